@@ -0,0 +1,79 @@
+﻿package bytewise
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func Test_AhoCorasick_FindAll(t *testing.T) {
+	ac, err := NewAhoCorasick([]string{"he", "she", "his", "hers"})
+	assert.Nil(t, err)
+	assert.Equal(t, []PatternMatch{
+		{Pattern: 1, Start: 1, End: 4},
+		{Pattern: 0, Start: 2, End: 4},
+		{Pattern: 3, Start: 2, End: 6},
+	}, ac.FindAll("ushers"))
+
+	assert.Equal(t, []PatternMatch{
+		{Pattern: 2, Start: 0, End: 3},
+		{Pattern: 0, Start: 5, End: 7},
+	}, ac.FindAll("his the"))
+
+	assert.Equal(t, 0, len(ac.FindAll("")))
+	assert.Equal(t, 0, len(ac.FindAll("xyz")))
+
+	ac, err = NewAhoCorasick([]string{"aa", "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, []PatternMatch{
+		{Pattern: 1, Start: 0, End: 1},
+		{Pattern: 0, Start: 0, End: 2},
+		{Pattern: 1, Start: 1, End: 2},
+		{Pattern: 0, Start: 1, End: 3},
+		{Pattern: 1, Start: 2, End: 3},
+	}, ac.FindAll("aaa"), "Overlapping matches are all reported.")
+
+	ac, err = NewAhoCorasick([]string{"日本"})
+	assert.Nil(t, err)
+	assert.Equal(t, []PatternMatch{{Pattern: 0, Start: 1, End: 7}}, ac.FindAll("x日本語"), "Offsets are byte offsets.")
+}
+
+func Test_AhoCorasick_FindLeftmostLongest(t *testing.T) {
+	ac, err := NewAhoCorasick([]string{"he", "she", "his", "hers"})
+	assert.Nil(t, err)
+	assert.Equal(t, []PatternMatch{{Pattern: 1, Start: 1, End: 4}}, ac.FindLeftmostLongest("ushers"))
+
+	ac, err = NewAhoCorasick([]string{"inc", "inc.", "co", "corp", "ltd"})
+	assert.Nil(t, err)
+	assert.Equal(t, []PatternMatch{
+		{Pattern: 3, Start: 5, End: 9},
+		{Pattern: 1, Start: 10, End: 14},
+	}, ac.FindLeftmostLongest("acme corp inc."))
+
+	ac, err = NewAhoCorasick([]string{"abc", "b", "bcd"})
+	assert.Nil(t, err)
+	assert.Equal(t, []PatternMatch{{Pattern: 0, Start: 0, End: 3}}, ac.FindLeftmostLongest("abcd"), "The leftmost match wins, even when a later one is longer.")
+
+	ac, err = NewAhoCorasick([]string{"ab", "ab"})
+	assert.Nil(t, err)
+	assert.Equal(t, []PatternMatch{{Pattern: 0, Start: 0, End: 2}}, ac.FindLeftmostLongest("ab"))
+}
+
+func Test_NewAhoCorasick_Errors(t *testing.T) {
+	ac, err := NewAhoCorasick([]string{"a", ""})
+	assert.NotNil(t, err)
+	assert.Nil(t, ac)
+
+	ac, err = NewAhoCorasick(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(ac.FindAll("anything")))
+}
+
+func Benchmark_AhoCorasick_FindAll(b *testing.B) {
+	ac, _ := NewAhoCorasick([]string{"inc", "inc.", "co", "corp", "ltd", "llc", "gmbh", "plc", "sa"})
+	text := strings.Repeat("acme corp, widgets inc. and gadgets gmbh ", 25)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ac.FindAll(text)
+	}
+}
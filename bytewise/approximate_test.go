@@ -0,0 +1,133 @@
+﻿package bytewise
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func Test_SellersSearch(t *testing.T) {
+	m, err := SellersSearch("abc", "xabcx", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []ApproximateMatch{{Start: 1, End: 4, Distance: 0}}, m)
+
+	m, err = SellersSearch("abc", "xabcx", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []ApproximateMatch{
+		{Start: 1, End: 3, Distance: 1},
+		{Start: 1, End: 4, Distance: 0},
+		{Start: 1, End: 5, Distance: 1},
+	}, m)
+
+	m, err = SellersSearch("survey", "a surgery report", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []ApproximateMatch{
+		{Start: 2, End: 7, Distance: 2},
+		{Start: 2, End: 8, Distance: 2},
+		{Start: 2, End: 9, Distance: 2},
+	}, m)
+
+	m, err = SellersSearch("abc", "xyz", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(m))
+
+	m, err = SellersSearch("abc", "", 3)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(m), "Matches are only reported for end positions within the text.")
+
+	m, err = SellersSearch("", "abc", 1)
+	assert.NotNil(t, err)
+	assert.Nil(t, m)
+
+	m, err = SellersSearch("abc", "abc", -1)
+	assert.NotNil(t, err)
+	assert.Nil(t, m)
+
+	m, err = SellersSearch("日本", "xx日本xx", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []ApproximateMatch{{Start: 2, End: 8, Distance: 0}}, m, "Offsets are byte offsets.")
+}
+
+func Test_BitapSearch(t *testing.T) {
+	m, err := BitapSearch("abc", "xabcx", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []ApproximateMatch{{Start: 1, End: 4, Distance: 0}}, m)
+
+	m, err = BitapSearch("abc", "xabcx", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []ApproximateMatch{
+		{Start: 1, End: 3, Distance: 1},
+		{Start: 1, End: 4, Distance: 0},
+		{Start: 1, End: 5, Distance: 1},
+	}, m)
+
+	m, err = BitapSearch("survey", "a surgery report", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []ApproximateMatch{
+		{Start: 2, End: 7, Distance: 2},
+		{Start: 2, End: 8, Distance: 2},
+		{Start: 2, End: 9, Distance: 2},
+	}, m)
+
+	m, err = BitapSearch("abc", "xyz", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(m))
+
+	m, err = BitapSearch("ab", "zz", 5)
+	assert.Nil(t, err)
+	assert.Equal(t, []ApproximateMatch{
+		{Start: 0, End: 1, Distance: 2},
+		{Start: 0, End: 2, Distance: 2},
+	}, m, "Distances larger than the pattern length are capped.")
+
+	m, err = BitapSearch("", "abc", 1)
+	assert.NotNil(t, err)
+	assert.Nil(t, m)
+
+	m, err = BitapSearch("abc", "abc", -1)
+	assert.NotNil(t, err)
+	assert.Nil(t, m)
+
+	m, err = BitapSearch(strings.Repeat("a", BitapMaxPatternLength), strings.Repeat("a", 70), 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 7, len(m))
+
+	m, err = BitapSearch(strings.Repeat("a", BitapMaxPatternLength+1), "aaa", 1)
+	assert.NotNil(t, err, "BitapSearch reports an error for patterns that do not fit in a machine word.")
+	assert.Nil(t, m)
+}
+
+func Test_BitapSearch_AgreesWithSellersSearch(t *testing.T) {
+	texts := []string{
+		"",
+		"the quick brown fox jumps over the lazy dog",
+		"GATTACAGATTTACAGATACAGGATTACA",
+		"mississippi",
+	}
+	patterns := []string{"a", "the", "qick", "GATTACA", "issi", "dgo"}
+	for _, text := range texts {
+		for _, pattern := range patterns {
+			for k := 0; k <= 3; k++ {
+				s, err := SellersSearch(pattern, text, k)
+				assert.Nil(t, err)
+				b, err := BitapSearch(pattern, text, k)
+				assert.Nil(t, err)
+				assert.Equal(t, s, b, "pattern %q, text %q, k %d", pattern, text, k)
+			}
+		}
+	}
+}
+
+func Benchmark_SellersSearch(b *testing.B) {
+	text := strings.Repeat("the quick brown fox jumps over the lazy dog ", 20)
+	for i := 0; i < b.N; i++ {
+		SellersSearch("lazy cat", text, 2)
+	}
+}
+
+func Benchmark_BitapSearch(b *testing.B) {
+	text := strings.Repeat("the quick brown fox jumps over the lazy dog ", 20)
+	for i := 0; i < b.N; i++ {
+		BitapSearch("lazy cat", text, 2)
+	}
+}
@@ -14,8 +14,9 @@ when the target strings contain no multi-byte runes.
 package bytewise
 
 import (
-	"errors"
-	"unicode"
+	"github.com/ZackPierce/stralgo"
+	"github.com/ZackPierce/stralgo/internal/tokenset"
+	"github.com/ZackPierce/stralgo/tokenize"
 )
 
 // HammingDistance calculates the Hamming distance between
@@ -27,7 +28,8 @@ import (
 
 // See: http://en.wikipedia.org/wiki/Hamming_distance
 //
-// Returns an error if the string lengths are not equal.
+// Returns a *stralgo.LengthMismatchError, which matches
+// stralgo.ErrUnequalLength, if the string lengths are not equal.
 //
 // Note that this algorithm implementation operates upon
 // individual bytes, and does not account for multibyte
@@ -36,7 +38,7 @@ func HammingDistance(a, b string) (uint, error) {
 	aLen := len(a)
 	bLen := len(b)
 	if aLen != bLen {
-		return 0, errors.New("Hamming distance is undefined between strings of unequal length.")
+		return 0, &stralgo.LengthMismatchError{Metric: "Hamming distance", ALength: aLen, BLength: bLen}
 	}
 	var d uint
 	for i := 0; i < aLen; i++ {
@@ -66,38 +68,30 @@ func HammingDistance(a, b string) (uint, error) {
 // individual bytes and does not account for multibyte
 // unicode runes.
 //
-// Returns an error if both of the input strings
+// Returns stralgo.ErrInsufficientBigrams if both of the input strings
 // contain less than two bytes.
 func DiceCoefficient(a, b string) (float64, error) {
-	aLimit := len(a) - 1
-	bLimit := len(b) - 1
-	if aLimit < 1 && bLimit < 1 {
-		return 0, errors.New("At least one of the input strings must have a length of 2 or greater for the bigram-based DiceCoefficient to be calculated.")
-	}
-	aSet := make(map[string]bool, aLimit)
-	totalBigrams := 0.0
-	var bigram string
-	for i := 0; i < aLimit; i++ {
-		bigram = a[i : i+2]
-		if !aSet[bigram] {
-			totalBigrams++
-			aSet[bigram] = true
-		}
-	}
+	w := getWorkspace()
+	c, err := w.DiceCoefficient(a, b)
+	putWorkspace(w)
+	return c, err
+}
 
-	bSet := make(map[string]bool, bLimit)
-	sharedBigrams := 0.0
-	for i := 0; i < bLimit; i++ {
-		bigram = b[i : i+2]
-		if !bSet[bigram] {
-			totalBigrams++
-			bSet[bigram] = true
-			if aSet[bigram] {
-				sharedBigrams++
-			}
-		}
+// DiceCoefficientTokenized calculates the similarity of two
+// strings per the Sorensen-Dice coefficient of the sets of
+// distinct tokens produced from each by tokenizer.
+//
+// DiceCoefficient is DiceCoefficientTokenized with byte
+// bigrams, tokenize.ByteNGrams(2), as the tokens.
+//
+// Returns stralgo.ErrNoTokens if neither of the input strings
+// produces any tokens.
+func DiceCoefficientTokenized(a, b string, tokenizer tokenize.Tokenizer) (float64, error) {
+	c, ok := tokenset.Dice(tokenizer.Tokenize(a), tokenizer.Tokenize(b))
+	if !ok {
+		return 0, stralgo.ErrNoTokens
 	}
-	return 2 * sharedBigrams / totalBigrams, nil
+	return c, nil
 }
 
 // WhiteSimilarity calculates the similarity of two
@@ -118,67 +112,51 @@ func DiceCoefficient(a, b string) (float64, error) {
 // individual bytes and does not account for multibyte
 // unicode runes.
 //
-// Returns an error if neither of the input strings
-// contains at least one byte bigram without whitespace.
+// Returns stralgo.ErrInsufficientBigrams if neither of the
+// input strings contains at least one byte bigram without whitespace.
 func WhiteSimilarity(a, b string) (float64, error) {
-	aPairs, aLen := asciiUpperWordLetterPairs(a)
-	bPairs, bLen := asciiUpperWordLetterPairs(b)
-	union := aLen + bLen
-	if union == 0 {
-		return 0.0, errors.New("At least one of the input strings must contain two or more non-whitespace byte bigrams in order to calculate the White Similarity")
-	}
-	intersection := 0.0
-	for _, aBigram := range aPairs {
-		for j, bBigram := range bPairs {
-			if aBigram == bBigram {
-				intersection++
-				bPairs[j] = byteBigram{}
-				break
-			}
-		}
-	}
-	return 2 * intersection / float64(union), nil
+	w := getWorkspace()
+	s, err := w.WhiteSimilarity(a, b)
+	putWorkspace(w)
+	return s, err
 }
 
-func asciiUpperWordLetterPairs(s string) ([]byteBigram, int) {
-	limit := len(s) - 1
-	if limit < 1 {
-		return make([]byteBigram, 0), 0
+// WhiteSimilarityTokenized calculates the similarity of two
+// strings through a variation on the Sorensen-Dice
+// Coefficient algorithm, over the tokens produced from
+// each by tokenizer.
+//
+// The strings are upper-cased (ASCII letters only) before
+// being tokenized, and repeated tokens are accounted for.
+//
+// WhiteSimilarity is WhiteSimilarityTokenized with the byte
+// bigrams within each ASCII-whitespace separated word,
+// tokenize.Chain(tokenize.ASCIIWhitespace, tokenize.ByteNGrams(2)),
+// as the tokens.
+//
+// Returns stralgo.ErrNoTokens if neither of the input strings
+// produces any tokens.
+func WhiteSimilarityTokenized(a, b string, tokenizer tokenize.Tokenizer) (float64, error) {
+	s, ok := tokenset.MultisetDice(tokenizer.Tokenize(asciiUpper(a)), tokenizer.Tokenize(asciiUpper(b)))
+	if !ok {
+		return 0.0, stralgo.ErrNoTokens
 	}
-	bigrams := make([]byteBigram, limit)
-	var a byte
-	var b byte
-	var aIsSpace bool
-	var bIsSpace bool
-	numPairs := 0
-	for i := 0; i < limit; i++ {
-		b, bIsSpace = asciiUpperOrSpace(s[i+1])
-		if bIsSpace {
-			i++
-			continue
-		}
-		a, aIsSpace = asciiUpperOrSpace(s[i])
-		if aIsSpace {
-			continue
-		}
-		bigrams[numPairs] = byteBigram{a: a, b: b}
-		numPairs++
+	return s, nil
+}
+
+func asciiUpper(s string) string {
+	upper := []byte(s)
+	for i, b := range upper {
+		upper[i] = asciiUpperByte(b)
 	}
-	bigrams = bigrams[0:numPairs]
-	return bigrams, numPairs
+	return string(upper)
 }
 
-func asciiUpperOrSpace(b byte) (byte, bool) {
-	if b <= unicode.MaxASCII {
-		if 'a' <= b && b <= 'z' {
-			return b - ('a' - 'A'), false
-		}
-		switch b {
-		case '\t', '\n', '\v', '\f', '\r', ' ', 0x85, 0xA0:
-			return b, true
-		}
+func asciiUpperByte(b byte) byte {
+	if 'a' <= b && b <= 'z' {
+		return b - ('a' - 'A')
 	}
-	return b, false
+	return b
 }
 
 // LevenshteinDistance calculates the magnitude of
@@ -193,40 +171,10 @@ func asciiUpperOrSpace(b byte) (byte, bool) {
 //
 // See: http://en.wikipedia.org/wiki/Levenshtein_distance
 func LevenshteinDistance(a, b string) (int, error) {
-	aLen := len(a)
-	bLen := len(b)
-	if aLen == 0 {
-		return bLen, nil
-	}
-	if bLen == 0 {
-		return aLen, nil
-	}
-	if aLen == bLen && a == b {
-		return 0, nil
-	}
-	rowLen := bLen + 1
-	prevRow := make([]int, rowLen, rowLen)
-	currRow := make([]int, rowLen, rowLen)
-	for h := 0; h < rowLen; h++ {
-		prevRow[h] = h
-	}
-	cost := 0
-	for i := 0; i < aLen; i++ {
-		currRow[0] = i + 1
-		for j := 0; j < bLen; j++ {
-			if a[i] == b[i] {
-				cost = 0
-			} else {
-				cost = 1
-			}
-			currRow[j+1] = min(
-				currRow[j]+1,
-				prevRow[j+1]+1,
-				prevRow[j]+cost)
-		}
-		prevRow, currRow = currRow, prevRow
-	}
-	return prevRow[bLen], nil
+	w := getWorkspace()
+	d, err := w.LevenshteinDistance(a, b)
+	putWorkspace(w)
+	return d, err
 }
 
 // DamerauLevenshteinDistance calculates the magnitude
@@ -244,78 +192,82 @@ func LevenshteinDistance(a, b string) (int, error) {
 //
 // See: http://en.wikipedia.org/wiki/Damerau-Levenshtein_distance
 func DamerauLevenshteinDistance(a, b string) (int, error) {
-	aLen := len(a)
-	bLen := len(b)
-	if aLen == 0 {
-		return bLen, nil
-	} else if bLen == 0 {
-		return aLen, nil
-	}
+	w := getWorkspace()
+	d, err := w.DamerauLevenshteinDistance(a, b)
+	putWorkspace(w)
+	return d, err
+}
 
-	// Swap to ensure a contains the shorter string
-	if aLen > bLen {
-		a, aLen, b, bLen = b, bLen, a, aLen
-	}
-	rowLen := aLen + 1
-	tranRow := make([]int, rowLen, rowLen)
-	prevRow := make([]int, rowLen, rowLen)
-	currRow := make([]int, rowLen, rowLen)
-	for h := 0; h < rowLen; h++ {
-		prevRow[h] = h
-	}
-	var prevB byte
-	var cost int
-	for i := 1; i <= bLen; i++ {
-		currB := b[i-1]
-		currRow[0] = i
+// LevenshteinDistanceWithin calculates the Levenshtein distance
+// between two strings if it is no greater than maxDistance,
+// stopping early once it is known to be greater.
+//
+// It returns the distance and true if the distance is no greater
+// than maxDistance. Otherwise it returns false, along with
+// maxDistance+1, a lower bound on the distance, or 0 if
+// maxDistance is negative.
+func LevenshteinDistanceWithin(a, b string, maxDistance int) (int, bool) {
+	w := getWorkspace()
+	d, ok := w.LevenshteinDistanceWithin(a, b, maxDistance)
+	putWorkspace(w)
+	return d, ok
+}
 
-		start := i - bLen - 1
-		if start < 1 {
-			start = 1
-		}
-		end := i + bLen + 1
-		if end > aLen {
-			end = aLen
-		}
+// DamerauLevenshteinDistanceWithin calculates the
+// Damerau-Levenshtein distance between two strings if it is no
+// greater than maxDistance, stopping early once it is known to be
+// greater. Its results are as for LevenshteinDistanceWithin.
+func DamerauLevenshteinDistanceWithin(a, b string, maxDistance int) (int, bool) {
+	w := getWorkspace()
+	d, ok := w.DamerauLevenshteinDistanceWithin(a, b, maxDistance)
+	putWorkspace(w)
+	return d, ok
+}
 
-		var prevA byte
-		for j := start; j <= end; j++ {
-			currA := a[j-1]
-			if currA == currB {
-				cost = 0
-			} else {
-				cost = 1
-			}
-			entry := min(
-				currRow[j-1]+1,
-				prevRow[j]+1,
-				prevRow[j-1]+cost)
-			if currA == prevB && currB == prevA {
-				trans := tranRow[j-2] + cost
-				if trans < entry {
-					entry = trans
-				}
-			}
-			currRow[j] = entry
-			prevA = currA
-		}
-		prevB = currB
-		tranRow, prevRow, currRow = prevRow, currRow, tranRow
-	}
-	return prevRow[aLen], nil
+// JaroSimilarity calculates the similarity between two strings
+// using the original Jaro distance formula, bytewise.
+//
+// The result is between 0 and 1.0, and the higher the score,
+// the more similar the two strings are. 1.0 is a perfect match.
+// If either string is empty, the result is 0.0, as for
+// runewise.JaroSimilarity.
+//
+// See (the first half of) : http://en.wikipedia.org/wiki/Jaro-Winkler_distance
+func JaroSimilarity(a, b string) float64 {
+	w := getWorkspace()
+	s := w.JaroSimilarity(a, b)
+	putWorkspace(w)
+	return s
 }
 
-func min(a, b, c int) int {
-	m := a
-	if b < m {
-		m = b
-	}
-	if c < m {
-		return c
-	}
-	return m
+// JaroWinklerSimilarity calculates the similarity between
+// two strings using the Jaro-Winkler distance formula,
+// bytewise, with Winkler's suggested constants (see
+// runewise.JaroWinklerSimilarity).
+//
+// The result is between 0 and 1.0, and the higher the score,
+// the more similar the two strings are. 1.0 is a perfect match.
+//
+// See : http://en.wikipedia.org/wiki/Jaro-Winkler_distance
+func JaroWinklerSimilarity(a, b string) float64 {
+	w := getWorkspace()
+	s := w.JaroWinklerSimilarity(a, b)
+	putWorkspace(w)
+	return s
 }
 
-type byteBigram struct {
-	a, b byte
+// JaroWinklerSimilarityParametric calculates the similarity
+// between two strings using the Jaro-Winkler distance formula,
+// bytewise, with caller-supplied constants (see
+// runewise.JaroWinklerSimilarityParametric).
+//
+// The product of prefixScale and maxPrefixLength should be between 0.0 and 1.0.
+// Assuming this is true, the result will be between 0 and 1.0.
+//
+// See : http://en.wikipedia.org/wiki/Jaro-Winkler_distance
+func JaroWinklerSimilarityParametric(a, b string, prefixScale float64, maxPrefixLength int, boostThreshold float64) float64 {
+	w := getWorkspace()
+	s := w.JaroWinklerSimilarityParametric(a, b, prefixScale, maxPrefixLength, boostThreshold)
+	putWorkspace(w)
+	return s
 }
@@ -2,6 +2,7 @@
 
 import (
 	"fmt"
+	"github.com/ZackPierce/stralgo/tokenize"
 	"github.com/stretchr/testify/assert"
 	"math"
 	"testing"
@@ -137,6 +138,38 @@ func Test_WhiteSimilarity(t *testing.T) {
 	EqualWithin(t, 0.4, c, 0.01)
 }
 
+func Test_DiceCoefficientTokenized(t *testing.T) {
+	c, err := DiceCoefficientTokenized("night", "nacht", tokenize.ByteNGrams(2))
+	assert.Nil(t, err)
+	EqualWithin(t, 0.25, c, 0.01)
+
+	c, err = DiceCoefficientTokenized("REPUBLIC OF FRANCE", "FRANCE, REPUBLIC", tokenize.Punctuation)
+	assert.Nil(t, err)
+	EqualWithin(t, 0.8, c, 0.01)
+
+	c, err = DiceCoefficientTokenized(" ", "", tokenize.ASCIIWhitespace)
+	assert.NotNil(t, err)
+	assert.Equal(t, 0.0, c)
+}
+
+func Test_WhiteSimilarityTokenized(t *testing.T) {
+	c, err := WhiteSimilarityTokenized("Healed", "Sealed", tokenize.Chain(tokenize.ASCIIWhitespace, tokenize.ByteNGrams(2)))
+	assert.Nil(t, err)
+	EqualWithin(t, 0.8, c, 0.01)
+
+	c, err = WhiteSimilarityTokenized("the cat the", "THE DOG", tokenize.ASCIIWhitespace)
+	assert.Nil(t, err)
+	EqualWithin(t, 0.4, c, 0.01, "Only one occurrence of THE is matched.")
+
+	c, err = WhiteSimilarityTokenized("a\xa0b", "A\xa0B", tokenize.ASCIIWhitespace)
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, c)
+
+	c, err = WhiteSimilarityTokenized("...", "!", tokenize.Words)
+	assert.NotNil(t, err)
+	assert.Equal(t, 0.0, c)
+}
+
 func Test_LevenshteinDistance_Easy(t *testing.T) {
 	d, err := LevenshteinDistance("test", "test")
 	assert.Nil(t, err)
@@ -170,6 +203,13 @@ func Test_LevenshteinDistance_Easy(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 1, d)
 
+	d, err = LevenshteinDistance("sitting", "kit")
+	assert.Nil(t, err)
+	assert.Equal(t, 5, d, "The second string may be the shorter.")
+
+	d, err = LevenshteinDistance("ab", "ba")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, d)
 }
 
 func Test_DamerauLevenshteinDistance(t *testing.T) {
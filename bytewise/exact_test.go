@@ -0,0 +1,176 @@
+﻿package bytewise
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func matchers(pattern string) []Matcher {
+	return []Matcher{NewKMP(pattern), NewHorspool(pattern), NewTwoWay(pattern)}
+}
+
+func Test_Matcher_Index(t *testing.T) {
+	for _, m := range matchers("needle") {
+		assert.Equal(t, 6, m.Len())
+		assert.Equal(t, 9, m.Index("haystack needle haystack"), "%T", m)
+		assert.Equal(t, 0, m.Index("needle"), "%T", m)
+		assert.Equal(t, -1, m.Index("needl"), "%T", m)
+		assert.Equal(t, -1, m.Index(""), "%T", m)
+	}
+	for _, m := range matchers("") {
+		assert.Equal(t, 0, m.Len())
+		assert.Equal(t, 0, m.Index("abc"), "%T", m)
+		assert.Equal(t, 0, m.Index(""), "%T", m)
+	}
+	for _, m := range matchers("本") {
+		assert.Equal(t, 3, m.Index("日本語"), "%T", m)
+	}
+}
+
+func Test_Matcher_AgreesWithStringsIndex(t *testing.T) {
+	texts := []string{
+		"",
+		"abababababcabababcababc",
+		"GATTACAGATTTACAGATACAGGATTACA",
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaab",
+		"mississippi river, mississippi state",
+	}
+	patterns := []string{"a", "ab", "abc", "ababc", "abababc", "GATTACA", "ACAG", "aaab", "aaaaa", "issi", "ssippi", "zebra", "ppi r"}
+	for _, text := range texts {
+		for _, pattern := range patterns {
+			for _, m := range matchers(pattern) {
+				for from := 0; from <= len(text); from++ {
+					assert.Equal(t, strings.Index(text[from:], pattern), m.Index(text[from:]), "%T %q in %q", m, pattern, text[from:])
+				}
+			}
+		}
+	}
+}
+
+func Test_FindAll(t *testing.T) {
+	for _, m := range matchers("aa") {
+		occ := FindAll(m, "aaaa")
+		assert.Equal(t, -1, occ.Index())
+		found := make([]int, 0)
+		for occ.Next() {
+			found = append(found, occ.Index())
+		}
+		assert.Equal(t, []int{0, 1, 2}, found, "%T reports overlapping occurrences", m)
+		assert.False(t, occ.Next())
+		assert.Equal(t, -1, occ.Index())
+	}
+	for _, m := range matchers("") {
+		found := make([]int, 0)
+		occ := FindAll(m, "abc")
+		for occ.Next() {
+			found = append(found, occ.Index())
+		}
+		assert.Equal(t, []int{0, 1, 2, 3}, found, "%T", m)
+	}
+}
+
+func Test_FindAll_AgreesWithNaiveSearch(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	random := func(n int) string {
+		s := make([]byte, n, n)
+		for i := range s {
+			s[i] = "ab"[r.Intn(2)]
+		}
+		return string(s)
+	}
+	patterns := []string{"a", "aa", "aaaa", "ab", "aba", "abab", "abaab", "aabaa", "abaabaab", "bbabb"}
+	for i := 0; i < 50; i++ {
+		patterns = append(patterns, random(1+r.Intn(8)))
+	}
+	texts := []string{strings.Repeat("a", 40), strings.Repeat("ab", 20), strings.Repeat("abaab", 8)}
+	for i := 0; i < 50; i++ {
+		texts = append(texts, random(r.Intn(60)))
+	}
+	for _, text := range texts {
+		for _, pattern := range patterns {
+			expected := make([]int, 0)
+			for i := 0; i+len(pattern) <= len(text); i++ {
+				if text[i:i+len(pattern)] == pattern {
+					expected = append(expected, i)
+				}
+			}
+			for _, m := range matchers(pattern) {
+				found := make([]int, 0)
+				for occ := FindAll(m, text); occ.Next(); {
+					found = append(found, occ.Index())
+				}
+				assert.Equal(t, expected, found, "%T %q in %q", m, pattern, text)
+			}
+		}
+	}
+}
+
+func Test_Count(t *testing.T) {
+	for _, m := range matchers("issi") {
+		assert.Equal(t, 2, Count(m, "mississippi"), "%T", m)
+		assert.Equal(t, 0, Count(m, ""), "%T", m)
+	}
+	for _, m := range matchers("ana") {
+		assert.Equal(t, 2, Count(m, "banana"), "%T counts overlapping occurrences, unlike strings.Count", m)
+	}
+}
+
+var benchmarkText = strings.Repeat("the quick brown fox jumps over the lazy dog ", 200) + "the quick brown fox jumps over the lazy cat"
+
+const benchmarkPattern = "over the lazy cat"
+
+func Benchmark_KMP_Index(b *testing.B) {
+	m := NewKMP(benchmarkPattern)
+	for i := 0; i < b.N; i++ {
+		m.Index(benchmarkText)
+	}
+}
+
+func Benchmark_Horspool_Index(b *testing.B) {
+	m := NewHorspool(benchmarkPattern)
+	for i := 0; i < b.N; i++ {
+		m.Index(benchmarkText)
+	}
+}
+
+func Benchmark_TwoWay_Index(b *testing.B) {
+	m := NewTwoWay(benchmarkPattern)
+	for i := 0; i < b.N; i++ {
+		m.Index(benchmarkText)
+	}
+}
+
+func Benchmark_StringsIndex(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		strings.Index(benchmarkText, benchmarkPattern)
+	}
+}
+
+// periodicText and periodicPattern overlap at every offset, which
+// makes restarting the search after each occurrence quadratic.
+var periodicText = strings.Repeat("a", 200000)
+
+var periodicPattern = strings.Repeat("a", 1000)
+
+func Benchmark_KMP_Count_Periodic(b *testing.B) {
+	m := NewKMP(periodicPattern)
+	for i := 0; i < b.N; i++ {
+		Count(m, periodicText)
+	}
+}
+
+func Benchmark_Horspool_Count_Periodic(b *testing.B) {
+	m := NewHorspool(periodicPattern)
+	for i := 0; i < b.N; i++ {
+		Count(m, periodicText)
+	}
+}
+
+func Benchmark_TwoWay_Count_Periodic(b *testing.B) {
+	m := NewTwoWay(periodicPattern)
+	for i := 0; i < b.N; i++ {
+		Count(m, periodicText)
+	}
+}
@@ -0,0 +1,14 @@
+﻿//go:build race
+
+/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package bytewise
+
+// raceEnabled reports whether the race detector is on. It makes
+// sync.Pool drop items at random, so pooled buffers are not
+// always reused.
+const raceEnabled = true
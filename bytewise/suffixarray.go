@@ -0,0 +1,160 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package bytewise
+
+import (
+	"github.com/ZackPierce/stralgo/internal/sais"
+	"sort"
+)
+
+// SuffixArray returns the suffix array of s, bytewise: the
+// byte offsets of every non-empty suffix of s, sorted in
+// lexicographic byte order.
+//
+// The array is built in linear time using the SA-IS
+// induced sorting algorithm.
+//
+// See: http://en.wikipedia.org/wiki/Suffix_array
+func SuffixArray(s string) []int {
+	return sais.Sort(byteSymbols(s), 256)
+}
+
+// LCPArray returns the longest common prefix array for s
+// and its suffix array sa, as returned by SuffixArray.
+//
+// Element i holds the length, in bytes, of the longest common
+// prefix of the suffixes starting at sa[i-1] and sa[i].
+// Element 0 is always 0.
+//
+// The array is built in linear time using Kasai's algorithm.
+//
+// See: http://en.wikipedia.org/wiki/LCP_array
+func LCPArray(s string, sa []int) []int {
+	return sais.LCP(byteSymbols(s), sa)
+}
+
+func byteSymbols(s string) []int {
+	symbols := make([]int, len(s), len(s))
+	for i := 0; i < len(s); i++ {
+		symbols[i] = int(s[i])
+	}
+	return symbols
+}
+
+// SuffixIndex pairs a text with its suffix and LCP arrays
+// in order to answer substring queries without rescanning
+// the text.
+//
+// A SuffixIndex is immutable once built and may be shared
+// between goroutines.
+type SuffixIndex struct {
+	text string
+	sa   []int
+	lcp  []int
+}
+
+// NewSuffixIndex builds a SuffixIndex over text, bytewise.
+func NewSuffixIndex(text string) *SuffixIndex {
+	symbols := byteSymbols(text)
+	sa := sais.Sort(symbols, 256)
+	return &SuffixIndex{text: text, sa: sa, lcp: sais.LCP(symbols, sa)}
+}
+
+// SuffixArray returns the suffix array of the indexed text.
+// The returned slice must not be modified.
+func (x *SuffixIndex) SuffixArray() []int {
+	return x.sa
+}
+
+// LCPArray returns the LCP array of the indexed text.
+// The returned slice must not be modified.
+func (x *SuffixIndex) LCPArray() []int {
+	return x.lcp
+}
+
+// Count returns the number of (possibly overlapping)
+// occurrences of pattern within the indexed text, in time
+// proportional to the pattern length times the logarithm of
+// the text length.
+//
+// An empty pattern occurs at every offset from 0 to len(text).
+func (x *SuffixIndex) Count(pattern string) int {
+	if len(pattern) == 0 {
+		return len(x.text) + 1
+	}
+	lo, hi := x.bounds(pattern)
+	return hi - lo
+}
+
+// Locate returns the byte offsets of every (possibly
+// overlapping) occurrence of pattern within the indexed
+// text, in ascending order.
+//
+// An empty pattern occurs at every offset from 0 to len(text).
+func (x *SuffixIndex) Locate(pattern string) []int {
+	if len(pattern) == 0 {
+		offsets := make([]int, len(x.text)+1, len(x.text)+1)
+		for i := range offsets {
+			offsets[i] = i
+		}
+		return offsets
+	}
+	lo, hi := x.bounds(pattern)
+	offsets := make([]int, hi-lo, hi-lo)
+	copy(offsets, x.sa[lo:hi])
+	sort.Ints(offsets)
+	return offsets
+}
+
+// bounds returns the half-open range of suffix array
+// entries whose suffixes begin with pattern.
+func (x *SuffixIndex) bounds(pattern string) (int, int) {
+	m := len(pattern)
+	prefix := func(i int) string {
+		suffix := x.text[x.sa[i]:]
+		if len(suffix) > m {
+			return suffix[:m]
+		}
+		return suffix
+	}
+	lo := sort.Search(len(x.sa), func(i int) bool {
+		return prefix(i) >= pattern
+	})
+	hi := sort.Search(len(x.sa), func(i int) bool {
+		return prefix(i) > pattern
+	})
+	return lo, hi
+}
+
+// LongestRepeatedSubstring returns the longest substring which
+// occurs at least twice within the indexed text, where the
+// occurrences may overlap. If several candidates share the
+// greatest length, the lexicographically smallest is returned.
+//
+// Returns the empty string if no byte is repeated.
+func (x *SuffixIndex) LongestRepeatedSubstring() string {
+	best := 0
+	at := 0
+	for i, l := range x.lcp {
+		if l > best {
+			best = l
+			at = x.sa[i]
+		}
+	}
+	return x.text[at : at+best]
+}
+
+// DistinctSubstringCount returns the number of distinct
+// non-empty substrings of the indexed text.
+func (x *SuffixIndex) DistinctSubstringCount() int {
+	n := len(x.text)
+	total := n * (n + 1) / 2
+	for _, l := range x.lcp {
+		total -= l
+	}
+	return total
+}
@@ -0,0 +1,59 @@
+﻿package bytewise
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func Test_SuffixArray(t *testing.T) {
+	assert.Equal(t, []int{5, 3, 1, 0, 4, 2}, SuffixArray("banana"))
+	assert.Equal(t, []int{10, 7, 4, 1, 0, 9, 8, 6, 3, 5, 2}, SuffixArray("mississippi"))
+	assert.Equal(t, []int{}, SuffixArray(""))
+	assert.Equal(t, []int{3, 2, 1, 0}, SuffixArray("aaaa"))
+}
+
+func Test_LCPArray(t *testing.T) {
+	assert.Equal(t, []int{0, 1, 3, 0, 0, 2}, LCPArray("banana", SuffixArray("banana")))
+	assert.Equal(t, []int{0, 1, 1, 4, 0, 0, 1, 0, 2, 1, 3}, LCPArray("mississippi", SuffixArray("mississippi")))
+}
+
+func Test_SuffixIndex_CountAndLocate(t *testing.T) {
+	x := NewSuffixIndex("mississippi")
+	assert.Equal(t, 2, x.Count("issi"))
+	assert.Equal(t, []int{1, 4}, x.Locate("issi"))
+	assert.Equal(t, 4, x.Count("s"))
+	assert.Equal(t, []int{2, 3, 5, 6}, x.Locate("s"))
+	assert.Equal(t, 1, x.Count("mississippi"))
+	assert.Equal(t, 0, x.Count("mississippis"))
+	assert.Equal(t, 0, x.Count("x"))
+	assert.Equal(t, []int{}, x.Locate("x"))
+	assert.Equal(t, 12, x.Count(""))
+	assert.Equal(t, 12, len(x.Locate("")))
+
+	x = NewSuffixIndex("日本語の日本")
+	assert.Equal(t, []int{0, 12}, x.Locate("日本"), "Offsets are byte offsets.")
+	assert.Equal(t, x.SuffixArray(), SuffixArray("日本語の日本"))
+}
+
+func Test_SuffixIndex_LongestRepeatedSubstring(t *testing.T) {
+	assert.Equal(t, "ana", NewSuffixIndex("banana").LongestRepeatedSubstring())
+	assert.Equal(t, "issi", NewSuffixIndex("mississippi").LongestRepeatedSubstring())
+	assert.Equal(t, "abc", NewSuffixIndex("abcxyzabc").LongestRepeatedSubstring())
+	assert.Equal(t, "", NewSuffixIndex("abc").LongestRepeatedSubstring())
+	assert.Equal(t, "", NewSuffixIndex("").LongestRepeatedSubstring())
+}
+
+func Test_SuffixIndex_DistinctSubstringCount(t *testing.T) {
+	assert.Equal(t, 15, NewSuffixIndex("banana").DistinctSubstringCount())
+	assert.Equal(t, 3, NewSuffixIndex("aaa").DistinctSubstringCount())
+	assert.Equal(t, 6, NewSuffixIndex("abc").DistinctSubstringCount())
+	assert.Equal(t, 0, NewSuffixIndex("").DistinctSubstringCount())
+}
+
+func Benchmark_SuffixArray(b *testing.B) {
+	text := strings.Repeat("the quick brown fox jumps over the lazy dog ", 100)
+	for i := 0; i < b.N; i++ {
+		SuffixArray(text)
+	}
+}
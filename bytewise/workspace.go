@@ -0,0 +1,192 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package bytewise
+
+import (
+	"github.com/ZackPierce/stralgo"
+	"github.com/ZackPierce/stralgo/sequence"
+	"slices"
+	"sync"
+)
+
+// Workspace holds the buffers used by the bytewise metrics, so
+// that they may be reused from one comparison to the next. Once
+// its buffers have grown to fit the longest strings compared,
+// its methods do not allocate, which suits batch jobs making
+// many comparisons.
+//
+// Each method returns the same result as the package-level
+// function of the same name.
+//
+// The zero value is an empty Workspace ready for use. A
+// Workspace must not be used by more than one goroutine at a
+// time; keep one per goroutine instead:
+//
+//	var w bytewise.Workspace
+//	for _, pair := range pairs {
+//		d, err := w.LevenshteinDistance(pair.a, pair.b)
+//		...
+//	}
+type Workspace struct {
+	sequence sequence.Workspace[byte]
+	// a and b hold copies of the strings compared, as the
+	// sequence metrics take slices.
+	a, b               []byte
+	bigramsA, bigramsB []uint16
+}
+
+// maxPooledBytes bounds the length of the strings whose buffers
+// are returned to the pool, so that one very long string does not
+// pin large buffers for the life of the program.
+const maxPooledBytes = 1 << 16
+
+// workspaces holds the Workspaces used by the package-level
+// functions, which would otherwise copy both strings and allocate
+// their buffers on every call.
+var workspaces = sync.Pool{
+	New: func() interface{} {
+		return new(Workspace)
+	},
+}
+
+func getWorkspace() *Workspace {
+	return workspaces.Get().(*Workspace)
+}
+
+func putWorkspace(w *Workspace) {
+	if cap(w.a) <= maxPooledBytes && cap(w.b) <= maxPooledBytes {
+		workspaces.Put(w)
+	}
+}
+
+// bytes copies a and b into the workspace's buffers.
+func (w *Workspace) bytes(a, b string) ([]byte, []byte) {
+	w.a = append(w.a[:0], a...)
+	w.b = append(w.b[:0], b...)
+	return w.a, w.b
+}
+
+// LevenshteinDistance calculates the Levenshtein distance
+// between two strings. See LevenshteinDistance.
+func (w *Workspace) LevenshteinDistance(a, b string) (int, error) {
+	if a == b {
+		return 0, nil
+	}
+	return w.sequence.LevenshteinDistance(w.bytes(a, b))
+}
+
+// DamerauLevenshteinDistance calculates the Damerau-Levenshtein
+// distance between two strings. See DamerauLevenshteinDistance.
+func (w *Workspace) DamerauLevenshteinDistance(a, b string) (int, error) {
+	return w.sequence.DamerauLevenshteinDistance(w.bytes(a, b))
+}
+
+// LevenshteinDistanceWithin calculates the Levenshtein distance
+// between two strings, if it is no greater than maxDistance. See
+// LevenshteinDistanceWithin.
+func (w *Workspace) LevenshteinDistanceWithin(a, b string, maxDistance int) (int, bool) {
+	x, y := w.bytes(a, b)
+	return w.sequence.LevenshteinDistanceWithin(x, y, maxDistance)
+}
+
+// DamerauLevenshteinDistanceWithin calculates the
+// Damerau-Levenshtein distance between two strings, if it is no
+// greater than maxDistance. See DamerauLevenshteinDistanceWithin.
+func (w *Workspace) DamerauLevenshteinDistanceWithin(a, b string, maxDistance int) (int, bool) {
+	x, y := w.bytes(a, b)
+	return w.sequence.DamerauLevenshteinDistanceWithin(x, y, maxDistance)
+}
+
+// JaroSimilarity calculates the Jaro similarity of two strings.
+// See JaroSimilarity.
+func (w *Workspace) JaroSimilarity(a, b string) float64 {
+	return w.sequence.JaroSimilarity(w.bytes(a, b))
+}
+
+// JaroWinklerSimilarity calculates the Jaro-Winkler similarity
+// of two strings. See JaroWinklerSimilarity.
+func (w *Workspace) JaroWinklerSimilarity(a, b string) float64 {
+	return w.sequence.JaroWinklerSimilarity(w.bytes(a, b))
+}
+
+// JaroWinklerSimilarityParametric calculates the Jaro-Winkler
+// similarity of two strings with the given parameters. See
+// JaroWinklerSimilarityParametric.
+func (w *Workspace) JaroWinklerSimilarityParametric(a, b string, prefixScale float64, maxPrefixLength int, boostThreshold float64) float64 {
+	x, y := w.bytes(a, b)
+	return w.sequence.JaroWinklerSimilarityParametric(x, y, prefixScale, maxPrefixLength, boostThreshold)
+}
+
+// DiceCoefficient calculates the Sorensen-Dice coefficient of
+// two strings. See DiceCoefficient.
+func (w *Workspace) DiceCoefficient(a, b string) (float64, error) {
+	if len(a) < 2 && len(b) < 2 {
+		return 0, stralgo.ErrInsufficientBigrams
+	}
+	w.bigramsA = slices.Compact(sortedBigrams(w.bigramsA[:0], a, false))
+	w.bigramsB = slices.Compact(sortedBigrams(w.bigramsB[:0], b, false))
+	shared := sharedBigrams(w.bigramsA, w.bigramsB)
+	return 2 * float64(shared) / float64(len(w.bigramsA)+len(w.bigramsB)), nil
+}
+
+// WhiteSimilarity calculates the White similarity of two
+// strings. See WhiteSimilarity.
+func (w *Workspace) WhiteSimilarity(a, b string) (float64, error) {
+	w.bigramsA = sortedBigrams(w.bigramsA[:0], a, true)
+	w.bigramsB = sortedBigrams(w.bigramsB[:0], b, true)
+	total := len(w.bigramsA) + len(w.bigramsB)
+	if total == 0 {
+		return 0.0, stralgo.ErrInsufficientBigrams
+	}
+	return 2 * float64(sharedBigrams(w.bigramsA, w.bigramsB)) / float64(total), nil
+}
+
+// sortedBigrams appends the byte bigrams of s to buf, packed
+// into uint16s, and sorts them. For WhiteSimilarity, ASCII
+// letters are upper-cased and bigrams including ASCII whitespace
+// are skipped.
+func sortedBigrams(buf []uint16, s string, white bool) []uint16 {
+	for i := 1; i < len(s); i++ {
+		first, second := s[i-1], s[i]
+		if white {
+			if asciiSpace(first) || asciiSpace(second) {
+				continue
+			}
+			first, second = asciiUpperByte(first), asciiUpperByte(second)
+		}
+		buf = append(buf, uint16(first)<<8|uint16(second))
+	}
+	slices.Sort(buf)
+	return buf
+}
+
+func asciiSpace(b byte) bool {
+	switch b {
+	case '\t', '\n', '\v', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+// sharedBigrams counts the bigrams common to the sorted a and
+// b, matching each occurrence in a with at most one in b.
+func sharedBigrams(a, b []uint16) int {
+	shared := 0
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			shared++
+			i++
+			j++
+		}
+	}
+	return shared
+}
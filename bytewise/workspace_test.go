@@ -0,0 +1,167 @@
+﻿package bytewise
+
+import (
+	"github.com/ZackPierce/stralgo"
+	"github.com/ZackPierce/stralgo/sequence"
+	"github.com/ZackPierce/stralgo/tokenize"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+func Test_Workspace_AgreesWithFunctions(t *testing.T) {
+	alphabet := []byte{'a', 'b', 'A', 'B', ' ', '\t', 0xc3, 0xa9, 0x00, 0xff}
+	r := rand.New(rand.NewSource(7))
+	random := func() string {
+		s := make([]byte, r.Intn(12), 12)
+		for i := range s {
+			s[i] = alphabet[r.Intn(len(alphabet))]
+		}
+		return string(s)
+	}
+	letterPairs := tokenize.Chain(tokenize.ASCIIWhitespace, tokenize.ByteNGrams(2))
+	var w Workspace
+	for i := 0; i < 20000; i++ {
+		a, b := random(), random()
+
+		d, err := sequence.LevenshteinDistance([]byte(a), []byte(b))
+		wd, wErr := w.LevenshteinDistance(a, b)
+		assert.Equal(t, d, wd)
+		assert.Equal(t, err, wErr)
+		wd, _ = LevenshteinDistance(a, b)
+		assert.Equal(t, d, wd)
+
+		d, err = sequence.DamerauLevenshteinDistance([]byte(a), []byte(b))
+		wd, wErr = w.DamerauLevenshteinDistance(a, b)
+		assert.Equal(t, d, wd)
+		assert.Equal(t, err, wErr)
+
+		d, ok := sequence.LevenshteinDistanceWithin([]byte(a), []byte(b), 3)
+		wd, wOk := w.LevenshteinDistanceWithin(a, b, 3)
+		assert.Equal(t, d, wd)
+		assert.Equal(t, ok, wOk)
+
+		assert.Equal(t, sequence.JaroSimilarity([]byte(a), []byte(b)), w.JaroSimilarity(a, b))
+		assert.Equal(t, sequence.JaroWinklerSimilarity([]byte(a), []byte(b)), w.JaroWinklerSimilarity(a, b))
+		assert.Equal(t, JaroWinklerSimilarity(a, b), w.JaroWinklerSimilarity(a, b))
+
+		s, err := DiceCoefficientTokenized(a, b, tokenize.ByteNGrams(2))
+		if len(a) < 2 && len(b) < 2 {
+			err = stralgo.ErrInsufficientBigrams
+		}
+		ws, wErr := w.DiceCoefficient(a, b)
+		EqualWithin(t, s, ws, 1e-12, a, b)
+		assert.Equal(t, err, wErr)
+
+		s, err = WhiteSimilarityTokenized(a, b, letterPairs)
+		if err != nil {
+			err = stralgo.ErrInsufficientBigrams
+		}
+		ws, wErr = w.WhiteSimilarity(a, b)
+		EqualWithin(t, s, ws, 1e-12, a, b)
+		assert.Equal(t, err, wErr)
+	}
+}
+
+func Test_Workspace_DoesNotAllocate(t *testing.T) {
+	var w Workspace
+	a, b := "The quick brown fox jumps over the lazy dog", "The quick brown dog jumps over the lazy fox"
+	w.LevenshteinDistance(a, b)
+	w.DamerauLevenshteinDistance(a, b)
+	w.JaroWinklerSimilarity(a, b)
+	w.DiceCoefficient(a, b)
+	w.WhiteSimilarity(a, b)
+	short, long := "kitten", "sitting"
+
+	allocs := testing.AllocsPerRun(100, func() {
+		w.LevenshteinDistance(a, b)
+		w.LevenshteinDistance(short, long)
+		w.DamerauLevenshteinDistance(a, b)
+		w.DamerauLevenshteinDistanceWithin(a, b, 2)
+		w.JaroSimilarity(a, b)
+		w.JaroWinklerSimilarity(short, long)
+		w.DiceCoefficient(a, b)
+		w.WhiteSimilarity(short, long)
+	})
+	assert.Equal(t, 0.0, allocs, "A warmed-up Workspace allocates nothing.")
+
+	if raceEnabled {
+		t.Skip("The race detector defeats sync.Pool.")
+	}
+	allocs = testing.AllocsPerRun(100, func() {
+		LevenshteinDistance(a, b)
+		DamerauLevenshteinDistance(short, long)
+		JaroWinklerSimilarity(a, b)
+		DiceCoefficient(a, b)
+		WhiteSimilarity(short, long)
+	})
+	assert.Equal(t, 0.0, allocs, "The package-level functions reuse pooled Workspaces.")
+}
+
+func Benchmark_Workspace_LevenshteinDistance(b *testing.B) {
+	var w Workspace
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.LevenshteinDistance("kitten", "sitting")
+	}
+}
+
+func Benchmark_Workspace_DamerauLevenshteinDistance(b *testing.B) {
+	var w Workspace
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.DamerauLevenshteinDistance("azertyuiop", "aeryuop")
+	}
+}
+
+func Benchmark_DamerauLevenshteinDistance(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		DamerauLevenshteinDistance("azertyuiop", "aeryuop")
+	}
+}
+
+func Benchmark_Workspace_JaroWinklerSimilarity(b *testing.B) {
+	var w Workspace
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.JaroWinklerSimilarity("martha", "marhta")
+	}
+}
+
+func Benchmark_JaroWinklerSimilarity(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		JaroWinklerSimilarity("martha", "marhta")
+	}
+}
+
+func Benchmark_Workspace_DiceCoefficient(b *testing.B) {
+	var w Workspace
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.DiceCoefficient("night at the opera", "a night at the opera")
+	}
+}
+
+func Benchmark_DiceCoefficient(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		DiceCoefficient("night at the opera", "a night at the opera")
+	}
+}
+
+func Benchmark_Workspace_WhiteSimilarity(b *testing.B) {
+	var w Workspace
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.WhiteSimilarity("Healed the sick", "Sealed the deal")
+	}
+}
+
+func Benchmark_WhiteSimilarity(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		WhiteSimilarity("Healed the sick", "Sealed the deal")
+	}
+}
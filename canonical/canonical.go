@@ -0,0 +1,192 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+/*
+Package stralgo/canonical implements the choice of a canonical
+form for a group of variant strings, such as a cluster of the
+spellings of a name found by the cluster package.
+
+Three approaches are provided, each of which may weight the
+variants, such as by the number of records in which they occur:
+
+	variants := []string{"Jonathon Smith", "Jonathan Smyth", "Jonatan Smith"}
+	counts := []float64{3, 1, 1}
+	d, _ := metric.LookupDistance("levenshtein")
+	i, err := canonical.Medoid(variants, counts, d) // 0, "Jonathon Smith"
+	j, err := canonical.Vote(variants, counts, nil) // 0, "Jonathon Smith"
+	s, err := canonical.Consensus(variants, nil)    // "Jonathan Smith"
+
+Medoid and MedoidBySimilarity choose the variant closest to all
+of the others, under any metric. Vote chooses the most common
+variant, optionally pooling those which differ only trivially.
+Consensus may construct a form which is not among the variants,
+voting on each character of an alignment of the variants.
+*/
+package canonical
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ZackPierce/stralgo/metric"
+	"github.com/ZackPierce/stralgo/pairwise"
+	"math"
+	"github.com/ZackPierce/stralgo/preprocess"
+)
+
+// distinct holds the distinct values of a list of variants,
+// along with their pooled weights and first positions.
+type distinct struct {
+	values  []string
+	weights []float64
+	first   []int
+}
+
+// pool pools the weights of the variants by the value of key,
+// in order of first occurrence. A nil weights weights each
+// variant 1.
+func pool(variants []string, weights []float64, key func(s string) string) (*distinct, error) {
+	if len(variants) == 0 {
+		return nil, errors.New("At least one variant is needed to choose a canonical form.")
+	}
+	if weights != nil && len(weights) != len(variants) {
+		return nil, fmt.Errorf("There are %d weights for %d variants.", len(weights), len(variants))
+	}
+	d := &distinct{}
+	index := make(map[string]int)
+	for i, v := range variants {
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+			if math.IsNaN(w) {
+				return nil, fmt.Errorf("Variant %d has a weight of NaN.", i)
+			}
+			if w < 0 {
+				return nil, fmt.Errorf("Variant %d has a negative weight, %v.", i, w)
+			}
+		}
+		k := v
+		if key != nil {
+			k = key(v)
+		}
+		if x, ok := index[k]; ok {
+			d.weights[x] += w
+			continue
+		}
+		index[k] = len(d.values)
+		d.values = append(d.values, v)
+		d.weights = append(d.weights, w)
+		d.first = append(d.first, i)
+	}
+	return d, nil
+}
+
+// Medoid returns the position of the variant whose weighted sum
+// of distances to every variant is least, such as the variant
+// with the fewest Levenshtein edits from the others. Of equally
+// close variants, the first is chosen. A nil weights weights each
+// variant 1.
+//
+// If the distance fails for any pair of variants, Medoid returns
+// a *pairwise.PairError with their positions.
+//
+// See: http://en.wikipedia.org/wiki/Medoid
+func Medoid(variants []string, weights []float64, d metric.Distance) (int, error) {
+	return medoid(variants, weights, d.Distance, func(x, y float64) bool { return x < y })
+}
+
+// MedoidBySimilarity returns the position of the variant whose
+// weighted sum of similarities to every variant, itself included,
+// is greatest, such as the variant with the greatest summed
+// Jaro-Winkler similarity to the others. It is otherwise like
+// Medoid.
+func MedoidBySimilarity(variants []string, weights []float64, s metric.Similarity) (int, error) {
+	return medoid(variants, weights, s.Similarity, func(x, y float64) bool { return x > y })
+}
+
+// medoid returns the position of the variant with the best
+// weighted sum of scores to every variant.
+func medoid(variants []string, weights []float64, score func(a, b string) (float64, error), better func(x, y float64) bool) (int, error) {
+	d, err := pool(variants, weights, nil)
+	if err != nil {
+		return 0, err
+	}
+	n := len(d.values)
+	sums := make([]float64, n, n)
+	for i := 0; i < n; i++ {
+		// A metric need not give a variant its best score with
+		// itself, as Jaro-Winkler gives an empty string 0.
+		for j := i; j < n; j++ {
+			s, err := score(d.values[i], d.values[j])
+			if err != nil {
+				return 0, &pairwise.PairError{I: d.first[i], J: d.first[j], Err: err}
+			}
+			sums[i] += d.weights[j] * s
+			if j != i {
+				sums[j] += d.weights[i] * s
+			}
+		}
+	}
+	best := 0
+	for i := 1; i < n; i++ {
+		if better(sums[i], sums[best]) {
+			best = i
+		}
+	}
+	return d.first[best], nil
+}
+
+// Vote returns the position of the variant of greatest total
+// weight, counting the weight of each occurrence of a variant. A
+// nil weights weights each variant 1, so that the most common
+// variant wins. Of variants of equal weight, the first is chosen.
+//
+// If t is not nil, variants which t transforms to the same string
+// pool their weight, and the winner is the heaviest variant of
+// the heaviest pool: so that if "SMITH" and "smith" are more
+// common together than "Smyth", though less common apart, the
+// more common of them wins.
+func Vote(variants []string, weights []float64, t preprocess.Transform) (int, error) {
+	var key func(s string) string
+	if t != nil {
+		key = t.Apply
+	}
+	pools, err := pool(variants, weights, key)
+	if err != nil {
+		return 0, err
+	}
+	winner := pools.first[heaviest(pools.weights)]
+	if t == nil {
+		return winner, nil
+	}
+
+	// Vote again among the variants of the winning pool.
+	winningKey := key(variants[winner])
+	var members []int
+	var forms []string
+	var formWeights []float64
+	for i, v := range variants {
+		if key(v) == winningKey {
+			members = append(members, i)
+			forms = append(forms, v)
+			if weights != nil {
+				formWeights = append(formWeights, weights[i])
+			}
+		}
+	}
+	d, _ := pool(forms, formWeights, nil)
+	return members[d.first[heaviest(d.weights)]], nil
+}
+
+// heaviest returns the position of the first of the greatest
+// weights.
+func heaviest(weights []float64) int {
+	best := 0
+	for i, w := range weights {
+		if w > weights[best] {
+			best = i
+		}
+	}
+	return best
+}
@@ -0,0 +1,122 @@
+﻿package canonical
+
+import (
+	"errors"
+	"github.com/ZackPierce/stralgo"
+	"github.com/ZackPierce/stralgo/metric"
+	"github.com/ZackPierce/stralgo/pairwise"
+	"github.com/ZackPierce/stralgo/preprocess"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+var variants = []string{"Jonathon Smith", "Jonathan Smyth", "Jonatan Smith", "Jonathan Smith"}
+
+func Test_Medoid(t *testing.T) {
+	levenshtein, _ := metric.LookupDistance("levenshtein")
+	i, err := Medoid(variants, nil, levenshtein)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, i)
+
+	i, err = Medoid(variants, []float64{5, 1, 1, 1}, levenshtein)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, i)
+
+	// Repeating a variant is the same as weighting it.
+	i, err = Medoid([]string{"Jonatan Smith", "Jonathan Smith", "Jonatan Smith"}, nil, levenshtein)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, i)
+
+	jw, _ := metric.LookupSimilarity("jaro-winkler")
+	i, err = MedoidBySimilarity(variants, nil, jw)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, i)
+
+	i, err = Medoid([]string{"a"}, nil, levenshtein)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, i)
+
+	hamming, _ := metric.LookupDistance("hamming")
+	_, err = Medoid(variants, nil, hamming)
+	var pairErr *pairwise.PairError
+	assert.True(t, errors.As(err, &pairErr))
+	assert.True(t, errors.Is(err, stralgo.ErrUnequalLength))
+	assert.Equal(t, 0, pairErr.I)
+	assert.Equal(t, 2, pairErr.J)
+
+	_, err = Medoid(nil, nil, levenshtein)
+	assert.NotNil(t, err)
+	_, err = Medoid(variants, []float64{1, 2}, levenshtein)
+	assert.NotNil(t, err)
+	_, err = Medoid(variants, []float64{1, 2, -1, 1}, levenshtein)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Variant 2 has a negative weight, -1.", err.Error())
+	}
+	_, err = Medoid(variants, []float64{1, math.NaN(), 1, 1}, levenshtein)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Variant 1 has a weight of NaN.", err.Error())
+	}
+}
+
+func Test_Vote(t *testing.T) {
+	names := []string{"Smyth", "SMITH", "Smyth", "smith", "Smith ", "SMITH"}
+	i, err := Vote(names, nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, i, "Smyth and SMITH tie, and Smyth is first.")
+
+	i, err = Vote(names, []float64{1, 1, 1, 1, 1, 0.5}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, i)
+
+	i, err = Vote(names, []float64{1, 1, 1, 5, 1, 1}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, i)
+
+	fold := preprocess.Chain(preprocess.CaseFold, preprocess.CollapseWhitespace)
+	i, err = Vote(names, nil, fold)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, i, "The SMITHs win together, and SMITH is the most common of them.")
+
+	i, err = Vote(names, []float64{1, 1, 1, 1, 3, 1}, fold)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, i)
+
+	_, err = Vote(nil, nil, fold)
+	assert.NotNil(t, err)
+}
+
+func Test_Consensus(t *testing.T) {
+	tests := []struct {
+		variants  []string
+		weights   []float64
+		consensus string
+	}{
+		{[]string{"Jonathon Smith", "Jonathan Smyth", "Jonatan Smith"}, nil, "Jonathan Smith"},
+		{[]string{"Jon Smith", "John Smyth", "John Smiht"}, nil, "John Smith"},
+		{[]string{"Mississipi", "Misisippi", "Mississippi"}, nil, "Mississippi"},
+		{[]string{"Mississipi", "Misisippi", "Mississippi"}, []float64{5, 1, 1}, "Mississipi"},
+		{[]string{"abc"}, nil, "abc"},
+		{[]string{"", "", "a"}, nil, ""},
+		{[]string{"b", "ab", "abc", "bc"}, []float64{1, 2, 2, 2}, "abc"},
+		{[]string{"Z\u00fcrich", "Zurich", "Z\u00fcrch"}, nil, "Z\u00fcrich"},
+	}
+	for _, test := range tests {
+		consensus, err := Consensus(test.variants, test.weights)
+		assert.Nil(t, err)
+		assert.Equal(t, test.consensus, consensus, "%q", test.variants)
+	}
+
+	_, err := Consensus(nil, nil)
+	assert.NotNil(t, err)
+}
+
+func Test_Align(t *testing.T) {
+	aligned, inserted := align([]rune("kitten"), []rune("sitting"))
+	assert.Equal(t, []rune("sittin"), aligned)
+	assert.Equal(t, [][]rune{nil, nil, nil, nil, nil, nil, []rune("g")}, inserted)
+
+	aligned, inserted = align([]rune("abcd"), []rune("xbd"))
+	assert.Equal(t, []rune{'x', 'b', gap, 'd'}, aligned)
+	assert.Equal(t, make([][]rune, 5, 5), inserted)
+}
@@ -0,0 +1,155 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package canonical
+
+import (
+	"github.com/ZackPierce/stralgo/metric"
+	"github.com/ZackPierce/stralgo/runewise"
+)
+
+// gap marks a rune of the center which is deleted in the
+// alignment of another variant.
+const gap rune = -1
+
+// Consensus returns a consensus of the variants, built rune by
+// rune from their multiple alignment, so that it may correct
+// errors which no one variant is free of: the consensus of
+// "Jonathon Smith", "Jonathan Smyth" and "Jonatan Smith" is
+// "Jonathan Smith". A nil weights weights each variant 1.
+//
+// The variants are aligned with the center star method: the
+// center is their Medoid under the Levenshtein distance, and each
+// variant is aligned with the center by the fewest edits. Then
+// for each rune of the center, the variants vote on whether to
+// keep it, replace it or delete it, and for each place between
+// the runes of the center, on what to insert there. Ties are
+// settled in favor of the center.
+//
+// See: http://en.wikipedia.org/wiki/Multiple_sequence_alignment
+func Consensus(variants []string, weights []float64) (string, error) {
+	d, err := pool(variants, weights, nil)
+	if err != nil {
+		return "", err
+	}
+	levenshtein := metric.NewDistance(runewise.LevenshteinDistance)
+	c, err := Medoid(d.values, d.weights, levenshtein)
+	if err != nil {
+		return "", err
+	}
+	center := []rune(d.values[c])
+
+	// The votes for each rune of the center, and for the runes
+	// inserted before each rune of the center, and after the last.
+	columns := make([]ballot[rune], len(center), len(center))
+	inserts := make([]ballot[string], len(center)+1, len(center)+1)
+	for i, r := range center {
+		columns[i].vote(r, 0)
+		inserts[i].vote("", 0)
+	}
+	inserts[len(center)].vote("", 0)
+
+	for v, value := range d.values {
+		aligned, inserted := align(center, []rune(value))
+		for i, r := range aligned {
+			columns[i].vote(r, d.weights[v])
+		}
+		for i, runes := range inserted {
+			inserts[i].vote(string(runes), d.weights[v])
+		}
+	}
+
+	var consensus []rune
+	for i := range center {
+		consensus = append(consensus, []rune(inserts[i].winner())...)
+		if r := columns[i].winner(); r != gap {
+			consensus = append(consensus, r)
+		}
+	}
+	consensus = append(consensus, []rune(inserts[len(center)].winner())...)
+	return string(consensus), nil
+}
+
+// ballot counts the weighted votes for each choice, in order of
+// first vote, so that the first choice wins ties.
+type ballot[T comparable] struct {
+	choices []T
+	weights []float64
+}
+
+func (b *ballot[T]) vote(choice T, weight float64) {
+	for i, c := range b.choices {
+		if c == choice {
+			b.weights[i] += weight
+			return
+		}
+	}
+	b.choices = append(b.choices, choice)
+	b.weights = append(b.weights, weight)
+}
+
+func (b *ballot[T]) winner() T {
+	return b.choices[heaviest(b.weights)]
+}
+
+// align aligns a variant with the center by the fewest
+// Levenshtein edits. It returns the rune of the variant aligned
+// with each rune of the center, or gap, and the runes of the
+// variant inserted before each rune of the center, and after the
+// last.
+//
+// Of equally short alignments, substitutions are preferred to
+// insertions and deletions, and the alignment chosen is the same
+// for equal inputs.
+func align(center, variant []rune) ([]rune, [][]rune) {
+	n, m := len(center), len(variant)
+	// costs[i][j] is the distance between center[:i] and
+	// variant[:j].
+	costs := make([][]int, n+1, n+1)
+	for i := range costs {
+		costs[i] = make([]int, m+1, m+1)
+		costs[i][0] = i
+	}
+	for j := 0; j <= m; j++ {
+		costs[0][j] = j
+	}
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			substitution := costs[i-1][j-1]
+			if center[i-1] != variant[j-1] {
+				substitution++
+			}
+			costs[i][j] = min(substitution, costs[i-1][j]+1, costs[i][j-1]+1)
+		}
+	}
+
+	aligned := make([]rune, n, n)
+	inserted := make([][]rune, n+1, n+1)
+	i, j := n, m
+	for i > 0 || j > 0 {
+		switch {
+		case i > 0 && j > 0 && costs[i][j] == costs[i-1][j-1]+boolToInt(center[i-1] != variant[j-1]):
+			i--
+			j--
+			aligned[i] = variant[j]
+		case i > 0 && costs[i][j] == costs[i-1][j]+1:
+			i--
+			aligned[i] = gap
+		default:
+			j--
+			// Insertions are found last first.
+			inserted[i] = append([]rune{variant[j]}, inserted[i]...)
+		}
+	}
+	return aligned, inserted
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
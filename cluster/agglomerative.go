@@ -0,0 +1,137 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"github.com/ZackPierce/stralgo/metric"
+	"github.com/ZackPierce/stralgo/pairwise"
+	"math"
+)
+
+// Linkage is the measure of the distance between two clusters
+// used by agglomerative clustering.
+type Linkage int
+
+const (
+	SingleLinkage   Linkage = iota // The distance between the closest members of the clusters.
+	CompleteLinkage                // The distance between the furthest members of the clusters.
+	AverageLinkage                 // The mean distance between the members of the clusters.
+)
+
+func (l Linkage) String() string {
+	switch l {
+	case SingleLinkage:
+		return "single"
+	case CompleteLinkage:
+		return "complete"
+	case AverageLinkage:
+		return "average"
+	}
+	return fmt.Sprintf("Linkage(%d)", int(l))
+}
+
+// Agglomerative clusters items hierarchically: starting from a
+// cluster for each item, it repeatedly merges the two closest
+// clusters under the given linkage, for as long as they are no
+// more than maxDistance apart.
+//
+// Single linkage may chain dissimilar items together through
+// intermediate ones; complete linkage keeps every pair of members
+// of a cluster within maxDistance; average linkage lies between.
+//
+// Agglomerative takes time proportional to the square of the
+// number of items, using the nearest-neighbor chain algorithm, and
+// memory for a copy of the matrix. Agglomerative panics if the
+// linkage is unknown.
+//
+// See: http://en.wikipedia.org/wiki/Hierarchical_clustering
+//
+// See: http://en.wikipedia.org/wiki/Nearest-neighbor_chain_algorithm
+func Agglomerative[N metric.Number](m *pairwise.Matrix[N], linkage Linkage, maxDistance float64) *Clustering {
+	var update func(da, db float64, na, nb int) float64
+	switch linkage {
+	case SingleLinkage:
+		update = func(da, db float64, na, nb int) float64 { return math.Min(da, db) }
+	case CompleteLinkage:
+		update = func(da, db float64, na, nb int) float64 { return math.Max(da, db) }
+	case AverageLinkage:
+		update = func(da, db float64, na, nb int) float64 {
+			return (float64(na)*da + float64(nb)*db) / float64(na+nb)
+		}
+	default:
+		panic(fmt.Sprintf("cluster: unknown linkage %v", linkage))
+	}
+
+	n := m.Size
+	// The distances between clusters are kept in a copy of the
+	// matrix, each cluster being identified by one of its items.
+	d := &pairwise.Matrix[float64]{Size: n, Values: make([]float64, len(m.Values), len(m.Values))}
+	for i, v := range m.Values {
+		d.Values[i] = float64(v)
+	}
+	sizes := make([]int, n, n)
+	active := make([]bool, n, n)
+	for i := range sizes {
+		sizes[i] = 1
+		active[i] = true
+	}
+	sets := newDisjointSets(n)
+
+	// Each linkage is reducible, so a pair of clusters which are
+	// each other's nearest neighbors may be merged whenever they
+	// are found, giving the same hierarchy as merging the closest
+	// pair first; and the merges no more than maxDistance apart
+	// are exactly those of the clusters within maxDistance.
+	chain := make([]int, 0, n)
+	for remaining := n; remaining > 1; {
+		if len(chain) == 0 {
+			for i := range active {
+				if active[i] {
+					chain = append(chain, i)
+					break
+				}
+			}
+		}
+		a := chain[len(chain)-1]
+		// The previous cluster in the chain is preferred among
+		// equally near neighbors, so that the chain cannot cycle.
+		nearest, nearestDistance := -1, math.Inf(1)
+		if len(chain) > 1 {
+			nearest = chain[len(chain)-2]
+			nearestDistance = d.At(a, nearest)
+		}
+		for k := range active {
+			if active[k] && k != a {
+				if dist := d.At(a, k); nearest < 0 || dist < nearestDistance {
+					nearest, nearestDistance = k, dist
+				}
+			}
+		}
+		if len(chain) < 2 || nearest != chain[len(chain)-2] {
+			chain = append(chain, nearest)
+			continue
+		}
+
+		// a and b are each other's nearest neighbors: merge b
+		// into a.
+		b := nearest
+		chain = chain[:len(chain)-2]
+		for k := range active {
+			if active[k] && k != a && k != b {
+				d.Values[d.Index(a, k)] = update(d.At(a, k), d.At(b, k), sizes[a], sizes[b])
+			}
+		}
+		sizes[a] += sizes[b]
+		active[b] = false
+		remaining--
+		if nearestDistance <= maxDistance {
+			sets.union(a, b)
+		}
+	}
+	return newClustering(m, sets.labels())
+}
@@ -0,0 +1,179 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+/*
+Package stralgo/cluster implements the clustering of strings,
+such as the variant spellings of a name, from the distances
+between every pair of them:
+
+	d, _ := metric.LookupDistance("levenshtein")
+	m, err := cluster.Distances(ctx, names, d, nil)
+	if err != nil {
+		return err
+	}
+	c := cluster.Agglomerative(m, cluster.AverageLinkage, 2)
+	for id, members := range c.Clusters() {
+		fmt.Println(names[c.Representatives[id]], len(members))
+	}
+
+Three methods are provided: agglomerative clustering, with
+single, complete or average linkage; DBSCAN; and the connected
+components of the graph joining the strings within a distance
+of each other.
+
+Each works from a pairwise.Matrix of distances, so that the
+distances, the most expensive part of clustering, may be
+computed once and clustered several ways. A similarity may be
+used through SimilarityDistances, which turns each similarity s
+into the distance 1 - s.
+*/
+package cluster
+
+import (
+	"context"
+	"github.com/ZackPierce/stralgo/metric"
+	"github.com/ZackPierce/stralgo/pairwise"
+)
+
+// Noise is the cluster ID of an item which belongs to no
+// cluster, as DBSCAN leaves the items far from any dense region.
+const Noise = -1
+
+// Clustering is the result of clustering a set of items.
+type Clustering struct {
+	// Labels holds the cluster ID of each item, or Noise. The
+	// IDs run from 0, numbered in order of the first item of each
+	// cluster.
+	Labels []int
+
+	// Representatives holds the position of the representative
+	// item of each cluster: its medoid, the member whose summed
+	// distance to the other members is least, and the first such
+	// member in case of a tie.
+	Representatives []int
+}
+
+// Clusters returns the positions of the members of each cluster,
+// in increasing order, indexed by cluster ID.
+func (c *Clustering) Clusters() [][]int {
+	clusters := make([][]int, len(c.Representatives), len(c.Representatives))
+	for i, label := range c.Labels {
+		if label != Noise {
+			clusters[label] = append(clusters[label], i)
+		}
+	}
+	return clusters
+}
+
+// Distances computes the distance between every pair of items,
+// with pairwise.Compute.
+func Distances(ctx context.Context, items []string, d metric.Distance, options *pairwise.Options) (*pairwise.Matrix[float64], error) {
+	return pairwise.Compute(ctx, items, d.Distance, options)
+}
+
+// SimilarityDistances computes the distance 1 - s for the
+// similarity s of every pair of items, with pairwise.Compute, so
+// that items may be clustered by similarity. A minimum similarity
+// of t is then a maximum distance of 1 - t.
+func SimilarityDistances(ctx context.Context, items []string, s metric.Similarity, options *pairwise.Options) (*pairwise.Matrix[float64], error) {
+	return pairwise.Compute(ctx, items, func(a, b string) (float64, error) {
+		sim, err := s.Similarity(a, b)
+		return 1 - sim, err
+	}, options)
+}
+
+// Components clusters items into the connected components of the
+// graph joining each pair of items no more than maxDistance
+// apart, so that any two items in a cluster are linked by a chain
+// of items, each within maxDistance of the next. It is the same
+// as Agglomerative with SingleLinkage, but faster.
+func Components[N metric.Number](m *pairwise.Matrix[N], maxDistance float64) *Clustering {
+	sets := newDisjointSets(m.Size)
+	for i := 0; i < m.Size; i++ {
+		for j := i + 1; j < m.Size; j++ {
+			if float64(m.At(i, j)) <= maxDistance {
+				sets.union(i, j)
+			}
+		}
+	}
+	return newClustering(m, sets.labels())
+}
+
+// disjointSets is a union-find forest over the items.
+//
+// See: http://en.wikipedia.org/wiki/Disjoint-set_data_structure
+type disjointSets []int
+
+func newDisjointSets(n int) disjointSets {
+	parents := make(disjointSets, n, n)
+	for i := range parents {
+		parents[i] = i
+	}
+	return parents
+}
+
+func (s disjointSets) find(i int) int {
+	for s[i] != i {
+		s[i] = s[s[i]]
+		i = s[i]
+	}
+	return i
+}
+
+func (s disjointSets) union(i, j int) {
+	i, j = s.find(i), s.find(j)
+	if i < j {
+		s[j] = i
+	} else {
+		s[i] = j
+	}
+}
+
+// labels returns the root of the set of each item.
+func (s disjointSets) labels() []int {
+	labels := make([]int, len(s), len(s))
+	for i := range labels {
+		labels[i] = s.find(i)
+	}
+	return labels
+}
+
+// newClustering numbers the clusters given by arbitrary labels,
+// which may be Noise, in order of their first items, and finds
+// their representatives.
+func newClustering[N metric.Number](m *pairwise.Matrix[N], labels []int) *Clustering {
+	ids := make(map[int]int)
+	c := &Clustering{Labels: make([]int, len(labels), len(labels))}
+	for i, label := range labels {
+		if label == Noise {
+			c.Labels[i] = Noise
+			continue
+		}
+		id, ok := ids[label]
+		if !ok {
+			id = len(ids)
+			ids[label] = id
+		}
+		c.Labels[i] = id
+	}
+
+	c.Representatives = make([]int, len(ids), len(ids))
+	for id, members := range c.Clusters() {
+		best, bestSum := -1, 0.0
+		for _, i := range members {
+			sum := 0.0
+			for _, j := range members {
+				if i != j {
+					sum += float64(m.At(i, j))
+				}
+			}
+			if best < 0 || sum < bestSum {
+				best, bestSum = i, sum
+			}
+		}
+		c.Representatives[id] = best
+	}
+	return c
+}
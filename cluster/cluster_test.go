@@ -0,0 +1,153 @@
+﻿package cluster
+
+import (
+	"context"
+	"github.com/ZackPierce/stralgo/metric"
+	"github.com/ZackPierce/stralgo/pairwise"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+var names = []string{
+	"Jon", "Smith", "John", "Smyth", "Joan", "Zebediah", "Smithe", "Jonn",
+}
+
+func randomMatrix(n int, seed int64) *pairwise.Matrix[float64] {
+	r := rand.New(rand.NewSource(seed))
+	m := &pairwise.Matrix[float64]{Size: n, Values: make([]float64, n*(n-1)/2, n*(n-1)/2)}
+	for i := range m.Values {
+		m.Values[i] = r.Float64()
+	}
+	return m
+}
+
+// naiveAgglomerative merges the closest pair of clusters, with
+// the linkage calculated from scratch, until none are within
+// maxDistance.
+func naiveAgglomerative(m *pairwise.Matrix[float64], linkage Linkage, maxDistance float64) []int {
+	var clusters [][]int
+	for i := 0; i < m.Size; i++ {
+		clusters = append(clusters, []int{i})
+	}
+	distance := func(a, b []int) float64 {
+		var d float64
+		switch linkage {
+		case SingleLinkage:
+			d = math.Inf(1)
+		case CompleteLinkage:
+			d = math.Inf(-1)
+		}
+		for _, i := range a {
+			for _, j := range b {
+				switch linkage {
+				case SingleLinkage:
+					d = math.Min(d, m.At(i, j))
+				case CompleteLinkage:
+					d = math.Max(d, m.At(i, j))
+				case AverageLinkage:
+					d += m.At(i, j) / float64(len(a)*len(b))
+				}
+			}
+		}
+		return d
+	}
+	for len(clusters) > 1 {
+		bestA, bestB, best := 0, 0, math.Inf(1)
+		for a := range clusters {
+			for b := a + 1; b < len(clusters); b++ {
+				if d := distance(clusters[a], clusters[b]); d < best {
+					bestA, bestB, best = a, b, d
+				}
+			}
+		}
+		if best > maxDistance {
+			break
+		}
+		clusters[bestA] = append(clusters[bestA], clusters[bestB]...)
+		clusters = append(clusters[:bestB], clusters[bestB+1:]...)
+	}
+	labels := make([]int, m.Size, m.Size)
+	for c, members := range clusters {
+		for _, i := range members {
+			labels[i] = c
+		}
+	}
+	return newClustering(m, labels).Labels
+}
+
+func Test_Agglomerative(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		m := randomMatrix(25, seed)
+		for _, linkage := range []Linkage{SingleLinkage, CompleteLinkage, AverageLinkage} {
+			for _, maxDistance := range []float64{-1, 0.05, 0.2, 0.5, 2} {
+				expected := naiveAgglomerative(m, linkage, maxDistance)
+				c := Agglomerative(m, linkage, maxDistance)
+				assert.Equal(t, expected, c.Labels, "%v linkage within %v, seed %d", linkage, maxDistance, seed)
+			}
+		}
+		assert.Equal(t, Agglomerative(m, SingleLinkage, 0.1).Labels, Components(m, 0.1).Labels)
+	}
+	assert.Panics(t, func() { Agglomerative(randomMatrix(3, 1), Linkage(7), 1) })
+	assert.Equal(t, "average", AverageLinkage.String())
+}
+
+func Test_Agglomerative_Ties(t *testing.T) {
+	// Every pair of items is equally distant.
+	m := &pairwise.Matrix[int]{Size: 5, Values: make([]int, 10, 10)}
+	for i := range m.Values {
+		m.Values[i] = 1
+	}
+	for _, linkage := range []Linkage{SingleLinkage, CompleteLinkage, AverageLinkage} {
+		assert.Equal(t, []int{0, 0, 0, 0, 0}, Agglomerative(m, linkage, 1).Labels)
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, Agglomerative(m, linkage, 0.5).Labels)
+	}
+}
+
+func Test_Strings(t *testing.T) {
+	d, _ := metric.LookupDistance("levenshtein")
+	m, err := Distances(context.Background(), names, d, nil)
+	assert.Nil(t, err)
+
+	c := Agglomerative(m, CompleteLinkage, 2)
+	assert.Equal(t, []int{0, 1, 0, 1, 0, 2, 1, 0}, c.Labels)
+	assert.Equal(t, [][]int{{0, 2, 4, 7}, {1, 3, 6}, {5}}, c.Clusters())
+	assert.Equal(t, "Jon", names[c.Representatives[0]])
+	assert.Equal(t, "Smith", names[c.Representatives[1]])
+	assert.Equal(t, 5, c.Representatives[2])
+
+	jw, _ := metric.LookupSimilarity("jaro-winkler")
+	s, err := SimilarityDistances(context.Background(), names, jw, nil)
+	assert.Nil(t, err)
+	assert.InDelta(t, 1-0.9333, s.At(0, 2), 0.001)
+	c = Components(s, 1-0.85)
+	assert.Equal(t, []int{0, 1, 0, 1, 0, 2, 1, 0}, c.Labels)
+}
+
+func Test_DBSCAN(t *testing.T) {
+	// Items on a line, at the given positions.
+	positions := []float64{0, 1, 2, 10, 11, 5.5, 20, 12, 13.5}
+	n := len(positions)
+	m := &pairwise.Matrix[float64]{Size: n, Values: make([]float64, n*(n-1)/2, n*(n-1)/2)}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			m.Values[m.Index(i, j)] = math.Abs(positions[i] - positions[j])
+		}
+	}
+
+	c := DBSCAN(m, 1.5, 3)
+	// 13.5 is a border item, within 1.5 of the core item 12, but
+	// 5.5 and 20 are noise.
+	assert.Equal(t, []int{0, 0, 0, 1, 1, Noise, Noise, 1, 1}, c.Labels)
+	assert.Equal(t, []int{1, 4}, c.Representatives)
+	assert.Equal(t, [][]int{{0, 1, 2}, {3, 4, 7, 8}}, c.Clusters())
+
+	assert.Equal(t, Components(m, 1.5).Labels, DBSCAN(m, 1.5, 1).Labels)
+
+	c = DBSCAN(m, 0.5, 2)
+	for _, label := range c.Labels {
+		assert.Equal(t, Noise, label)
+	}
+	assert.Empty(t, c.Representatives)
+}
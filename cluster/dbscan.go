@@ -0,0 +1,76 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package cluster
+
+import (
+	"github.com/ZackPierce/stralgo/metric"
+	"github.com/ZackPierce/stralgo/pairwise"
+)
+
+// DBSCAN clusters items by density: an item with at least
+// minPoints items, itself included, within eps of it is a core
+// item, and a cluster is a set of core items each within eps of
+// another, along with the items within eps of them. The remaining
+// items, far from any core item, are labelled Noise.
+//
+// eps is in the units of the distances in the matrix, such as
+// edits for a Levenshtein distance. A minPoints of 1 or less makes
+// every item a core item, so that DBSCAN gives the same clusters
+// as Components.
+//
+// An item within eps of the core items of two clusters, but not
+// itself a core item, is placed in the cluster found first, as
+// the items are visited in order.
+//
+// See: http://en.wikipedia.org/wiki/DBSCAN
+func DBSCAN[N metric.Number](m *pairwise.Matrix[N], eps float64, minPoints int) *Clustering {
+	n := m.Size
+	neighbors := func(i int) []int {
+		var found []int
+		for j := 0; j < n; j++ {
+			if j != i && float64(m.At(i, j)) <= eps {
+				found = append(found, j)
+			}
+		}
+		return found
+	}
+
+	const unvisited = -2
+	labels := make([]int, n, n)
+	for i := range labels {
+		labels[i] = unvisited
+	}
+	cluster := 0
+	for i := range labels {
+		if labels[i] != unvisited {
+			continue
+		}
+		seeds := neighbors(i)
+		if len(seeds)+1 < minPoints {
+			labels[i] = Noise
+			continue
+		}
+		labels[i] = cluster
+		for len(seeds) > 0 {
+			j := seeds[0]
+			seeds = seeds[1:]
+			if labels[j] == Noise {
+				// A border item of this cluster.
+				labels[j] = cluster
+			}
+			if labels[j] != unvisited {
+				continue
+			}
+			labels[j] = cluster
+			if more := neighbors(j); len(more)+1 >= minPoints {
+				seeds = append(seeds, more...)
+			}
+		}
+		cluster++
+	}
+	return newClustering(m, labels)
+}
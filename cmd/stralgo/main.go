@@ -0,0 +1,318 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+/*
+Command stralgo computes the string metrics of the stralgo
+packages from the command line.
+
+Usage:
+
+	stralgo compare [-metric names] [-format tsv|csv|json] A B
+	stralgo pairs [-metric names] [-format tsv|csv|json] [-header] [file ...]
+	stralgo metrics
+
+The compare command scores the strings A and B. The pairs command
+scores each line of tab-separated values read from the named
+files, or from standard input if there are none or a file is
+named "-", comparing the first two fields of each line; with
+-header, the first line of each input is skipped. The metrics
+command lists the names of the available metrics.
+
+The -metric flag takes a comma-separated list of metric names, as
+listed by the metrics command, or "all" to compute every metric
+at once; it defaults to "jaro-winkler". Scores are written as
+tab-separated or comma-separated values, with a header row naming
+the columns, or as JSON Lines, one object per pair. Tab-separated
+values are written as read, without quoting, so a string holding a
+tab or a newline can only be written as CSV or JSON:
+
+	{"a":"MARTHA","b":"MARHTA","scores":{"jaro-winkler":0.9611111111111111}}
+
+A metric which is undefined for a pair, such as the Hamming
+distance between strings of unequal length, leaves an empty cell,
+or a null score along with an "errors" entry in JSON.
+*/
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/ZackPierce/stralgo/metric"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+const usage = `Usage:
+	stralgo compare [-metric names] [-format tsv|csv|json] A B
+	stralgo pairs [-metric names] [-format tsv|csv|json] [-header] [file ...]
+	stralgo metrics
+`
+
+// run executes the command given by args, and returns the exit
+// status: 0 on success, 1 on failure, and 2 on a usage error.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	command, args := args[0], args[1:]
+	if command == "metrics" {
+		for _, name := range metric.Names() {
+			fmt.Fprintln(stdout, name)
+		}
+		return 0
+	}
+	if command != "compare" && command != "pairs" {
+		fmt.Fprintf(stderr, "stralgo: unknown command %q\n%s", command, usage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	names := flags.String("metric", "jaro-winkler", `comma-separated metric names, or "all"`)
+	format := flags.String("format", "tsv", "output format: tsv, csv or json")
+	header := flags.Bool("header", false, "skip the first line of each input (pairs only)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	scorers, err := lookup(*names)
+	if err != nil {
+		fmt.Fprintf(stderr, "stralgo: %v\n", err)
+		return 2
+	}
+	out, err := newWriter(*format, stdout, scorers)
+	if err != nil {
+		fmt.Fprintf(stderr, "stralgo: %v\n", err)
+		return 2
+	}
+
+	if command == "compare" {
+		if flags.NArg() != 2 {
+			fmt.Fprintf(stderr, "stralgo: compare takes exactly two strings\n%s", usage)
+			return 2
+		}
+		err = out.write(flags.Arg(0), flags.Arg(1))
+	} else {
+		err = pairs(flags.Args(), *header, stdin, out)
+	}
+	// Rows scored before an error are still written.
+	if flushErr := out.flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "stralgo: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// scorer computes one metric for a pair of strings.
+type scorer struct {
+	name  string
+	score func(a, b string) (float64, error)
+}
+
+// lookup resolves a comma-separated list of metric names.
+func lookup(names string) ([]scorer, error) {
+	list := strings.Split(names, ",")
+	if names == "all" {
+		list = metric.Names()
+	}
+	scorers := make([]scorer, 0, len(list))
+	for _, name := range list {
+		name = strings.TrimSpace(name)
+		if d, err := metric.LookupDistance(name); err == nil {
+			scorers = append(scorers, scorer{name, d.Distance})
+		} else if s, err := metric.LookupSimilarity(name); err == nil {
+			scorers = append(scorers, scorer{name, s.Similarity})
+		} else {
+			return nil, fmt.Errorf("unknown metric %q; see \"stralgo metrics\"", name)
+		}
+	}
+	return scorers, nil
+}
+
+// pairs scores the tab-separated pairs read from each of the
+// named files, or from stdin.
+func pairs(files []string, header bool, stdin io.Reader, out writer) error {
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	for _, name := range files {
+		if name == "-" {
+			if err := scanPairs("standard input", stdin, header, out); err != nil {
+				return err
+			}
+			continue
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		err = scanPairs(name, f, header, out)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanPairs scores the tab-separated pairs read from r. Blank
+// lines are skipped, and fields after the second are ignored.
+func scanPairs(name string, r io.Reader, header bool, out writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if header && line == 1 {
+			continue
+		}
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if text == "" {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) < 2 {
+			return fmt.Errorf("%s:%d: expected two tab-separated fields", name, line)
+		}
+		if err := out.write(fields[0], fields[1]); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	return nil
+}
+
+// writer writes the scores of pairs in one of the output formats.
+type writer interface {
+	write(a, b string) error
+	flush() error
+}
+
+func newWriter(format string, w io.Writer, scorers []scorer) (writer, error) {
+	switch format {
+	case "tsv":
+		return newTableWriter(&tsvWriter{w: bufio.NewWriter(w)}, scorers)
+	case "csv":
+		return newTableWriter(csv.NewWriter(w), scorers)
+	case "json":
+		return &jsonWriter{enc: json.NewEncoder(w), scorers: scorers}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q; use tsv, csv or json", format)
+}
+
+// rowWriter writes rows of fields, as csv.Writer does.
+type rowWriter interface {
+	Write(row []string) error
+	Flush()
+	Error() error
+}
+
+// tsvWriter writes rows of tab-separated values without quoting,
+// in the form read by scanPairs.
+type tsvWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (t *tsvWriter) Write(row []string) error {
+	for _, field := range row {
+		if strings.ContainsAny(field, "\t\n") {
+			return fmt.Errorf("cannot write %q as tab-separated values; use -format csv or json", field)
+		}
+	}
+	for i, field := range row {
+		if i > 0 {
+			t.w.WriteByte('\t')
+		}
+		t.w.WriteString(field)
+	}
+	return t.w.WriteByte('\n')
+}
+
+func (t *tsvWriter) Flush() {
+	t.err = t.w.Flush()
+}
+
+func (t *tsvWriter) Error() error {
+	return t.err
+}
+
+// tableWriter writes a header row followed by a row per pair.
+type tableWriter struct {
+	rows    rowWriter
+	scorers []scorer
+	row     []string
+}
+
+func newTableWriter(rows rowWriter, scorers []scorer) (*tableWriter, error) {
+	t := &tableWriter{rows: rows, scorers: scorers, row: make([]string, len(scorers)+2, len(scorers)+2)}
+	t.row[0], t.row[1] = "a", "b"
+	for i, s := range scorers {
+		t.row[i+2] = s.name
+	}
+	return t, rows.Write(t.row)
+}
+
+func (t *tableWriter) write(a, b string) error {
+	t.row[0], t.row[1] = a, b
+	for i, s := range t.scorers {
+		t.row[i+2] = ""
+		if score, err := s.score(a, b); err == nil {
+			t.row[i+2] = strconv.FormatFloat(score, 'g', -1, 64)
+		}
+	}
+	return t.rows.Write(t.row)
+}
+
+func (t *tableWriter) flush() error {
+	t.rows.Flush()
+	return t.rows.Error()
+}
+
+// jsonWriter writes a JSON object per pair.
+type jsonWriter struct {
+	enc     *json.Encoder
+	scorers []scorer
+}
+
+type jsonRecord struct {
+	A      string              `json:"a"`
+	B      string              `json:"b"`
+	Scores map[string]*float64 `json:"scores"`
+	Errors map[string]string   `json:"errors,omitempty"`
+}
+
+func (j *jsonWriter) write(a, b string) error {
+	record := jsonRecord{A: a, B: b, Scores: make(map[string]*float64, len(j.scorers))}
+	for _, s := range j.scorers {
+		score, err := s.score(a, b)
+		if err != nil {
+			if record.Errors == nil {
+				record.Errors = make(map[string]string)
+			}
+			record.Errors[s.name] = err.Error()
+			record.Scores[s.name] = nil
+			continue
+		}
+		record.Scores[s.name] = &score
+	}
+	return j.enc.Encode(record)
+}
+
+func (j *jsonWriter) flush() error {
+	return nil
+}
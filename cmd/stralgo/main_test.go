@@ -0,0 +1,108 @@
+﻿package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runCommand(stdin string, args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	code = run(args, strings.NewReader(stdin), &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func Test_Compare(t *testing.T) {
+	code, out, _ := runCommand("", "compare", "MARTHA", "MARHTA")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "a\tb\tjaro-winkler\nMARTHA\tMARHTA\t0.9611111111111111\n", out)
+
+	code, out, _ = runCommand("", "compare", "-metric", "levenshtein,hamming", "-format", "csv", "kitten", "sitting")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "a,b,levenshtein,hamming\nkitten,sitting,3,\n", out)
+
+	code, out, _ = runCommand("", "compare", "-metric", "hamming,levenshtein", "-format", "json", "ab", "abc")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, `{"a":"ab","b":"abc","scores":{"hamming":null,"levenshtein":1},"errors":{"hamming":"Hamming distance is undefined between inputs of unequal length, 2 and 3."}}`+"\n", out)
+}
+
+func Test_Compare_AllMetrics(t *testing.T) {
+	code, out, _ := runCommand("", "compare", "-metric", "all", "-format", "csv", "night", "nacht")
+	assert.Equal(t, 0, code)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if assert.Len(t, lines, 2) {
+		header := strings.Split(lines[0], ",")
+		assert.Equal(t, []string{"a", "b"}, header[:2])
+		code, names, _ := runCommand("", "metrics")
+		assert.Equal(t, 0, code)
+		assert.Equal(t, strings.Fields(names), header[2:])
+		assert.Len(t, strings.Split(lines[1], ","), len(header))
+	}
+}
+
+func Test_Pairs(t *testing.T) {
+	input := "left\tright\nkitten\tsitting\textra\n\nflaw\tlawn\r\n"
+	code, out, _ := runCommand(input, "pairs", "-metric", "levenshtein", "-header")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "a\tb\tlevenshtein\nkitten\tsitting\t3\nflaw\tlawn\t2\n", out)
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "pairs.tsv")
+	assert.Nil(t, os.WriteFile(file, []byte("a\tb\n"), 0o644))
+	code, out, _ = runCommand("ab\tba\n", "pairs", "-metric", "damerau-levenshtein", "-format", "json", file, "-")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, `{"a":"a","b":"b","scores":{"damerau-levenshtein":1}}`+"\n"+`{"a":"ab","b":"ba","scores":{"damerau-levenshtein":1}}`+"\n", out)
+}
+
+func Test_Pairs_TSVIsUnquoted(t *testing.T) {
+	code, out, _ := runCommand("a\"b\tab\n", "pairs", "-metric", "levenshtein")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "a\tb\tlevenshtein\na\"b\tab\t1\n", out)
+
+	code, out, errOut := runCommand("", "compare", "a\tb", "ab")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "use -format csv or json")
+	assert.Equal(t, "a\tb\tjaro-winkler\n", out)
+
+	code, out, _ = runCommand("", "compare", "-metric", "levenshtein", "-format", "csv", "a\tb", "ab")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "a,b,levenshtein\na\tb,ab,1\n", out)
+}
+
+func Test_Pairs_WritesRowsBeforeAnError(t *testing.T) {
+	input := "kitten\tsitting\nno tab\n"
+	for _, format := range []string{"tsv", "csv"} {
+		code, out, errOut := runCommand(input, "pairs", "-metric", "levenshtein", "-format", format)
+		assert.Equal(t, 1, code)
+		assert.Contains(t, errOut, "standard input:2")
+		sep := map[string]string{"tsv": "\t", "csv": ","}[format]
+		assert.Equal(t, strings.Join([]string{"a", "b", "levenshtein"}, sep)+"\n"+strings.Join([]string{"kitten", "sitting", "3"}, sep)+"\n", out, format)
+	}
+}
+
+func Test_Errors(t *testing.T) {
+	code, _, errOut := runCommand("")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errOut, "Usage:")
+
+	code, _, errOut = runCommand("", "compare", "-metric", "nonesuch", "a", "b")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errOut, `unknown metric "nonesuch"`)
+
+	code, _, errOut = runCommand("", "compare", "-format", "xml", "a", "b")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errOut, `unknown format "xml"`)
+
+	code, _, _ = runCommand("", "compare", "a")
+	assert.Equal(t, 2, code)
+
+	code, _, errOut = runCommand("a\tb\nno tab\n", "pairs")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "standard input:2: expected two tab-separated fields")
+
+	code, _, _ = runCommand("", "pairs", filepath.Join(t.TempDir(), "missing.tsv"))
+	assert.Equal(t, 1, code)
+}
@@ -0,0 +1,72 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package dedup
+
+import (
+	"fmt"
+	"github.com/ZackPierce/stralgo/preprocess"
+	"github.com/ZackPierce/stralgo/tokenize"
+)
+
+// BlockingKey computes the values of a blocking key from one
+// field of each record. Two records are compared if they share a
+// value. Empty values are ignored, so a record may be left out of
+// a key altogether.
+type BlockingKey struct {
+	// Field is the position of the field the key is computed
+	// from, which must be one of the fields of the Config.
+	Field int
+	// Values returns the values of the key for a value of the
+	// field.
+	Values func(value string) []string
+}
+
+// values returns the values of the key for a record.
+func (k BlockingKey) values(r Record) []string {
+	return k.Values(r[k.Field])
+}
+
+// normalizeKey folds case, strips diacritics and collapses
+// whitespace, so that a key value is not split by trivial
+// differences.
+var normalizeKey = preprocess.Chain(preprocess.CaseFold, preprocess.StripDiacritics, preprocess.CollapseWhitespace)
+
+// ExactKey blocks records whose values of the given field are
+// equal, ignoring case, diacritics and whitespace.
+func ExactKey(field int) BlockingKey {
+	return BlockingKey{Field: field, Values: func(value string) []string {
+		return []string{normalizeKey.Apply(value)}
+	}}
+}
+
+// PrefixKey blocks records whose values of the given field start
+// with the same n runes, ignoring case, diacritics and
+// whitespace. Values shorter than n runes are used whole.
+//
+// PrefixKey panics if n is less than 1.
+func PrefixKey(field, n int) BlockingKey {
+	if n < 1 {
+		panic(fmt.Sprintf("dedup: a PrefixKey needs a prefix of at least 1 rune, not %d", n))
+	}
+	return BlockingKey{Field: field, Values: func(value string) []string {
+		prefix := normalizeKey([]rune(value))
+		if len(prefix) > n {
+			prefix = prefix[:n]
+		}
+		return []string{string(prefix)}
+	}}
+}
+
+// TokenKey blocks records whose values of the given field share
+// a token, ignoring case and diacritics, as found by tokenizer.
+// Blocking on the words of a name, for example, compares records
+// whose names share a word, even if the words are reordered.
+func TokenKey(field int, tokenizer tokenize.Tokenizer) BlockingKey {
+	return BlockingKey{Field: field, Values: func(value string) []string {
+		return tokenizer.Tokenize(normalizeKey.Apply(value))
+	}}
+}
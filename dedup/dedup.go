@@ -0,0 +1,437 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+/*
+Package stralgo/dedup implements record linkage and
+deduplication: finding the records which describe the same
+entity, within one list or across two, when their fields may be
+misspelled, abbreviated or missing.
+
+Each field of a pair of records is compared with its own
+similarity metric, and the similarities are combined into a
+weighted score, which classifies the pair as a match, a possible
+match for a person to review, or a non-match:
+
+	jw, _ := metric.LookupSimilarity("jaro-winkler")
+	white, _ := metric.LookupSimilarity("white")
+	hamming, _ := metric.LookupDistance("hamming")
+	config := &dedup.Config{
+		Fields: []dedup.Field{
+			{Name: "name", Similarity: jw, Weight: 2},
+			{Name: "street", Similarity: white, Weight: 1},
+			{Name: "postcode", Similarity: metric.NormalizeDistance(hamming), Weight: 1},
+		},
+		MatchThreshold:    0.9,
+		PossibleThreshold: 0.75,
+		Blocking:          []dedup.BlockingKey{dedup.PrefixKey(0, 3), dedup.ExactKey(2)},
+	}
+	pairs, err := config.Deduplicate(ctx, records)
+
+Comparing every pair of n records takes time proportional to the
+square of n, which is too slow for large lists. Blocking keys avoid this: only
+records which share the value of at least one blocking key are
+compared, so a key should be one which the records of an entity
+are likely to share, despite errors in their fields.
+
+See: http://en.wikipedia.org/wiki/Record_linkage
+*/
+package dedup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ZackPierce/stralgo/metric"
+	"math"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Record is a record to be compared, holding the value of each
+// of the fields of a Config, in order. An empty value, or one of
+// only whitespace, is missing.
+type Record []string
+
+// Field configures the comparison of one field of the records.
+type Field struct {
+	// Name describes the field.
+	Name string
+	// Similarity compares the values of the field. A Distance
+	// may be used by adapting it with metric.NormalizeDistance.
+	// Where the similarity fails for a pair of values, such as a
+	// Hamming distance between values of unequal length, the
+	// values are scored 0.
+	Similarity metric.Similarity
+	// Weight is the weight of the field's similarity in the
+	// score of a pair of records. It must not be negative.
+	Weight float64
+}
+
+// Class is the classification of a pair of records.
+type Class int
+
+const (
+	NonMatch      Class = iota // The records describe different entities.
+	PossibleMatch              // The records may describe the same entity, and should be reviewed.
+	Match                      // The records describe the same entity.
+)
+
+func (c Class) String() string {
+	switch c {
+	case NonMatch:
+		return "non-match"
+	case PossibleMatch:
+		return "possible match"
+	case Match:
+		return "match"
+	}
+	return fmt.Sprintf("Class(%d)", int(c))
+}
+
+// Config configures the comparison of records.
+type Config struct {
+	// Fields configures the comparison of each field.
+	Fields []Field
+
+	// MatchThreshold is the lowest score of a Match, and
+	// PossibleThreshold the lowest score of a PossibleMatch.
+	// Both must be finite, and PossibleThreshold must be no
+	// greater than MatchThreshold.
+	MatchThreshold    float64
+	PossibleThreshold float64
+
+	// Blocking holds the keys used to choose the pairs of records
+	// to compare. Two records are compared only if a key gives
+	// them a value in common. If Blocking is empty, every pair of
+	// records is compared.
+	Blocking []BlockingKey
+
+	// MaxBlockSize, if positive, is the largest number of records
+	// which may share a key value. Values shared by more records,
+	// such as a common surname, are too common to be useful, and
+	// are ignored.
+	MaxBlockSize int
+
+	// Workers is the number of goroutines comparing records. It
+	// defaults to runtime.GOMAXPROCS(0). The Similarity of each
+	// field must be safe for concurrent use.
+	Workers int
+}
+
+// Comparison is the result of comparing two records.
+type Comparison struct {
+	// Score is the weighted mean of the similarities of the
+	// fields which neither record is missing, or 0 if there are
+	// none.
+	Score float64
+	// Fields holds the similarity of each field, or NaN for a
+	// field which either record is missing.
+	Fields []float64
+	// Class is the classification of the score.
+	Class Class
+}
+
+// Pair is a Comparison between two records, identified by their
+// positions.
+type Pair struct {
+	A, B int
+	Comparison
+}
+
+// validate checks the Config.
+func (c *Config) validate() error {
+	if len(c.Fields) == 0 {
+		return errors.New("A Config must have at least one Field.")
+	}
+	for i, f := range c.Fields {
+		if f.Similarity == nil {
+			return fmt.Errorf("Field %d (%s) has no Similarity.", i, f.Name)
+		}
+		if math.IsNaN(f.Weight) {
+			return fmt.Errorf("Field %d (%s) has a weight of NaN.", i, f.Name)
+		}
+		if f.Weight < 0 {
+			return fmt.Errorf("Field %d (%s) has a negative weight, %v.", i, f.Name, f.Weight)
+		}
+	}
+	if math.IsNaN(c.MatchThreshold) || math.IsInf(c.MatchThreshold, 0) {
+		return fmt.Errorf("The MatchThreshold, %v, is not a finite number.", c.MatchThreshold)
+	}
+	if math.IsNaN(c.PossibleThreshold) || math.IsInf(c.PossibleThreshold, 0) {
+		return fmt.Errorf("The PossibleThreshold, %v, is not a finite number.", c.PossibleThreshold)
+	}
+	if c.PossibleThreshold > c.MatchThreshold {
+		return fmt.Errorf("The PossibleThreshold, %v, exceeds the MatchThreshold, %v.", c.PossibleThreshold, c.MatchThreshold)
+	}
+	for i, k := range c.Blocking {
+		if k.Values == nil {
+			return fmt.Errorf("Blocking key %d has no Values function.", i)
+		}
+		if k.Field < 0 || k.Field >= len(c.Fields) {
+			return fmt.Errorf("Blocking key %d uses field %d, but the Config has %d fields.", i, k.Field, len(c.Fields))
+		}
+	}
+	return nil
+}
+
+// checkRecords checks that each record has a value for each field.
+func (c *Config) checkRecords(records []Record) error {
+	for i, r := range records {
+		if len(r) != len(c.Fields) {
+			return fmt.Errorf("Record %d has %d fields, but the Config has %d.", i, len(r), len(c.Fields))
+		}
+	}
+	return nil
+}
+
+// Classify returns the class of a score.
+func (c *Config) Classify(score float64) Class {
+	switch {
+	case score >= c.MatchThreshold:
+		return Match
+	case score >= c.PossibleThreshold:
+		return PossibleMatch
+	}
+	return NonMatch
+}
+
+// Compare compares two records.
+func (c *Config) Compare(a, b Record) (Comparison, error) {
+	if err := c.validate(); err != nil {
+		return Comparison{}, err
+	}
+	if err := c.checkRecords([]Record{a, b}); err != nil {
+		return Comparison{}, err
+	}
+	return c.compare(a, b), nil
+}
+
+func (c *Config) compare(a, b Record) Comparison {
+	fields := make([]float64, len(c.Fields), len(c.Fields))
+	var sum, weights float64
+	for i, f := range c.Fields {
+		if missing(a[i]) || missing(b[i]) {
+			fields[i] = math.NaN()
+			continue
+		}
+		s, err := f.Similarity.Similarity(a[i], b[i])
+		if err != nil {
+			s = 0
+		}
+		fields[i] = s
+		sum += f.Weight * s
+		weights += f.Weight
+	}
+	score := 0.0
+	if weights > 0 {
+		score = sum / weights
+	}
+	return Comparison{Score: score, Fields: fields, Class: c.Classify(score)}
+}
+
+func missing(value string) bool {
+	return strings.TrimSpace(value) == ""
+}
+
+// Deduplicate compares the pairs of records chosen by the
+// blocking keys, and returns those classed as a Match or a
+// PossibleMatch, ordered by A and then B, with A < B.
+//
+// If ctx is cancelled before every pair has been compared,
+// Deduplicate stops early and returns ctx.Err().
+func (c *Config) Deduplicate(ctx context.Context, records []Record) ([]Pair, error) {
+	pairs, err := c.Candidates(ctx, records)
+	return mayMatch(pairs), err
+}
+
+// Candidates is like Deduplicate, but returns every pair of
+// records compared, whatever its class, as needed to estimate
+// the parameters of a FellegiSunter model.
+func (c *Config) Candidates(ctx context.Context, records []Record) ([]Pair, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	if err := c.checkRecords(records); err != nil {
+		return nil, err
+	}
+	candidates := c.candidates(records, len(records))
+	return c.comparePairs(ctx, records, records, candidates)
+}
+
+// Link compares the pairs of a record from a and a record from b
+// chosen by the blocking keys, and returns those classed as a
+// Match or a PossibleMatch, ordered by A and then B, where A is
+// the position of a record in a and B the position of a record
+// in b.
+//
+// If ctx is cancelled before every pair has been compared, Link
+// stops early and returns ctx.Err().
+func (c *Config) Link(ctx context.Context, a, b []Record) ([]Pair, error) {
+	pairs, err := c.LinkCandidates(ctx, a, b)
+	return mayMatch(pairs), err
+}
+
+// LinkCandidates is like Link, but returns every pair of records
+// compared, whatever its class.
+func (c *Config) LinkCandidates(ctx context.Context, a, b []Record) ([]Pair, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	if err := c.checkRecords(a); err != nil {
+		return nil, err
+	}
+	if err := c.checkRecords(b); err != nil {
+		return nil, err
+	}
+	records := make([]Record, 0, len(a)+len(b))
+	records = append(append(records, a...), b...)
+	candidates := c.candidates(records, len(a))
+	for i := range candidates {
+		candidates[i][1] -= len(a)
+	}
+	return c.comparePairs(ctx, a, b, candidates)
+}
+
+// mayMatch returns the pairs classed as a Match or a
+// PossibleMatch.
+func mayMatch(pairs []Pair) []Pair {
+	if pairs == nil {
+		return nil
+	}
+	matches := pairs[:0]
+	for _, p := range pairs {
+		if p.Class != NonMatch {
+			matches = append(matches, p)
+		}
+	}
+	return matches
+}
+
+// compareChunk is the number of pairs compared by a worker at a
+// time, between checks for the cancellation of the context.
+const compareChunk = 256
+
+// comparePairs compares each candidate pair of a record of a and
+// a record of b, and returns the results in the order of the
+// candidates.
+func (c *Config) comparePairs(ctx context.Context, a, b []Record, candidates [][2]int) ([]Pair, error) {
+	workers := c.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = max(1, min(workers, (len(candidates)+compareChunk-1)/compareChunk))
+
+	results := make([]Pair, len(candidates), len(candidates))
+	var (
+		next     atomic.Int64
+		canceled atomic.Bool
+		wg       sync.WaitGroup
+	)
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				from := int(next.Add(compareChunk)) - compareChunk
+				if from >= len(candidates) {
+					return
+				}
+				if ctx.Err() != nil {
+					canceled.Store(true)
+					return
+				}
+				to := min(from+compareChunk, len(candidates))
+				for i := from; i < to; i++ {
+					p := candidates[i]
+					results[i] = Pair{A: p[0], B: p[1], Comparison: c.compare(a[p[0]], b[p[1]])}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if canceled.Load() {
+		return nil, ctx.Err()
+	}
+	return results, nil
+}
+
+// candidates returns the pairs of records to compare, in order.
+// Records before split are paired only with those from split
+// onwards, unless split is len(records), when every pair may be.
+func (c *Config) candidates(records []Record, split int) [][2]int {
+	linking := split < len(records)
+	var pairs [][2]int
+	if len(c.Blocking) == 0 {
+		for i := 0; i < split; i++ {
+			from := i + 1
+			if linking {
+				from = split
+			}
+			for j := from; j < len(records); j++ {
+				pairs = append(pairs, [2]int{i, j})
+			}
+		}
+		return pairs
+	}
+
+	seen := make(map[[2]int]struct{})
+	for _, key := range c.Blocking {
+		blocks := make(map[string][]int)
+		for i, r := range records {
+			for _, value := range uniqueKeys(key.values(r)) {
+				blocks[value] = append(blocks[value], i)
+			}
+		}
+		for _, block := range blocks {
+			if c.MaxBlockSize > 0 && len(block) > c.MaxBlockSize {
+				continue
+			}
+			for x, i := range block {
+				for _, j := range block[x+1:] {
+					if linking && (i >= split || j < split) {
+						continue
+					}
+					pair := [2]int{i, j}
+					if _, ok := seen[pair]; !ok {
+						seen[pair] = struct{}{}
+						pairs = append(pairs, pair)
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(pairs, func(x, y int) bool {
+		if pairs[x][0] != pairs[y][0] {
+			return pairs[x][0] < pairs[y][0]
+		}
+		return pairs[x][1] < pairs[y][1]
+	})
+	return pairs
+}
+
+// uniqueKeys returns the values of keys which are not empty,
+// without repetition.
+func uniqueKeys(keys []string) []string {
+	unique := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k == "" {
+			continue
+		}
+		repeated := false
+		for _, u := range unique {
+			if u == k {
+				repeated = true
+				break
+			}
+		}
+		if !repeated {
+			unique = append(unique, k)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,196 @@
+﻿package dedup
+
+import (
+	"context"
+	"github.com/ZackPierce/stralgo/metric"
+	"github.com/ZackPierce/stralgo/tokenize"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+var people = []Record{
+	{"John Smith", "12 High Street", "SW1A 1AA"},
+	{"Jon Smith", "12 High St", "SW1A 1AA"},
+	{"Mary Jones", "4 Mill Lane", "OX1 2JD"},
+	{"Jane Doe", "99 Station Road", "M1 1AE"},
+	{"Mary Jones", "", "OX1 2JD"},
+	{"J. Smith", "12 High Street", "SW1A 1AB"},
+}
+
+func testConfig() *Config {
+	jw, _ := metric.LookupSimilarity("jaro-winkler")
+	white, _ := metric.LookupSimilarity("white")
+	hamming, _ := metric.LookupDistance("hamming")
+	return &Config{
+		Fields: []Field{
+			{Name: "name", Similarity: jw, Weight: 2},
+			{Name: "street", Similarity: white, Weight: 1},
+			{Name: "postcode", Similarity: metric.NormalizeDistance(hamming), Weight: 1},
+		},
+		MatchThreshold:    0.9,
+		PossibleThreshold: 0.75,
+	}
+}
+
+func Test_Compare(t *testing.T) {
+	config := testConfig()
+	c, err := config.Compare(people[0], people[1])
+	assert.Nil(t, err)
+	assert.Equal(t, Match, c.Class)
+	assert.Equal(t, 1.0, c.Fields[2])
+
+	c, err = config.Compare(people[2], people[4])
+	assert.Nil(t, err)
+	assert.True(t, math.IsNaN(c.Fields[1]), "A missing field is not compared.")
+	assert.Equal(t, 1.0, c.Score)
+
+	c, err = config.Compare(people[0], people[3])
+	assert.Nil(t, err)
+	assert.Equal(t, NonMatch, c.Class)
+
+	c, err = config.Compare(Record{"", "", ""}, people[0])
+	assert.Nil(t, err)
+	assert.Equal(t, 0.0, c.Score)
+
+	c, err = config.Compare(Record{"a", "b", "SW1A"}, people[0])
+	assert.Nil(t, err)
+	assert.Equal(t, 0.0, c.Fields[2], "A failed similarity scores 0.")
+
+	_, err = config.Compare(Record{"too", "short"}, people[0])
+	assert.NotNil(t, err)
+	assert.Equal(t, "match", Match.String())
+	assert.Equal(t, "possible match", PossibleMatch.String())
+}
+
+func Test_Config_Validate(t *testing.T) {
+	config := testConfig()
+	config.PossibleThreshold = 0.95
+	_, err := config.Deduplicate(context.Background(), people)
+	assert.NotNil(t, err)
+
+	config = testConfig()
+	config.Fields[1].Weight = -1
+	_, err = config.Deduplicate(context.Background(), people)
+	assert.NotNil(t, err)
+
+	config = testConfig()
+	config.Fields[1].Weight = math.NaN()
+	_, err = config.Deduplicate(context.Background(), people)
+	assert.EqualError(t, err, "Field 1 (street) has a weight of NaN.")
+
+	for _, threshold := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		config = testConfig()
+		config.MatchThreshold = threshold
+		_, err = config.Deduplicate(context.Background(), people)
+		assert.NotNil(t, err, "MatchThreshold %v", threshold)
+
+		config = testConfig()
+		config.PossibleThreshold = threshold
+		_, err = config.Deduplicate(context.Background(), people)
+		assert.NotNil(t, err, "PossibleThreshold %v", threshold)
+	}
+
+	config = testConfig()
+	config.Blocking = []BlockingKey{ExactKey(2), PrefixKey(3, 2)}
+	_, err = config.Deduplicate(context.Background(), people)
+	assert.EqualError(t, err, "Blocking key 1 uses field 3, but the Config has 3 fields.")
+	config.Blocking = []BlockingKey{{Field: 0}}
+	_, err = config.Link(context.Background(), people, people)
+	assert.NotNil(t, err)
+
+	config = testConfig()
+	config.Fields[0].Similarity = nil
+	_, err = config.Compare(people[0], people[1])
+	assert.NotNil(t, err)
+
+	_, err = (&Config{}).Deduplicate(context.Background(), people)
+	assert.NotNil(t, err)
+}
+
+func pairIDs(pairs []Pair) [][2]int {
+	ids := make([][2]int, len(pairs), len(pairs))
+	for i, p := range pairs {
+		ids[i] = [2]int{p.A, p.B}
+	}
+	return ids
+}
+
+func Test_Deduplicate(t *testing.T) {
+	config := testConfig()
+	all, err := config.Deduplicate(context.Background(), people)
+	assert.Nil(t, err)
+	assert.Equal(t, [][2]int{{0, 1}, {0, 5}, {1, 5}, {2, 4}}, pairIDs(all))
+	for _, p := range all {
+		assert.True(t, p.Class != NonMatch)
+	}
+
+	config.Blocking = []BlockingKey{ExactKey(2)}
+	blocked, err := config.Deduplicate(context.Background(), people)
+	assert.Nil(t, err)
+	assert.Equal(t, [][2]int{{0, 1}, {2, 4}}, pairIDs(blocked))
+
+	config.Blocking = []BlockingKey{ExactKey(2), TokenKey(0, tokenize.Words)}
+	blocked, err = config.Deduplicate(context.Background(), people)
+	assert.Nil(t, err)
+	assert.Equal(t, pairIDs(all), pairIDs(blocked))
+
+	config.MaxBlockSize = 2
+	blocked, err = config.Deduplicate(context.Background(), people)
+	assert.Nil(t, err)
+	assert.Equal(t, [][2]int{{0, 1}, {2, 4}}, pairIDs(blocked), "The three Smiths form too large a block.")
+}
+
+func Test_Link(t *testing.T) {
+	config := testConfig()
+	config.Blocking = []BlockingKey{PrefixKey(2, 3)}
+	left := people[:3]
+	right := []Record{
+		{"Mary Jones", "4 Mill Ln", "OX1 2JD"},
+		{"JOHN SMITH", "12 high street", "sw1a 1aa"},
+		{"John Smith", "12 High Street", "SW1A 1AA"},
+	}
+	pairs, err := config.Link(context.Background(), left, right)
+	assert.Nil(t, err)
+	assert.Equal(t, [][2]int{{0, 2}, {1, 2}, {2, 0}}, pairIDs(pairs))
+
+	config.Blocking = nil
+	unblocked, err := config.Link(context.Background(), left, right)
+	assert.Nil(t, err)
+	assert.Equal(t, pairIDs(pairs), pairIDs(unblocked))
+}
+
+func Test_BlockingKeys(t *testing.T) {
+	r := Record{"  Jos\u00e9  GARC\u00cdA ", "", "sw1a 1aa"}
+	assert.Equal(t, []string{"jose garcia"}, ExactKey(0).values(r))
+	assert.Equal(t, []string{"jos"}, PrefixKey(0, 3).values(r))
+	assert.Equal(t, []string{"sw1a 1aa"}, PrefixKey(2, 20).values(r))
+	assert.Equal(t, []string{"jose", "garcia"}, TokenKey(0, tokenize.Words).values(r))
+	assert.Equal(t, []string{""}, ExactKey(1).values(r))
+	assert.PanicsWithValue(t, "dedup: a PrefixKey needs a prefix of at least 1 rune, not -1", func() { PrefixKey(0, -1) })
+	assert.Panics(t, func() { PrefixKey(0, 0) })
+}
+
+func Test_Deduplicate_Cancel(t *testing.T) {
+	records := make([]Record, 200, 200)
+	for i := range records {
+		records[i] = people[i%len(people)]
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pairs, err := testConfig().Deduplicate(ctx, records)
+	assert.Nil(t, pairs)
+	assert.Equal(t, context.Canceled, err)
+
+	config := testConfig()
+	config.Workers = 3
+	pairs, err = config.Deduplicate(context.Background(), records)
+	assert.Nil(t, err)
+	config.Workers = 1
+	serial, err := config.Deduplicate(context.Background(), records)
+	assert.Nil(t, err)
+	assert.Equal(t, pairIDs(serial), pairIDs(pairs))
+	for i := range serial {
+		assert.Equal(t, serial[i].Score, pairs[i].Score)
+	}
+}
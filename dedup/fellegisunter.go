@@ -0,0 +1,347 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package dedup
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Missing is the agreement level of a field which either record
+// of a pair is missing. Missing fields do not contribute to the
+// weight or posterior probability of a pair.
+const Missing = -1
+
+// FellegiSunter is a probabilistic model of record linkage, which
+// weighs the evidence of each field's agreement by how much more
+// often that agreement occurs between matching records than
+// between non-matching ones, rather than by hand-tuned weights.
+//
+// The similarity of each field is reduced to an agreement level,
+// so that a comparison of records becomes a vector of levels. The
+// probability of each level of each field among matches, m, and
+// among non-matches, u, can then be estimated from the vectors of
+// unlabelled pairs by expectation-maximization, with Estimate:
+//
+//	fs := &dedup.FellegiSunter{Levels: [][]float64{
+//		{0.92, 0.88}, // Name: Jaro-Winkler >= 0.92, >= 0.88, or else.
+//		{1},          // Postcode: exact, or else.
+//	}}
+//	pairs, err := config.Candidates(ctx, records)
+//	vectors := fs.Vectors(pairs)
+//	_, err = fs.Estimate(vectors, 100, 1e-6)
+//	for i, p := range pairs {
+//		fmt.Println(p.A, p.B, fs.Weight(vectors[i]), fs.Posterior(vectors[i]))
+//	}
+//
+// The model assumes that the agreement of each field is
+// independent of the others, given the match status of the pair.
+//
+// See: http://en.wikipedia.org/wiki/Record_linkage#Probabilistic_record_linkage
+type FellegiSunter struct {
+	// Levels holds the similarity thresholds of the agreement
+	// levels of each field, in decreasing order. A similarity of
+	// at least Levels[f][0] is level 0, the closest agreement; one
+	// of at least Levels[f][1] is level 1; and so on, to level
+	// len(Levels[f]), the disagreement below every threshold.
+	Levels [][]float64
+
+	// M holds, for each field, the probability of each agreement
+	// level among matching pairs, and U among non-matching pairs.
+	// Estimate sets them, starting from their values if they are
+	// already set, or from defaults if not. Once set, each must
+	// hold len(Levels[f])+1 probabilities for each field f.
+	M, U [][]float64
+
+	// Prior is the proportion of the pairs compared which match.
+	// Estimate sets it, starting from its value if positive, or
+	// from 0.1 if not.
+	Prior float64
+}
+
+// minProbability is the smallest probability Estimate assigns to
+// an agreement level, so that no level carries infinite weight
+// merely because it was not seen among the matches or the
+// non-matches.
+const minProbability = 1e-6
+
+// Level returns the agreement level of a similarity for a field,
+// or Missing if the similarity is NaN.
+//
+// Level panics if the field has no entry in Levels.
+func (fs *FellegiSunter) Level(field int, similarity float64) int {
+	if field < 0 || field >= len(fs.Levels) {
+		panic(fmt.Sprintf("dedup: the FellegiSunter model has Levels for %d fields, so none for field %d", len(fs.Levels), field))
+	}
+	if math.IsNaN(similarity) {
+		return Missing
+	}
+	for level, threshold := range fs.Levels[field] {
+		if similarity >= threshold {
+			return level
+		}
+	}
+	return len(fs.Levels[field])
+}
+
+// Vector returns the agreement levels of the fields of a
+// comparison.
+//
+// Vector panics, as Level does, if the comparison has more fields
+// than the model has Levels.
+func (fs *FellegiSunter) Vector(c Comparison) []int {
+	vector := make([]int, len(c.Fields), len(c.Fields))
+	for f, s := range c.Fields {
+		vector[f] = fs.Level(f, s)
+	}
+	return vector
+}
+
+// Vectors returns the agreement levels of the fields of each
+// pair.
+func (fs *FellegiSunter) Vectors(pairs []Pair) [][]int {
+	vectors := make([][]int, len(pairs), len(pairs))
+	for i, p := range pairs {
+		vectors[i] = fs.Vector(p.Comparison)
+	}
+	return vectors
+}
+
+// LevelWeight returns the weight of evidence for a match of an
+// agreement level of a field, log2(m/u): positive for levels more
+// common among matches, and negative for those more common among
+// non-matches.
+//
+// LevelWeight panics if M and U hold no probabilities for the
+// level, as before they are set or estimated.
+func (fs *FellegiSunter) LevelWeight(field, level int) float64 {
+	if field >= len(fs.M) || field >= len(fs.U) || level >= len(fs.M[field]) || level >= len(fs.U[field]) {
+		panic(fmt.Sprintf("dedup: the FellegiSunter model has no probabilities for level %d of field %d; set M and U, or call Estimate, first", level, field))
+	}
+	return math.Log2(fs.M[field][level] / fs.U[field][level])
+}
+
+// Weight returns the match weight of a vector of agreement
+// levels: the sum of the weights of its levels, ignoring missing
+// fields. The higher the weight, the more likely a match.
+//
+// Weight panics, as LevelWeight does, if M and U are not set.
+func (fs *FellegiSunter) Weight(vector []int) float64 {
+	weight := 0.0
+	for f, level := range vector {
+		if level != Missing {
+			weight += fs.LevelWeight(f, level)
+		}
+	}
+	return weight
+}
+
+// Posterior returns the probability that a pair with the given
+// vector of agreement levels is a match.
+//
+// Posterior panics, as LevelWeight does, if M and U are not set.
+func (fs *FellegiSunter) Posterior(vector []int) float64 {
+	// Working with the log-odds avoids underflow for long vectors.
+	logOdds := math.Log(fs.Prior) - math.Log(1-fs.Prior) + fs.Weight(vector)*math.Ln2
+	return 1 / (1 + math.Exp(-logOdds))
+}
+
+// Estimate fits the probabilities M and U, and the Prior, to a
+// set of agreement vectors by expectation-maximization, stopping
+// once no parameter changes by more than tolerance in an
+// iteration, or after maxIterations. It returns the number of
+// iterations performed.
+//
+// EM finds two classes of pairs without knowing which is which;
+// the class whose closest agreement levels are the more common is
+// taken to be the matches.
+func (fs *FellegiSunter) Estimate(vectors [][]int, maxIterations int, tolerance float64) (int, error) {
+	if err := fs.checkVectors(vectors); err != nil {
+		return 0, err
+	}
+	patterns, counts := distinctVectors(vectors)
+	fs.initialize()
+
+	posteriors := make([]float64, len(patterns), len(patterns))
+	iteration := 0
+	for iteration < maxIterations {
+		iteration++
+
+		// Expectation: the probability that each pattern matches.
+		for p, vector := range patterns {
+			posteriors[p] = fs.Posterior(vector)
+		}
+
+		// Maximization: the parameters which best explain those
+		// probabilities.
+		var matches, total float64
+		m := fs.zeroLevels()
+		u := fs.zeroLevels()
+		for p, vector := range patterns {
+			g, n := posteriors[p], counts[p]
+			matches += g * n
+			total += n
+			for f, level := range vector {
+				if level != Missing {
+					m[f][level] += g * n
+					u[f][level] += (1 - g) * n
+				}
+			}
+		}
+		normalize(m)
+		normalize(u)
+		prior := math.Min(math.Max(matches/total, minProbability), 1-minProbability)
+
+		change := math.Abs(prior - fs.Prior)
+		for f := range m {
+			for level := range m[f] {
+				change = math.Max(change, math.Abs(m[f][level]-fs.M[f][level]))
+				change = math.Max(change, math.Abs(u[f][level]-fs.U[f][level]))
+			}
+		}
+		fs.M, fs.U, fs.Prior = m, u, prior
+		if change <= tolerance {
+			break
+		}
+	}
+
+	// The closest agreement is more common among matches.
+	evidence := 0.0
+	for f := range fs.M {
+		evidence += fs.M[f][0] - fs.U[f][0]
+	}
+	if evidence < 0 {
+		fs.M, fs.U, fs.Prior = fs.U, fs.M, 1-fs.Prior
+	}
+	return iteration, nil
+}
+
+// checkVectors checks that the thresholds of each field are in
+// decreasing order, that M and U, if set, hold a probability for
+// each agreement level, and that each vector holds a valid
+// agreement level for each field.
+func (fs *FellegiSunter) checkVectors(vectors [][]int) error {
+	if len(fs.Levels) == 0 {
+		return errors.New("A FellegiSunter model must have Levels for at least one field.")
+	}
+	for f, thresholds := range fs.Levels {
+		for level, threshold := range thresholds {
+			if math.IsNaN(threshold) || level > 0 && threshold >= thresholds[level-1] {
+				return fmt.Errorf("The Levels of field %d, %v, are not in decreasing order.", f, thresholds)
+			}
+		}
+	}
+	if len(fs.M) > 0 || len(fs.U) > 0 {
+		if len(fs.M) != len(fs.Levels) || len(fs.U) != len(fs.Levels) {
+			return fmt.Errorf("M and U hold probabilities for %d and %d fields, but the model has %d.", len(fs.M), len(fs.U), len(fs.Levels))
+		}
+		for f, thresholds := range fs.Levels {
+			if n := len(thresholds) + 1; len(fs.M[f]) != n || len(fs.U[f]) != n {
+				return fmt.Errorf("M and U hold %d and %d probabilities for field %d, which has %d agreement levels.", len(fs.M[f]), len(fs.U[f]), f, n)
+			}
+		}
+	}
+	if len(vectors) == 0 {
+		return errors.New("At least one agreement vector is needed to estimate a FellegiSunter model.")
+	}
+	for i, vector := range vectors {
+		if len(vector) != len(fs.Levels) {
+			return fmt.Errorf("Vector %d has %d fields, but the model has %d.", i, len(vector), len(fs.Levels))
+		}
+		for f, level := range vector {
+			if level < Missing || level > len(fs.Levels[f]) {
+				return fmt.Errorf("Vector %d has level %d for field %d, which has levels 0 to %d.", i, level, f, len(fs.Levels[f]))
+			}
+		}
+	}
+	return nil
+}
+
+// initialize sets any parameters not yet set to their defaults:
+// for matches, probabilities halving from the closest agreement
+// level to the disagreement, and for non-matches, the reverse.
+func (fs *FellegiSunter) initialize() {
+	if len(fs.M) == 0 && len(fs.U) == 0 {
+		fs.M = fs.zeroLevels()
+		fs.U = fs.zeroLevels()
+		for f := range fs.M {
+			n := len(fs.M[f])
+			for level := range fs.M[f] {
+				fs.M[f][level] = math.Ldexp(1, n-level)
+				fs.U[f][level] = math.Ldexp(1, level+1)
+			}
+		}
+		normalize(fs.M)
+		normalize(fs.U)
+	}
+	if fs.Prior <= 0 || fs.Prior >= 1 {
+		fs.Prior = 0.1
+	}
+}
+
+// zeroLevels returns a zero value for each agreement level of
+// each field.
+func (fs *FellegiSunter) zeroLevels() [][]float64 {
+	levels := make([][]float64, len(fs.Levels), len(fs.Levels))
+	for f := range levels {
+		n := len(fs.Levels[f]) + 1
+		levels[f] = make([]float64, n, n)
+	}
+	return levels
+}
+
+// normalize scales the values of each field to probabilities
+// summing to 1, none smaller than minProbability.
+func normalize(levels [][]float64) {
+	for _, values := range levels {
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		for i := range values {
+			if sum > 0 {
+				values[i] /= sum
+			} else {
+				values[i] = 1 / float64(len(values))
+			}
+			values[i] = math.Max(values[i], minProbability)
+		}
+		sum = 0.0
+		for _, v := range values {
+			sum += v
+		}
+		for i := range values {
+			values[i] /= sum
+		}
+	}
+}
+
+// distinctVectors returns each distinct vector, along with the
+// number of times it occurs, in order of first occurrence. Pairs
+// of records typically produce few distinct vectors, so
+// estimating from them is much faster than from every pair.
+func distinctVectors(vectors [][]int) ([][]int, []float64) {
+	index := make(map[string]int)
+	var patterns [][]int
+	var counts []float64
+	key := make([]byte, 0, 2*len(vectors[0]))
+	for _, vector := range vectors {
+		key = key[:0]
+		for _, level := range vector {
+			key = append(key, byte(level+1), byte((level+1)>>8))
+		}
+		if p, ok := index[string(key)]; ok {
+			counts[p]++
+			continue
+		}
+		index[string(key)] = len(patterns)
+		patterns = append(patterns, vector)
+		counts = append(counts, 1)
+	}
+	return patterns, counts
+}
@@ -0,0 +1,153 @@
+﻿package dedup
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// sampleLevel draws an agreement level with the given
+// probabilities.
+func sampleLevel(r *rand.Rand, probabilities []float64) int {
+	x := r.Float64()
+	for level, p := range probabilities {
+		if x < p {
+			return level
+		}
+		x -= p
+	}
+	return len(probabilities) - 1
+}
+
+func Test_FellegiSunter_Estimate(t *testing.T) {
+	m := [][]float64{{0.85, 0.1, 0.05}, {0.9, 0.1}, {0.8, 0.2}}
+	u := [][]float64{{0.02, 0.08, 0.9}, {0.1, 0.9}, {0.3, 0.7}}
+	prior := 0.2
+
+	r := rand.New(rand.NewSource(1))
+	vectors := make([][]int, 20000, 20000)
+	for i := range vectors {
+		probabilities := u
+		if r.Float64() < prior {
+			probabilities = m
+		}
+		vector := make([]int, len(probabilities), len(probabilities))
+		for f := range vector {
+			vector[f] = sampleLevel(r, probabilities[f])
+		}
+		if i%10 == 0 {
+			vector[2] = Missing
+		}
+		vectors[i] = vector
+	}
+
+	fs := &FellegiSunter{Levels: [][]float64{{0.92, 0.88}, {1}, {0.5}}}
+	iterations, err := fs.Estimate(vectors, 500, 1e-9)
+	assert.Nil(t, err)
+	assert.True(t, iterations > 1 && iterations < 500, "EM converges, after %d iterations.", iterations)
+	assert.InDelta(t, prior, fs.Prior, 0.02)
+	for f := range m {
+		for level := range m[f] {
+			assert.InDelta(t, m[f][level], fs.M[f][level], 0.03, "m of field %d, level %d", f, level)
+			assert.InDelta(t, u[f][level], fs.U[f][level], 0.03, "u of field %d, level %d", f, level)
+		}
+	}
+
+	// Starting from the wrong labelling, the classes are swapped
+	// back.
+	swapped := &FellegiSunter{Levels: fs.Levels, M: u, U: m, Prior: 0.8}
+	_, err = swapped.Estimate(vectors, 500, 1e-9)
+	assert.Nil(t, err)
+	assert.InDelta(t, fs.Prior, swapped.Prior, 0.001)
+	assert.InDelta(t, fs.M[0][0], swapped.M[0][0], 0.001)
+
+	best, worst := []int{0, 0, 0}, []int{2, 1, 1}
+	assert.True(t, fs.Weight(best) > 0)
+	assert.True(t, fs.Weight(worst) < 0)
+	assert.True(t, fs.Posterior(best) > 0.99)
+	assert.True(t, fs.Posterior(worst) < 0.01)
+	assert.Equal(t, fs.Weight([]int{0, 0, Missing}), fs.LevelWeight(0, 0)+fs.LevelWeight(1, 0))
+	assert.InDelta(t, math.Log2(fs.M[1][1]/fs.U[1][1]), fs.LevelWeight(1, 1), 1e-12)
+}
+
+func Test_FellegiSunter_Levels(t *testing.T) {
+	fs := &FellegiSunter{Levels: [][]float64{{0.92, 0.88}, {1}}}
+	assert.Equal(t, 0, fs.Level(0, 0.95))
+	assert.Equal(t, 0, fs.Level(0, 0.92))
+	assert.Equal(t, 1, fs.Level(0, 0.9))
+	assert.Equal(t, 2, fs.Level(0, 0.5))
+	assert.Equal(t, 1, fs.Level(1, 0.99))
+	assert.Equal(t, Missing, fs.Level(1, math.NaN()))
+	assert.Equal(t, []int{1, Missing}, fs.Vector(Comparison{Fields: []float64{0.9, math.NaN()}}))
+	assert.PanicsWithValue(t, "dedup: the FellegiSunter model has Levels for 2 fields, so none for field 2", func() {
+		fs.Vector(Comparison{Fields: []float64{0.9, 1, 1}})
+	})
+}
+
+func Test_FellegiSunter_Errors(t *testing.T) {
+	fs := &FellegiSunter{Levels: [][]float64{{0.9}, {1}}}
+	_, err := fs.Estimate(nil, 10, 0)
+	assert.NotNil(t, err)
+	_, err = fs.Estimate([][]int{{0, 0, 0}}, 10, 0)
+	assert.NotNil(t, err)
+	_, err = fs.Estimate([][]int{{0, 2}}, 10, 0)
+	assert.NotNil(t, err)
+	_, err = fs.Estimate([][]int{{0, -2}}, 10, 0)
+	assert.NotNil(t, err)
+	_, err = (&FellegiSunter{}).Estimate([][]int{{}}, 10, 0)
+	assert.NotNil(t, err)
+
+	for _, levels := range [][]float64{{0.8, 0.9}, {0.9, 0.9}, {math.NaN()}} {
+		unordered := &FellegiSunter{Levels: [][]float64{{1}, levels}}
+		_, err = unordered.Estimate([][]int{{0, 0}}, 10, 0)
+		assert.NotNil(t, err, "%v", levels)
+	}
+
+	for _, m := range [][][]float64{{{1}, {0.5, 0.5}}, {{0.5, 0.5}}, {{0.5, 0.5}, {0.5, 0.5}, {1}}} {
+		preset := &FellegiSunter{Levels: fs.Levels, M: m, U: [][]float64{{0.5, 0.5}, {0.5, 0.5}}}
+		_, err = preset.Estimate([][]int{{0, 0}}, 10, 0)
+		assert.NotNil(t, err, "%v", m)
+	}
+	_, err = (&FellegiSunter{Levels: fs.Levels, M: [][]float64{{0.5, 0.5}, {0.5, 0.5}}}).Estimate([][]int{{0, 0}}, 10, 0)
+	assert.NotNil(t, err, "M without U.")
+
+	assert.PanicsWithValue(t, "dedup: the FellegiSunter model has no probabilities for level 0 of field 0; set M and U, or call Estimate, first", func() {
+		fs.Weight([]int{0, 1})
+	})
+	assert.Panics(t, func() { fs.Posterior([]int{0, 1}) })
+	assert.Equal(t, 0.0, fs.Weight([]int{Missing, Missing}), "Missing fields need no probabilities.")
+}
+
+func Test_FellegiSunter_Candidates(t *testing.T) {
+	config := testConfig()
+	pairs, err := config.Candidates(context.Background(), people)
+	assert.Nil(t, err)
+	assert.Equal(t, len(people)*(len(people)-1)/2, len(pairs))
+
+	matches, err := config.Deduplicate(context.Background(), people)
+	assert.Nil(t, err)
+	assert.True(t, len(matches) < len(pairs))
+
+	fs := &FellegiSunter{Levels: [][]float64{{0.92, 0.88}, {0.8}, {1}}}
+	vectors := fs.Vectors(pairs)
+	_, err = fs.Estimate(vectors, 100, 1e-6)
+	assert.Nil(t, err)
+	for i, p := range pairs {
+		// John Smith and Jon Smith, at the same address, are
+		// more likely a match than John Smith and Jane Doe.
+		if p.A == 0 && p.B == 1 {
+			for j, q := range pairs {
+				if q.A == 0 && q.B == 3 {
+					assert.True(t, fs.Posterior(vectors[i]) > fs.Posterior(vectors[j]))
+					assert.True(t, fs.Weight(vectors[i]) > fs.Weight(vectors[j]))
+				}
+			}
+		}
+	}
+
+	linked, err := config.LinkCandidates(context.Background(), people[:2], people[2:])
+	assert.Nil(t, err)
+	assert.Equal(t, 2*(len(people)-2), len(linked))
+}
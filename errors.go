@@ -0,0 +1,103 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+/*
+Package stralgo defines the errors returned by the string
+algorithms of its subpackages, such as bytewise, runewise,
+graphemewise and sequence, so that callers may tell them apart
+with errors.Is and errors.As rather than by their text:
+
+	d, err := runewise.HammingDistance(a, b)
+	var mismatch *stralgo.LengthMismatchError
+	if errors.As(err, &mismatch) {
+		log.Printf("lengths differ: %d and %d", mismatch.ALength, mismatch.BLength)
+	}
+
+The policy for which functions can fail is as follows. A metric
+returns an error only when it is undefined for its inputs, and
+then returns a zero score along with the error:
+
+  - HammingDistance fails with a *LengthMismatchError, which
+    matches ErrUnequalLength, when its inputs differ in length.
+  - DiceCoefficient and WhiteSimilarity fail with
+    ErrInsufficientBigrams when neither input contains a bigram
+    they can use, and their Tokenized variants fail with
+    ErrNoTokens when neither input produces a token.
+  - LevenshteinDistance and DamerauLevenshteinDistance are defined
+    for all inputs, and never fail; their error result is always
+    nil. JaroSimilarity and JaroWinklerSimilarity are likewise
+    defined for all inputs, and have no error result.
+  - Functions which decode strings, such as the methods of
+    runewise.StringMetrics, may also fail with ErrInvalidUTF8 if
+    asked to reject invalid input.
+  - The approximate substring searches fail with ErrEmptyPattern,
+    ErrNegativeDistance or ErrPatternTooLong when given a pattern
+    or distance they cannot search with, and NewAhoCorasick fails
+    with ErrEmptyPattern.
+
+Future metrics follow the same policy, reusing these errors where
+they apply.
+*/
+package stralgo
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnequalLength is matched by the errors of metrics which
+	// are only defined between inputs of equal length. The errors
+	// returned are *LengthMismatchError values, which carry the
+	// lengths.
+	ErrUnequalLength = errors.New("The inputs are of unequal length.")
+
+	// ErrInsufficientBigrams is returned by the bigram-based
+	// similarity metrics when neither input contains a bigram
+	// the metric can use.
+	ErrInsufficientBigrams = errors.New("At least one of the inputs must contain a usable bigram for a bigram-based similarity to be calculated.")
+
+	// ErrNoTokens is returned by the token-based similarity
+	// metrics when neither input produces a token.
+	ErrNoTokens = errors.New("At least one of the inputs must produce a token for a token-based similarity to be calculated.")
+
+	// ErrInvalidUTF8 is returned when an input string which must
+	// be valid UTF-8 is not.
+	ErrInvalidUTF8 = errors.New("The input string is not valid UTF-8.")
+
+	// ErrEmptyPattern is returned by searches given an empty
+	// pattern.
+	ErrEmptyPattern = errors.New("The pattern must contain at least one element for a search to be performed.")
+
+	// ErrNegativeDistance is returned by approximate searches
+	// given a negative maximum distance.
+	ErrNegativeDistance = errors.New("The maximum distance for an approximate substring search must not be negative.")
+
+	// ErrPatternTooLong is returned by BitapSearch given a pattern
+	// longer than BitapMaxPatternLength; use SellersSearch instead.
+	ErrPatternTooLong = errors.New("The pattern is too long for BitapSearch; use SellersSearch for longer patterns.")
+)
+
+// LengthMismatchError reports that a metric which is only
+// defined between inputs of equal length, such as the Hamming
+// distance, was given inputs of unequal length. It matches
+// ErrUnequalLength.
+//
+// The lengths are counted in the units the metric compares:
+// bytes, runes, grapheme clusters or sequence elements.
+type LengthMismatchError struct {
+	Metric  string // The name of the metric, such as "Hamming distance".
+	ALength int    // The length of the first input.
+	BLength int    // The length of the second input.
+}
+
+func (e *LengthMismatchError) Error() string {
+	return fmt.Sprintf("%s is undefined between inputs of unequal length, %d and %d.", e.Metric, e.ALength, e.BLength)
+}
+
+// Is reports whether target is ErrUnequalLength.
+func (e *LengthMismatchError) Is(target error) bool {
+	return target == ErrUnequalLength
+}
@@ -0,0 +1,84 @@
+﻿package stralgo_test
+
+import (
+	"errors"
+	"github.com/ZackPierce/stralgo"
+	"github.com/ZackPierce/stralgo/bytewise"
+	"github.com/ZackPierce/stralgo/graphemewise"
+	"github.com/ZackPierce/stralgo/runewise"
+	"github.com/ZackPierce/stralgo/sequence"
+	"github.com/ZackPierce/stralgo/tokenize"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func Test_LengthMismatchError(t *testing.T) {
+	_, bErr := bytewise.HammingDistance("caf\u00e9", "cafe")
+	_, rErr := runewise.HammingDistance([]rune("abc"), []rune("ab"))
+	_, gErr := graphemewise.HammingDistance(graphemewise.Split("ab"), graphemewise.Split("abc"))
+	_, sErr := sequence.HammingDistance([]int64{1}, []int64{})
+	_, fErr := sequence.HammingDistanceFunc([]string{}, []string{"a"}, strings.EqualFold)
+	lengths := [][2]int{{5, 4}, {3, 2}, {2, 3}, {1, 0}, {0, 1}}
+	for i, err := range []error{bErr, rErr, gErr, sErr, fErr} {
+		assert.True(t, errors.Is(err, stralgo.ErrUnequalLength), "%v", err)
+		var mismatch *stralgo.LengthMismatchError
+		if assert.True(t, errors.As(err, &mismatch), "%v", err) {
+			assert.Equal(t, "Hamming distance", mismatch.Metric)
+			assert.Equal(t, lengths[i][0], mismatch.ALength)
+			assert.Equal(t, lengths[i][1], mismatch.BLength)
+		}
+	}
+	assert.Equal(t, "Hamming distance is undefined between inputs of unequal length, 3 and 2.", rErr.Error())
+	assert.False(t, errors.Is(rErr, stralgo.ErrNoTokens))
+}
+
+func Test_InsufficientBigrams(t *testing.T) {
+	var w runewise.Workspace
+	errs := make([]error, 0)
+	for _, f := range []func() (float64, error){
+		func() (float64, error) { return bytewise.DiceCoefficient("a", "") },
+		func() (float64, error) { return bytewise.WhiteSimilarity("a b", "c") },
+		func() (float64, error) { return runewise.DiceCoefficient([]rune("a"), nil) },
+		func() (float64, error) { return runewise.WhiteSimilarity([]rune("a b"), []rune(" ")) },
+		func() (float64, error) { return runewise.Strings.WhiteSimilarity("a", "b") },
+		func() (float64, error) { return w.DiceCoefficient([]rune("a"), []rune("b")) },
+		func() (float64, error) { return w.WhiteSimilarity([]rune("a b"), []rune("c")) },
+		func() (float64, error) { return graphemewise.DiceCoefficient(graphemewise.Split("a"), nil) },
+		func() (float64, error) { return graphemewise.WhiteSimilarity(graphemewise.Split("a b"), nil) },
+	} {
+		s, err := f()
+		assert.Equal(t, 0.0, s)
+		errs = append(errs, err)
+	}
+	for _, err := range errs {
+		assert.True(t, errors.Is(err, stralgo.ErrInsufficientBigrams), "%v", err)
+	}
+}
+
+func Test_NoTokens(t *testing.T) {
+	_, bErr := bytewise.DiceCoefficientTokenized(" ", "", tokenize.Words)
+	_, rErr := runewise.WhiteSimilarityTokenized([]rune("!"), []rune("?"), tokenize.Words)
+	_, gErr := graphemewise.DiceCoefficientTokenized(nil, nil, tokenize.Words)
+	for _, err := range []error{bErr, rErr, gErr} {
+		assert.True(t, errors.Is(err, stralgo.ErrNoTokens), "%v", err)
+	}
+}
+
+func Test_SearchErrors(t *testing.T) {
+	_, err := bytewise.SellersSearch("", "text", 1)
+	assert.True(t, errors.Is(err, stralgo.ErrEmptyPattern))
+	_, err = runewise.BitapSearch([]rune("pattern"), []rune("text"), -1)
+	assert.True(t, errors.Is(err, stralgo.ErrNegativeDistance))
+	_, err = graphemewise.BitapSearch(graphemewise.Split(strings.Repeat("x", 65)), nil, 1)
+	assert.True(t, errors.Is(err, stralgo.ErrPatternTooLong))
+	_, err = bytewise.NewAhoCorasick([]string{"a", ""})
+	assert.True(t, errors.Is(err, stralgo.ErrEmptyPattern))
+	_, err = runewise.NewAhoCorasick([][]rune{nil}, false)
+	assert.True(t, errors.Is(err, stralgo.ErrEmptyPattern))
+}
+
+func Test_InvalidUTF8(t *testing.T) {
+	_, err := runewise.StringMetrics{Invalid: runewise.RejectInvalidUTF8}.LevenshteinDistance("\xff", "")
+	assert.True(t, errors.Is(err, stralgo.ErrInvalidUTF8))
+}
@@ -0,0 +1,102 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package graphemewise
+
+import (
+	"github.com/ZackPierce/stralgo/runewise"
+	"strings"
+)
+
+// PatternMatch describes an exact occurrence of one of
+// a set of patterns within a text.
+//
+// Pattern is the index of the matched pattern in the slice
+// the matcher was built from. Start and End are cluster
+// indices into the searched text, such that text[Start:End]
+// is the matched pattern (or its case-insensitive equivalent).
+type PatternMatch struct {
+	Pattern int
+	Start   int
+	End     int
+}
+
+// AhoCorasick is a compiled multi-pattern matcher over
+// grapheme clusters, built with NewAhoCorasick, which finds
+// exact occurrences of any number of patterns in a single
+// pass over a text (see runewise.AhoCorasick).
+//
+// An AhoCorasick is immutable once built and may be shared
+// between goroutines.
+//
+// See: http://en.wikipedia.org/wiki/Aho-Corasick_string_matching_algorithm
+type AhoCorasick struct {
+	in              interner
+	ac              *runewise.AhoCorasick
+	caseInsensitive bool
+}
+
+// NewAhoCorasick builds an AhoCorasick automaton over the
+// given patterns, using cluster-level transitions.
+//
+// If caseInsensitive is true, each cluster of both the
+// patterns and the searched texts is upper-cased, as by
+// WhiteSimilarity, so that "Ünited" matches "üNITED".
+//
+// Returns stralgo.ErrEmptyPattern if any of the patterns is empty.
+func NewAhoCorasick(patterns [][]string, caseInsensitive bool) (*AhoCorasick, error) {
+	in := make(interner)
+	runes := make([][]rune, len(patterns), len(patterns))
+	for p, pattern := range patterns {
+		runes[p] = in.runes(fold(pattern, caseInsensitive))
+	}
+	ac, err := runewise.NewAhoCorasick(runes, false)
+	if err != nil {
+		return nil, err
+	}
+	return &AhoCorasick{in: in, ac: ac, caseInsensitive: caseInsensitive}, nil
+}
+
+// FindAll returns every occurrence of every pattern within
+// text, including occurrences which overlap one another.
+//
+// Matches are ordered by ascending End, and matches sharing
+// an End are ordered from longest to shortest.
+func (ac *AhoCorasick) FindAll(text []string) []PatternMatch {
+	return convertPatternMatches(ac.ac.FindAll(ac.in.lookup(fold(text, ac.caseInsensitive))))
+}
+
+// FindLeftmostLongest returns the non-overlapping occurrences
+// of the patterns within text, scanning from left to right
+// and preferring, at each position, the match which starts
+// earliest and then the longest match starting there.
+//
+// When several identical patterns match, the one with the
+// lowest index is reported.
+func (ac *AhoCorasick) FindLeftmostLongest(text []string) []PatternMatch {
+	return convertPatternMatches(ac.ac.FindLeftmostLongest(ac.in.lookup(fold(text, ac.caseInsensitive))))
+}
+
+// fold returns the upper-case form of each cluster if
+// caseInsensitive is true, and the clusters unchanged otherwise.
+func fold(clusters []string, caseInsensitive bool) []string {
+	if !caseInsensitive {
+		return clusters
+	}
+	upper := make([]string, len(clusters), len(clusters))
+	for i, c := range clusters {
+		upper[i] = strings.ToUpper(c)
+	}
+	return upper
+}
+
+func convertPatternMatches(matches []runewise.PatternMatch) []PatternMatch {
+	converted := make([]PatternMatch, len(matches), len(matches))
+	for i, m := range matches {
+		converted[i] = PatternMatch(m)
+	}
+	return converted
+}
@@ -0,0 +1,36 @@
+﻿package graphemewise
+
+import (
+	"github.com/ZackPierce/stralgo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_AhoCorasick_FindAll(t *testing.T) {
+	ac, err := NewAhoCorasick([][]string{Split("he"), Split("she"), Split("his"), Split("hers")}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []PatternMatch{
+		{Pattern: 1, Start: 1, End: 4},
+		{Pattern: 0, Start: 2, End: 4},
+		{Pattern: 3, Start: 2, End: 6},
+	}, ac.FindAll(Split("ushers")))
+
+	ac, err = NewAhoCorasick([][]string{Split("cafe\u0301")}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []PatternMatch{{Pattern: 0, Start: 2, End: 6}}, ac.FindAll(Split("a cafe\u0301")), "Offsets are cluster indices.")
+	assert.Equal(t, 0, len(ac.FindAll(Split("a cafe"))))
+
+	_, err = NewAhoCorasick([][]string{Split("a"), nil}, false)
+	assert.Equal(t, stralgo.ErrEmptyPattern, err)
+}
+
+func Test_AhoCorasick_CaseInsensitive(t *testing.T) {
+	ac, err := NewAhoCorasick([][]string{Split("GmbH"), Split("u\u0308nited")}, true)
+	assert.Nil(t, err)
+	assert.Equal(t, []PatternMatch{
+		{Pattern: 1, Start: 0, End: 6},
+		{Pattern: 0, Start: 15, End: 19},
+	}, ac.FindAll(Split("U\u0308NITED Widgets GMBH")))
+
+	assert.Equal(t, []PatternMatch{{Pattern: 0, Start: 5, End: 9}}, ac.FindLeftmostLongest(Split("acme gmbh")))
+}
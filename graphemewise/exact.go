@@ -0,0 +1,161 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package graphemewise
+
+import (
+	"github.com/ZackPierce/stralgo/runewise"
+)
+
+// Matcher is implemented by the precompiled single-pattern
+// exact matchers KMP, Horspool and TwoWay.
+type Matcher interface {
+	// Index returns the cluster index of the first occurrence
+	// of the pattern within text, or -1 if there is none.
+	// An empty pattern matches at index 0.
+	Index(text []string) int
+	// Len returns the length of the pattern in clusters.
+	Len() int
+	// compiled returns the runewise matcher upon the interned
+	// pattern, along with the interner which maps texts onto
+	// the same runes.
+	compiled() (runewise.Matcher, interner)
+}
+
+// Occurrences iterates over the exact occurrences of a
+// pattern within a text, as found by FindAll.
+//
+// Typical usage:
+//
+//	occ := FindAll(m, text)
+//	for occ.Next() {
+//		fmt.Println(occ.Index())
+//	}
+type Occurrences struct {
+	runes *runewise.Occurrences
+}
+
+// FindAll returns an iterator over every occurrence of the
+// matcher's pattern within text, in ascending cluster index
+// order, as runewise.FindAll does over runes.
+//
+// Occurrences which overlap one another are all reported.
+// An empty pattern occurs at every index from 0 to len(text).
+func FindAll(m Matcher, text []string) *Occurrences {
+	rm, in := m.compiled()
+	return &Occurrences{runes: runewise.FindAll(rm, in.lookup(text))}
+}
+
+// Next advances the iterator to the next occurrence,
+// returning false once no occurrences remain.
+func (o *Occurrences) Next() bool {
+	return o.runes.Next()
+}
+
+// Index returns the cluster index of the current occurrence,
+// or -1 before the first call to Next and once Next has
+// returned false.
+func (o *Occurrences) Index() int {
+	return o.runes.Index()
+}
+
+// Count returns the number of occurrences of the matcher's
+// pattern within text, overlapping occurrences included,
+// consistent with FindAll.
+func Count(m Matcher, text []string) int {
+	rm, in := m.compiled()
+	return runewise.Count(rm, in.lookup(text))
+}
+
+// KMP is a precompiled Knuth-Morris-Pratt matcher over
+// grapheme clusters (see runewise.KMP).
+//
+// See: http://en.wikipedia.org/wiki/Knuth-Morris-Pratt_algorithm
+type KMP struct {
+	in interner
+	m  *runewise.KMP
+}
+
+// NewKMP compiles pattern into a KMP matcher.
+func NewKMP(pattern []string) *KMP {
+	in := make(interner)
+	return &KMP{in: in, m: runewise.NewKMP(in.runes(pattern))}
+}
+
+// Index returns the cluster index of the first occurrence
+// of the pattern within text, or -1 if there is none.
+func (k *KMP) Index(text []string) int {
+	return k.m.Index(k.in.lookup(text))
+}
+
+// Len returns the length of the pattern in clusters.
+func (k *KMP) Len() int {
+	return k.m.Len()
+}
+
+func (k *KMP) compiled() (runewise.Matcher, interner) {
+	return k.m, k.in
+}
+
+// Horspool is a precompiled Boyer-Moore-Horspool matcher over
+// grapheme clusters (see runewise.Horspool).
+//
+// See: http://en.wikipedia.org/wiki/Boyer-Moore-Horspool_algorithm
+type Horspool struct {
+	in interner
+	m  *runewise.Horspool
+}
+
+// NewHorspool compiles pattern into a Horspool matcher.
+func NewHorspool(pattern []string) *Horspool {
+	in := make(interner)
+	return &Horspool{in: in, m: runewise.NewHorspool(in.runes(pattern))}
+}
+
+// Index returns the cluster index of the first occurrence
+// of the pattern within text, or -1 if there is none.
+func (h *Horspool) Index(text []string) int {
+	return h.m.Index(h.in.lookup(text))
+}
+
+// Len returns the length of the pattern in clusters.
+func (h *Horspool) Len() int {
+	return h.m.Len()
+}
+
+func (h *Horspool) compiled() (runewise.Matcher, interner) {
+	return h.m, h.in
+}
+
+// TwoWay is a precompiled Crochemore-Perrin Two-Way matcher over
+// grapheme clusters (see runewise.TwoWay).
+//
+// See: http://en.wikipedia.org/wiki/Two-way_string-matching_algorithm
+type TwoWay struct {
+	in interner
+	m  *runewise.TwoWay
+}
+
+// NewTwoWay compiles pattern into a TwoWay matcher.
+func NewTwoWay(pattern []string) *TwoWay {
+	in := make(interner)
+	return &TwoWay{in: in, m: runewise.NewTwoWay(in.runes(pattern))}
+}
+
+// Index returns the cluster index of the first occurrence
+// of the pattern within text, or -1 if there is none.
+func (tw *TwoWay) Index(text []string) int {
+	return tw.m.Index(tw.in.lookup(text))
+}
+
+// Len returns the length of the pattern in clusters.
+func (tw *TwoWay) Len() int {
+	return tw.m.Len()
+}
+
+func (tw *TwoWay) compiled() (runewise.Matcher, interner) {
+	return tw.m, tw.in
+}
@@ -0,0 +1,49 @@
+﻿package graphemewise
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func matchers(pattern []string) []Matcher {
+	return []Matcher{NewKMP(pattern), NewHorspool(pattern), NewTwoWay(pattern)}
+}
+
+func Test_Matcher_Index(t *testing.T) {
+	for _, m := range matchers(Split("cafe\u0301")) {
+		assert.Equal(t, 4, m.Len())
+		assert.Equal(t, 4, m.Index(Split("the cafe\u0301")), "%T", m)
+		assert.Equal(t, -1, m.Index(Split("the cafe\u0301\u0327")), "%T matches whole clusters", m)
+		assert.Equal(t, -1, m.Index(Split("the caf\u00e9")), "%T does not normalize", m)
+		assert.Equal(t, -1, m.Index(nil), "%T", m)
+	}
+	for _, m := range matchers(nil) {
+		assert.Equal(t, 0, m.Len())
+		assert.Equal(t, 0, m.Index(Split("abc")), "%T", m)
+	}
+}
+
+func Test_FindAll(t *testing.T) {
+	family := "\U0001F468\u200d\U0001F469\u200d\U0001F467"
+	for _, m := range matchers(Split(family + family)) {
+		occ := FindAll(m, Split(family+family+family+"x"+family))
+		assert.Equal(t, -1, occ.Index())
+		found := make([]int, 0)
+		for occ.Next() {
+			found = append(found, occ.Index())
+		}
+		assert.Equal(t, []int{0, 1}, found, "%T reports overlapping occurrences", m)
+		assert.False(t, occ.Next())
+		assert.Equal(t, -1, occ.Index())
+	}
+}
+
+func Test_Count(t *testing.T) {
+	for _, m := range matchers(Split("ana")) {
+		assert.Equal(t, 2, Count(m, Split("banana")), "%T", m)
+		assert.Equal(t, 0, Count(m, nil), "%T", m)
+	}
+	for _, m := range matchers(nil) {
+		assert.Equal(t, 3, Count(m, Split("e\u0301e\u0301")), "%T", m)
+	}
+}
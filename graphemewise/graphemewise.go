@@ -0,0 +1,390 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+/*
+Package stralgo/graphemewise implements various string algorithms
+with an emphasis on similarity metrics, implemented upon extended
+grapheme clusters, the user-perceived characters of a text.
+
+Where runewise treats "é" written as 'e' followed by a combining
+acute accent as two runes, and an emoji ZWJ sequence such as a
+family emoji as many, graphemewise treats each as one character.
+Texts are segmented with Split, following Unicode Standard
+Annex #29, and the resulting clusters are compared for exact
+equality. Note that no normalization is applied, so a precomposed
+"é" and its decomposed form are still distinct clusters, unless
+the texts are first normalized with stralgo/preprocess.
+
+The exact matchers, AhoCorasick and the suffix arrays take texts
+already split into clusters, and report cluster indices. There is
+no counterpart of runewise.Workspace, as every call here interns
+its clusters afresh, which allocates however buffers are reused,
+nor of runewise.StringMetrics, as Split is itself the decoding of
+a string.
+*/
+package graphemewise
+
+import (
+	"github.com/ZackPierce/stralgo"
+	"github.com/ZackPierce/stralgo/internal/tokenset"
+	"github.com/ZackPierce/stralgo/runewise"
+	"github.com/ZackPierce/stralgo/tokenize"
+	"strings"
+	"unicode"
+)
+
+// ApproximateMatch describes an occurrence of a pattern
+// found within a larger text by an approximate substring
+// search.
+//
+// Start and End are cluster indices into the searched text,
+// such that text[Start:End] is the matched substring.
+// Distance is the Levenshtein distance, in clusters, between
+// the matched substring and the pattern.
+type ApproximateMatch struct {
+	Start    int
+	End      int
+	Distance int
+}
+
+// clusterBase is the first rune used to stand in for an
+// interned cluster. It lies in a private use plane, where
+// unicode.ToUpper and unicode.IsSpace leave runes untouched.
+const clusterBase = 0xF0000
+
+// interner assigns each distinct cluster a stand-in rune, so
+// that the runewise algorithms, which only ever compare runes
+// for equality, can be reused upon clusters.
+type interner map[string]rune
+
+func (in interner) runes(clusters []string) []rune {
+	runes := make([]rune, len(clusters), len(clusters))
+	for i, c := range clusters {
+		r, ok := in[c]
+		if !ok {
+			r = clusterBase + rune(len(in))
+			in[c] = r
+		}
+		runes[i] = r
+	}
+	return runes
+}
+
+// lookup is like runes, but leaves the interner unchanged, so that
+// it may be shared between goroutines, mapping each cluster it does
+// not hold to a rune which stands in for none of those it does.
+func (in interner) lookup(clusters []string) []rune {
+	unknown := clusterBase + rune(len(in))
+	runes := make([]rune, len(clusters), len(clusters))
+	for i, c := range clusters {
+		r, ok := in[c]
+		if !ok {
+			r = unknown
+		}
+		runes[i] = r
+	}
+	return runes
+}
+
+func intern(a, b []string) ([]rune, []rune) {
+	in := make(interner)
+	return in.runes(a), in.runes(b)
+}
+
+// HammingDistance calculates the Hamming distance between
+// two strings containing equal numbers of grapheme clusters.
+//
+// The Hamming distance is the total number of clusters
+// that differ at the same index within the two strings.
+//
+// The higher the result, the more different the strings.
+//
+// See: http://en.wikipedia.org/wiki/Hamming_distance
+//
+// Returns a *stralgo.LengthMismatchError, which matches
+// stralgo.ErrUnequalLength, if the string cluster counts are not equal.
+func HammingDistance(a, b []string) (uint, error) {
+	if len(a) != len(b) {
+		return 0, &stralgo.LengthMismatchError{Metric: "Hamming distance", ALength: len(a), BLength: len(b)}
+	}
+	ra, rb := intern(a, b)
+	return runewise.HammingDistance(ra, rb)
+}
+
+// DiceCoefficient calculates the similarity of two
+// strings per the Sorensen-Dice coefficient, over
+// grapheme cluster bigrams.
+//
+// The resulting value is scaled between 0 and 1.0,
+// and a higher value means a higher similarity.
+//
+// See: http://en.wikipedia.org/wiki/Dice_coefficient
+//
+// Returns stralgo.ErrInsufficientBigrams if both of the input strings contain
+// less than two clusters.
+func DiceCoefficient(a, b []string) (float64, error) {
+	if len(a) < 2 && len(b) < 2 {
+		return 0, stralgo.ErrInsufficientBigrams
+	}
+	ra, rb := intern(a, b)
+	return runewise.DiceCoefficient(ra, rb)
+}
+
+// WhiteSimilarity calculates the similarity of two
+// strings through a variation on the Sorensen-Dice
+// Coefficient algorithm, over grapheme cluster bigrams.
+//
+// The resulting value is scaled between 0 and 1.0,
+// and a higher value means a higher similarity.
+//
+// As in runewise, bigrams including whitespace are
+// disregarded, an upper-case filter is applied, and
+// bigram frequency is accounted for. A cluster counts
+// as whitespace when its first rune does.
+//
+// See: http://www.catalysoft.com/articles/strikeamatch.html
+//
+// Returns stralgo.ErrInsufficientBigrams if neither of the
+// input strings contains at least one cluster bigram without whitespace.
+func WhiteSimilarity(a, b []string) (float64, error) {
+	in := make(interner)
+	s, err := runewise.WhiteSimilarity(in.upperRunes(a), in.upperRunes(b))
+	if err != nil {
+		return 0.0, stralgo.ErrInsufficientBigrams
+	}
+	return s, nil
+}
+
+// upperRunes is like runes, but maps whitespace clusters to
+// a space and interns the upper-case form of the others.
+func (in interner) upperRunes(clusters []string) []rune {
+	upper := make([]string, len(clusters), len(clusters))
+	for i, c := range clusters {
+		upper[i] = strings.ToUpper(c)
+	}
+	runes := in.runes(upper)
+	for i, c := range clusters {
+		for _, r := range c {
+			if unicode.IsSpace(r) {
+				runes[i] = ' '
+			}
+			break
+		}
+	}
+	return runes
+}
+
+// DiceCoefficientTokenized calculates the similarity of two
+// strings per the Sorensen-Dice coefficient of the sets of
+// distinct tokens produced from each by tokenizer.
+//
+// Pass NGrams(2) to count cluster bigrams, as DiceCoefficient
+// does, or any other tokenizer to compare words or n-grams.
+//
+// Returns stralgo.ErrNoTokens if neither of the input strings
+// produces any tokens.
+func DiceCoefficientTokenized(a, b []string, tokenizer tokenize.Tokenizer) (float64, error) {
+	c, ok := tokenset.Dice(tokenizer.Tokenize(strings.Join(a, "")), tokenizer.Tokenize(strings.Join(b, "")))
+	if !ok {
+		return 0, stralgo.ErrNoTokens
+	}
+	return c, nil
+}
+
+// WhiteSimilarityTokenized calculates the similarity of two
+// strings through a variation on the Sorensen-Dice
+// Coefficient algorithm, over the tokens produced from
+// each by tokenizer.
+//
+// The strings are upper-cased before being tokenized,
+// and repeated tokens are accounted for. Pass
+// tokenize.Chain(tokenize.Whitespace, NGrams(2)) to count
+// the cluster bigrams within each word.
+//
+// Returns stralgo.ErrNoTokens if neither of the input strings
+// produces any tokens.
+func WhiteSimilarityTokenized(a, b []string, tokenizer tokenize.Tokenizer) (float64, error) {
+	ta := tokenizer.Tokenize(strings.ToUpper(strings.Join(a, "")))
+	tb := tokenizer.Tokenize(strings.ToUpper(strings.Join(b, "")))
+	s, ok := tokenset.MultisetDice(ta, tb)
+	if !ok {
+		return 0.0, stralgo.ErrNoTokens
+	}
+	return s, nil
+}
+
+// NGrams returns a Tokenizer which splits a text into its
+// overlapping substrings of n grapheme clusters. A text of
+// fewer than n clusters has no n-grams.
+//
+// NGrams panics if n is less than 1.
+func NGrams(n int) tokenize.Tokenizer {
+	if n < 1 {
+		panic("The n-gram length must be at least 1.")
+	}
+	return tokenize.TokenizerFunc(func(s string) []string {
+		clusters := Split(s)
+		count := len(clusters) - n + 1
+		if count < 1 {
+			return make([]string, 0)
+		}
+		grams := make([]string, count, count)
+		for i := range grams {
+			grams[i] = strings.Join(clusters[i:i+n], "")
+		}
+		return grams
+	})
+}
+
+// LevenshteinDistance calculates the magnitude of
+// difference between two strings using the
+// Levenshtein Distance metric.
+//
+// This edit distance is the minimum number of single-cluster
+// edits (insertions, deletions, or substitutions) needed
+// to transform one string into the other.
+//
+// The larger the result, the more different the strings.
+//
+// See: http://en.wikipedia.org/wiki/Levenshtein_distance
+func LevenshteinDistance(a, b []string) (int, error) {
+	ra, rb := intern(a, b)
+	return runewise.LevenshteinDistance(ra, rb)
+}
+
+// DamerauLevenshteinDistance calculates the magnitude
+// of difference between two strings using the Damerau-
+// Levenshtein algorithm with adjacent-only transpositions,
+// over grapheme clusters.
+//
+// This edit distance is the minimum number of single-cluster
+// edits (insertions, deletions, substitutions, or
+// transpositions) to transform one string into the other.
+//
+// The larger the result, the more different the strings.
+//
+// See: http://en.wikipedia.org/wiki/Damerau-Levenshtein_distance
+func DamerauLevenshteinDistance(a, b []string) (int, error) {
+	ra, rb := intern(a, b)
+	return runewise.DamerauLevenshteinDistance(ra, rb)
+}
+
+// LevenshteinDistanceWithin calculates the Levenshtein distance
+// between two strings, over grapheme clusters, if it is no
+// greater than maxDistance, stopping early once it is known to be
+// greater.
+//
+// It returns the distance and true if the distance is no greater
+// than maxDistance. Otherwise it returns false, along with
+// maxDistance+1, a lower bound on the distance, or 0 if
+// maxDistance is negative.
+func LevenshteinDistanceWithin(a, b []string, maxDistance int) (int, bool) {
+	ra, rb := intern(a, b)
+	return runewise.LevenshteinDistanceWithin(ra, rb, maxDistance)
+}
+
+// DamerauLevenshteinDistanceWithin calculates the
+// Damerau-Levenshtein distance between two strings, over grapheme
+// clusters, if it is no greater than maxDistance, stopping early
+// once it is known to be greater. Its results are as for
+// LevenshteinDistanceWithin.
+func DamerauLevenshteinDistanceWithin(a, b []string, maxDistance int) (int, bool) {
+	ra, rb := intern(a, b)
+	return runewise.DamerauLevenshteinDistanceWithin(ra, rb, maxDistance)
+}
+
+// JaroSimilarity calculates the similarity between two strings
+// using the original Jaro distance formula, over grapheme clusters.
+//
+// The result is between 0 and 1.0, and the higher the score,
+// the more similar the two strings are. 1.0 is a perfect match.
+//
+// If either input argument is empty or nil, the result will be 0.0,
+// as for runewise.JaroSimilarity.
+//
+// See (the first half of) : http://en.wikipedia.org/wiki/Jaro-Winkler_distance
+func JaroSimilarity(a, b []string) float64 {
+	ra, rb := intern(a, b)
+	return runewise.JaroSimilarity(ra, rb)
+}
+
+// JaroWinklerSimilarity calculates the similarity between
+// two input strings using the Jaro-Winkler distance formula,
+// over grapheme clusters, with Winkler's suggested constants
+// (see runewise.JaroWinklerSimilarity).
+//
+// The result is between 0 and 1.0, and the higher the score,
+// the more similar the two strings are. 1.0 is a perfect match.
+//
+// See : http://en.wikipedia.org/wiki/Jaro-Winkler_distance
+func JaroWinklerSimilarity(a, b []string) float64 {
+	ra, rb := intern(a, b)
+	return runewise.JaroWinklerSimilarity(ra, rb)
+}
+
+// JaroWinklerSimilarityParametric calculates similarity between
+// two input strings using the Jaro-Winkler distance formula,
+// over grapheme clusters, with caller-supplied constants
+// (see runewise.JaroWinklerSimilarityParametric).
+//
+// The product of prefixScale and maxPrefixLength should be between 0.0 and 1.0.
+// Assuming this is true, the result will be between 0 and 1.0.
+//
+// See : http://en.wikipedia.org/wiki/Jaro-Winkler_distance
+func JaroWinklerSimilarityParametric(a, b []string, prefixScale float64, maxPrefixLength int, boostThreshold float64) float64 {
+	ra, rb := intern(a, b)
+	return runewise.JaroWinklerSimilarityParametric(ra, rb, prefixScale, maxPrefixLength, boostThreshold)
+}
+
+// SellersSearch finds the approximate occurrences of pattern
+// within text, over grapheme clusters, using Sellers'
+// semi-global variant of the Levenshtein algorithm.
+//
+// See runewise.SellersSearch for a description of the
+// reported matches.
+//
+// Returns stralgo.ErrEmptyPattern if the pattern is empty, or
+// stralgo.ErrNegativeDistance if maxDistance is negative.
+func SellersSearch(pattern, text []string, maxDistance int) ([]ApproximateMatch, error) {
+	if len(pattern) == 0 {
+		return nil, stralgo.ErrEmptyPattern
+	}
+	rp, rt := intern(pattern, text)
+	matches, err := runewise.SellersSearch(rp, rt, maxDistance)
+	return convertMatches(matches), err
+}
+
+// BitapSearch finds the approximate occurrences of pattern
+// within text, over grapheme clusters, using the Wu-Manber
+// extension of the Bitap algorithm.
+//
+// The results are identical to those of SellersSearch.
+//
+// Returns stralgo.ErrEmptyPattern if the pattern is empty,
+// stralgo.ErrPatternTooLong if it is longer than
+// runewise.BitapMaxPatternLength clusters, or stralgo.ErrNegativeDistance if
+// maxDistance is negative.
+func BitapSearch(pattern, text []string, maxDistance int) ([]ApproximateMatch, error) {
+	if len(pattern) == 0 {
+		return nil, stralgo.ErrEmptyPattern
+	}
+	if len(pattern) > runewise.BitapMaxPatternLength {
+		return nil, stralgo.ErrPatternTooLong
+	}
+	rp, rt := intern(pattern, text)
+	matches, err := runewise.BitapSearch(rp, rt, maxDistance)
+	return convertMatches(matches), err
+}
+
+func convertMatches(matches []runewise.ApproximateMatch) []ApproximateMatch {
+	if matches == nil {
+		return nil
+	}
+	converted := make([]ApproximateMatch, len(matches), len(matches))
+	for i, m := range matches {
+		converted[i] = ApproximateMatch(m)
+	}
+	return converted
+}
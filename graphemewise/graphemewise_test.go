@@ -0,0 +1,139 @@
+﻿package graphemewise
+
+import (
+	"fmt"
+	"github.com/ZackPierce/stralgo/tokenize"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func Test_LevenshteinDistance(t *testing.T) {
+	d, err := LevenshteinDistance(Split("caf\u00e9"), Split("cafe\u0301"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, d, "Precomposed and decomposed forms are distinct, but each is one cluster.")
+
+	d, err = LevenshteinDistance(Split("café"), Split("cafés"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, d)
+
+	d, err = LevenshteinDistance(Split("I ❤ 👨‍👩‍👧"), Split("I ❤ 👨‍👩‍👦"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, d, "A changed emoji ZWJ sequence is a single substitution.")
+
+	d, err = LevenshteinDistance(Split("kitten"), Split("sitting"))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, d)
+
+	d, err = LevenshteinDistance(nil, Split("abc"))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, d)
+}
+
+func Test_DamerauLevenshteinDistance(t *testing.T) {
+	d, err := DamerauLevenshteinDistance(Split("Sjöstedt"), Split("Söjstedt"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, d)
+
+	d, err = DamerauLevenshteinDistance(Split("ab"), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, d)
+}
+
+func Test_HammingDistance(t *testing.T) {
+	d, err := HammingDistance(Split("née"), Split("nee"))
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), d)
+
+	d, err = HammingDistance(Split("ab"), Split("abc"))
+	assert.NotNil(t, err)
+	assert.Equal(t, uint(0), d)
+}
+
+func Test_DiceCoefficient(t *testing.T) {
+	c, err := DiceCoefficient(Split("night"), Split("nacht"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0/4.0, c)
+
+	c, err = DiceCoefficient(Split("é"), Split("é"))
+	assert.NotNil(t, err, "A single cluster has no bigrams, however many runes it contains.")
+	assert.Equal(t, 0.0, c)
+}
+
+func Test_WhiteSimilarity(t *testing.T) {
+	c, err := WhiteSimilarity(Split("Healed"), Split("Sealed"))
+	assert.Nil(t, err)
+	EqualWithin(t, 0.8, c, 0.01)
+
+	c, err = WhiteSimilarity(Split("résumé "), Split("RÉSUMÉ"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, c)
+
+	c, err = WhiteSimilarity(Split("a"), Split(" b"))
+	assert.NotNil(t, err)
+	assert.Equal(t, 0.0, c)
+}
+
+func Test_DiceCoefficientTokenized(t *testing.T) {
+	c, err := DiceCoefficientTokenized(Split("cafe\u0301s"), Split("cafe\u0301"), NGrams(2))
+	assert.Nil(t, err)
+	EqualWithin(t, 0.857, c, 0.01)
+
+	c, err = DiceCoefficientTokenized(Split("a"), nil, NGrams(2))
+	assert.NotNil(t, err)
+	assert.Equal(t, 0.0, c)
+}
+
+func Test_WhiteSimilarityTokenized(t *testing.T) {
+	c, err := WhiteSimilarityTokenized(Split("résumé "), Split("RÉSUMÉ"), tokenize.Chain(tokenize.Whitespace, NGrams(2)))
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, c)
+
+	c, err = WhiteSimilarityTokenized(Split("I ❤ 👨‍👩‍👧"), Split("i ❤ 👨‍👩‍👦"), tokenize.Whitespace)
+	assert.Nil(t, err)
+	EqualWithin(t, 0.666, c, 0.01)
+}
+
+func Test_NGrams(t *testing.T) {
+	assert.Equal(t, []string{"cafe\u0301"}, NGrams(4).Tokenize("cafe\u0301"))
+	assert.Equal(t, []string{"👨‍👩‍👧!"}, NGrams(2).Tokenize("👨‍👩‍👧!"))
+	assert.Equal(t, []string{}, NGrams(3).Tokenize("ab"))
+	assert.Panics(t, func() { NGrams(0) })
+}
+
+func Test_Jaro(t *testing.T) {
+	EqualWithin(t, 0.9444444, JaroSimilarity(Split("martha"), Split("marhta")), 0.0001)
+	EqualWithin(t, 0.9444444+(0.1*3*(1-0.944444)), JaroWinklerSimilarity(Split("martha"), Split("marhta")), 0.0001)
+	EqualWithin(t, 0.9444444+(0.2*3*(1-0.944444)), JaroWinklerSimilarityParametric(Split("martha"), Split("marhta"), 0.2, 4, 0.7), 0.0001)
+	assert.Equal(t, 1.0, JaroSimilarity(Split("é"), Split("é")))
+	assert.Equal(t, 0.0, JaroSimilarity(nil, Split("a")))
+}
+
+func Test_ApproximateSearch(t *testing.T) {
+	expected := []ApproximateMatch{
+		{Start: 2, End: 6, Distance: 0},
+	}
+	m, err := SellersSearch(Split("café"), Split("a café!"), 0)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, m)
+
+	m, err = BitapSearch(Split("café"), Split("a café!"), 0)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, m)
+
+	m, err = SellersSearch(nil, Split("abc"), 0)
+	assert.NotNil(t, err)
+	assert.Nil(t, m)
+
+	m, err = BitapSearch(Split("abc"), Split("abc"), -1)
+	assert.NotNil(t, err)
+	assert.Nil(t, m)
+}
+
+func EqualWithin(t *testing.T, a, b, delta float64, msgAndArgs ...interface{}) bool {
+	if math.Abs(a-b) > delta {
+		return assert.Fail(t, fmt.Sprintf("Not within delta: Abs(%#v - %#v) > %#v", a, b, delta), msgAndArgs...)
+	}
+
+	return true
+}
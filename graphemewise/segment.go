@@ -0,0 +1,119 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package graphemewise
+
+import (
+	"sort"
+)
+
+//go:generate go run gen_tables.go
+
+// gbProperty is a Grapheme_Cluster_Break property value,
+// with Extended_Pictographic folded in as an extra value.
+type gbProperty uint8
+
+const (
+	gbOther gbProperty = iota
+	gbPrepend
+	gbCR
+	gbLF
+	gbControl
+	gbExtend
+	gbRegionalIndicator
+	gbSpacingMark
+	gbL
+	gbV
+	gbT
+	gbLV
+	gbLVT
+	gbZWJ
+	gbExtendedPictographic
+)
+
+type propertyRange struct {
+	lo, hi   rune
+	property gbProperty
+}
+
+func property(r rune) gbProperty {
+	i := sort.Search(len(graphemeBreakProperties), func(i int) bool {
+		return graphemeBreakProperties[i].hi >= r
+	})
+	if i < len(graphemeBreakProperties) && graphemeBreakProperties[i].lo <= r {
+		return graphemeBreakProperties[i].property
+	}
+	return gbOther
+}
+
+// Split segments s into its extended grapheme clusters, the
+// units a reader perceives as single characters, following
+// the rules of Unicode Standard Annex #29 (Unicode 15.0).
+//
+// Concatenating the returned clusters reproduces s exactly.
+// Each invalid UTF-8 byte is treated as a U+FFFD rune.
+//
+// See: http://www.unicode.org/reports/tr29/#Grapheme_Cluster_Boundaries
+func Split(s string) []string {
+	clusters := make([]string, 0, len(s))
+	start := 0
+	prev := gbOther
+	// pictographic is true while the text so far ends with
+	// Extended_Pictographic Extend*, and joined is true when
+	// that sequence is followed by a ZWJ (rule GB11).
+	pictographic := false
+	joined := false
+	// regional counts the Regional_Indicators which immediately
+	// precede the current position (rules GB12 and GB13).
+	regional := 0
+	for i, r := range s {
+		p := property(r)
+		if i > 0 && isBoundary(prev, p, joined, regional) {
+			clusters = append(clusters, s[start:i])
+			start = i
+		}
+		joined = p == gbZWJ && pictographic
+		pictographic = p == gbExtendedPictographic || (p == gbExtend && pictographic)
+		if p == gbRegionalIndicator {
+			regional++
+		} else {
+			regional = 0
+		}
+		prev = p
+	}
+	if start < len(s) {
+		clusters = append(clusters, s[start:])
+	}
+	return clusters
+}
+
+func isBoundary(prev, next gbProperty, joined bool, regional int) bool {
+	switch {
+	case prev == gbCR && next == gbLF: // GB3
+		return false
+	case prev == gbControl || prev == gbCR || prev == gbLF: // GB4
+		return true
+	case next == gbControl || next == gbCR || next == gbLF: // GB5
+		return true
+	case prev == gbL && (next == gbL || next == gbV || next == gbLV || next == gbLVT): // GB6
+		return false
+	case (prev == gbLV || prev == gbV) && (next == gbV || next == gbT): // GB7
+		return false
+	case (prev == gbLVT || prev == gbT) && next == gbT: // GB8
+		return false
+	case next == gbExtend || next == gbZWJ: // GB9
+		return false
+	case next == gbSpacingMark: // GB9a
+		return false
+	case prev == gbPrepend: // GB9b
+		return false
+	case prev == gbZWJ && next == gbExtendedPictographic && joined: // GB11
+		return false
+	case prev == gbRegionalIndicator && next == gbRegionalIndicator && regional%2 == 1: // GB12, GB13
+		return false
+	}
+	return true // GB999
+}
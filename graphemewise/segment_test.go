@@ -0,0 +1,69 @@
+﻿package graphemewise
+
+import (
+	"bufio"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func Test_Split(t *testing.T) {
+	assert.Equal(t, []string{}, Split(""))
+	assert.Equal(t, []string{"c", "a", "f", "é"}, Split("café"))
+	assert.Equal(t, []string{"c", "a", "f", "e\u0301"}, Split("cafe\u0301"), "A combining accent joins its base character.")
+	assert.Equal(t, []string{"\r\n", "x"}, Split("\r\nx"))
+	assert.Equal(t, []string{"👨‍👩‍👧", "!"}, Split("👨‍👩‍👧!"), "An emoji ZWJ sequence is a single cluster.")
+	assert.Equal(t, []string{"🇯🇵", "🇫🇷", "🇺"}, Split("🇯🇵🇫🇷🇺"), "Regional indicators pair up.")
+	assert.Equal(t, []string{"\u1112\u1161\u11ab", "\u1100\u116e\u11a8"}, Split("\u1112\u1161\u11ab\u1100\u116e\u11a8"), "Conjoining jamo form syllables.")
+	assert.Equal(t, []string{"\xff", "a"}, Split("\xffa"))
+}
+
+func Test_Split_UnicodeConformance(t *testing.T) {
+	f, err := os.Open("testdata/GraphemeBreakTest.txt")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	cases := 0
+	for scanner.Scan() {
+		line := scanner.Text()
+		if hash := strings.Index(line, "#"); hash >= 0 {
+			line = line[:hash]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		expected := make([]string, 0)
+		var text, cluster strings.Builder
+		for _, field := range strings.Fields(line) {
+			switch field {
+			case "÷":
+				if cluster.Len() > 0 {
+					expected = append(expected, cluster.String())
+					cluster.Reset()
+				}
+			case "×":
+			default:
+				cp, err := strconv.ParseUint(field, 16, 32)
+				assert.Nil(t, err)
+				cluster.WriteRune(rune(cp))
+				text.WriteRune(rune(cp))
+			}
+		}
+		assert.Equal(t, expected, Split(text.String()), line)
+		cases++
+	}
+	assert.Nil(t, scanner.Err())
+	assert.True(t, cases > 500)
+}
+
+func Benchmark_Split(b *testing.B) {
+	text := strings.Repeat("Café 👨‍👩‍👧 🇯🇵 한국 ", 20)
+	for i := 0; i < b.N; i++ {
+		Split(text)
+	}
+}
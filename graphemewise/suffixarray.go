@@ -0,0 +1,128 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package graphemewise
+
+import (
+	"github.com/ZackPierce/stralgo/runewise"
+	"sort"
+)
+
+// SuffixArray returns the suffix array of clusters: the cluster
+// indices of every non-empty suffix, sorted in lexicographic
+// order of their clusters, each compared as a string.
+//
+// See runewise.SuffixArray.
+//
+// See: http://en.wikipedia.org/wiki/Suffix_array
+func SuffixArray(clusters []string) []int {
+	return runewise.SuffixArray(sortedInterner(clusters).runes(clusters))
+}
+
+// LCPArray returns the longest common prefix array for clusters
+// and its suffix array sa, as returned by SuffixArray.
+//
+// Element i holds the length, in clusters, of the longest common
+// prefix of the suffixes starting at sa[i-1] and sa[i].
+// Element 0 is always 0.
+//
+// See: http://en.wikipedia.org/wiki/LCP_array
+func LCPArray(clusters []string, sa []int) []int {
+	return runewise.LCPArray(sortedInterner(clusters).runes(clusters), sa)
+}
+
+// sortedInterner returns an interner holding the distinct
+// clusters, whose stand-in runes are in the order of the
+// clusters themselves, so that sorting the runes sorts the
+// clusters.
+func sortedInterner(clusters []string) interner {
+	distinct := make([]string, 0)
+	in := make(interner)
+	for _, c := range clusters {
+		if _, ok := in[c]; !ok {
+			in[c] = 0
+			distinct = append(distinct, c)
+		}
+	}
+	sort.Strings(distinct)
+	for i, c := range distinct {
+		in[c] = clusterBase + rune(i)
+	}
+	return in
+}
+
+// SuffixIndex pairs a text of grapheme clusters with its suffix
+// and LCP arrays in order to answer substring queries without
+// rescanning the text (see runewise.SuffixIndex).
+//
+// A SuffixIndex is immutable once built and may be shared
+// between goroutines, provided the indexed slice is not
+// modified.
+type SuffixIndex struct {
+	text []string
+	in   interner
+	x    *runewise.SuffixIndex
+}
+
+// NewSuffixIndex builds a SuffixIndex over text, clusterwise.
+func NewSuffixIndex(text []string) *SuffixIndex {
+	in := sortedInterner(text)
+	return &SuffixIndex{text: text, in: in, x: runewise.NewSuffixIndex(in.runes(text))}
+}
+
+// SuffixArray returns the suffix array of the indexed text.
+// The returned slice must not be modified.
+func (x *SuffixIndex) SuffixArray() []int {
+	return x.x.SuffixArray()
+}
+
+// LCPArray returns the LCP array of the indexed text.
+// The returned slice must not be modified.
+func (x *SuffixIndex) LCPArray() []int {
+	return x.x.LCPArray()
+}
+
+// Count returns the number of (possibly overlapping)
+// occurrences of pattern within the indexed text.
+//
+// An empty pattern occurs at every index from 0 to len(text).
+func (x *SuffixIndex) Count(pattern []string) int {
+	return x.x.Count(x.in.lookup(pattern))
+}
+
+// Locate returns the cluster indices of every (possibly
+// overlapping) occurrence of pattern within the indexed
+// text, in ascending order.
+//
+// An empty pattern occurs at every index from 0 to len(text).
+func (x *SuffixIndex) Locate(pattern []string) []int {
+	return x.x.Locate(x.in.lookup(pattern))
+}
+
+// LongestRepeatedSubstring returns the longest substring which
+// occurs at least twice within the indexed text, where the
+// occurrences may overlap. If several candidates share the
+// greatest length, the lexicographically smallest is returned.
+//
+// Returns an empty slice if no cluster is repeated.
+func (x *SuffixIndex) LongestRepeatedSubstring() []string {
+	sa, lcp := x.x.SuffixArray(), x.x.LCPArray()
+	best := 0
+	at := 0
+	for i, l := range lcp {
+		if l > best {
+			best = l
+			at = sa[i]
+		}
+	}
+	return x.text[at : at+best]
+}
+
+// DistinctSubstringCount returns the number of distinct
+// non-empty substrings of the indexed text, in clusters.
+func (x *SuffixIndex) DistinctSubstringCount() int {
+	return x.x.DistinctSubstringCount()
+}
@@ -0,0 +1,51 @@
+﻿package graphemewise
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sort"
+	"testing"
+)
+
+func Test_SuffixArray(t *testing.T) {
+	assert.Equal(t, []int{5, 3, 1, 0, 4, 2}, SuffixArray(Split("banana")))
+	assert.Equal(t, []int{0, 5, 3, 1, 4, 2}, SuffixArray(Split("be\u0301ne\u0301ne\u0301")), "\"b\" sorts before \"e\u0301\", which sorts before \"n\".")
+	assert.Equal(t, []int{1, 0}, SuffixArray([]string{"e\u0301", "e"}), "Clusters are compared whole.")
+
+	text := Split("a\u0301bab\u00e1 a\u0301b\u00e1ab ")
+	expected := make([]int, len(text), len(text))
+	for i := range expected {
+		expected[i] = i
+	}
+	sort.Slice(expected, func(i, j int) bool {
+		a, b := text[expected[i]:], text[expected[j]:]
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	assert.Equal(t, expected, SuffixArray(text))
+	assert.Equal(t, []int{}, SuffixArray(nil))
+}
+
+func Test_LCPArray(t *testing.T) {
+	c := Split("be\u0301ne\u0301ne\u0301")
+	assert.Equal(t, []int{0, 0, 1, 3, 0, 2}, LCPArray(c, SuffixArray(c)))
+}
+
+func Test_SuffixIndex(t *testing.T) {
+	text := Split("e\u0301te\u0301 e\u0301te\u0301")
+	x := NewSuffixIndex(text)
+	assert.Equal(t, 2, x.Count(Split("e\u0301te\u0301")))
+	assert.Equal(t, []int{0, 4}, x.Locate(Split("e\u0301te\u0301")), "Offsets are cluster indices.")
+	assert.Equal(t, 0, x.Count(Split("ete")))
+	assert.Equal(t, 8, x.Count(nil))
+	assert.Equal(t, Split("e\u0301te\u0301"), x.LongestRepeatedSubstring())
+	assert.Equal(t, x.LCPArray(), LCPArray(text, x.SuffixArray()))
+	assert.Equal(t, x.SuffixArray(), SuffixArray(text))
+
+	x = NewSuffixIndex(Split("abc"))
+	assert.Equal(t, 0, len(x.LongestRepeatedSubstring()))
+	assert.Equal(t, 6, x.DistinctSubstringCount())
+}
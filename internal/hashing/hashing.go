@@ -0,0 +1,38 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+/*
+Package hashing implements the 64-bit hashing of tokens shared
+by the minhash and simhash packages. The hashes do not depend on
+the platform or the process, so that the signatures and
+fingerprints built from them may be stored and compared later.
+*/
+package hashing
+
+// FNV1a returns the 64-bit FNV-1a hash of s. FNV-1a alone leaves
+// the high bits of the hashes of short strings poorly distributed,
+// so callers which use every bit of a hash should scramble it
+// with Mix.
+//
+// See: http://en.wikipedia.org/wiki/Fowler%E2%80%93Noll%E2%80%93Vo_hash_function
+func FNV1a(s string) uint64 {
+	x := uint64(14695981039346656037)
+	for i := 0; i < len(s); i++ {
+		x ^= uint64(s[i])
+		x *= 1099511628211
+	}
+	return x
+}
+
+// Mix scrambles the bits of x, with the finalizer of SplitMix64,
+// so that every bit of the result depends on every bit of x.
+//
+// See: http://xorshift.di.unimi.it/splitmix64.c
+func Mix(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
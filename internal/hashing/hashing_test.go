@@ -0,0 +1,18 @@
+﻿package hashing
+
+import (
+	"github.com/stretchr/testify/assert"
+	"hash/fnv"
+	"testing"
+)
+
+func Test_FNV1a(t *testing.T) {
+	for _, s := range []string{"", "a", "night", "na\u00efve"} {
+		f := fnv.New64a()
+		f.Write([]byte(s))
+		assert.Equal(t, f.Sum64(), FNV1a(s))
+	}
+	assert.NotEqual(t, FNV1a("ab"), FNV1a("ba"))
+	// The results are fixed, so that stored hashes remain valid.
+	assert.Equal(t, uint64(0xe220a8397b1dcdaf), Mix(0))
+}
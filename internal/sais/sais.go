@@ -0,0 +1,224 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+/*
+Package sais implements linear-time suffix array construction
+by induced sorting (SA-IS), shared by the bytewise and runewise
+suffix array functions.
+
+See: G. Nong, S. Zhang and W. H. Chan, "Two Efficient Algorithms
+for Linear Time Suffix Array Construction", IEEE Transactions on
+Computers 60 (2011).
+*/
+package sais
+
+// Sort returns the suffix array of s, the starting offsets of
+// all non-empty suffixes of s in lexicographic order.
+//
+// Every element of s must lie in the range [0, alphabetSize).
+func Sort(s []int, alphabetSize int) []int {
+	n := len(s)
+	if n == 0 {
+		return []int{}
+	}
+	// Shift the alphabet up by one to make room for
+	// a unique, smallest sentinel at the end.
+	t := make([]int, n+1, n+1)
+	for i, c := range s {
+		t[i] = c + 1
+	}
+	sa := make([]int, n+1, n+1)
+	sais(t, sa, alphabetSize+1)
+	// The sentinel suffix always sorts first.
+	return sa[1:]
+}
+
+// sais fills sa with the suffix array of t, which must
+// end with a unique sentinel smaller than every other symbol.
+func sais(t, sa []int, k int) {
+	n := len(t)
+	if n == 1 {
+		sa[0] = 0
+		return
+	}
+	stype := make([]bool, n, n)
+	stype[n-1] = true
+	for i := n - 2; i >= 0; i-- {
+		stype[i] = t[i] < t[i+1] || (t[i] == t[i+1] && stype[i+1])
+	}
+	isLMS := func(i int) bool {
+		return i > 0 && stype[i] && !stype[i-1]
+	}
+	bkt := make([]int, k, k)
+
+	// Induce-sort the LMS substrings from their bucket ends.
+	for i := range sa {
+		sa[i] = -1
+	}
+	bucketEnds(t, bkt)
+	for i := 1; i < n; i++ {
+		if isLMS(i) {
+			bkt[t[i]]--
+			sa[bkt[t[i]]] = i
+		}
+	}
+	induceL(t, sa, stype, bkt)
+	induceS(t, sa, stype, bkt)
+
+	// Compact the sorted LMS substrings into the front of sa,
+	// then name them, storing names in the back half of sa.
+	n1 := 0
+	for i := 0; i < n; i++ {
+		if isLMS(sa[i]) {
+			sa[n1] = sa[i]
+			n1++
+		}
+	}
+	for i := n1; i < n; i++ {
+		sa[i] = -1
+	}
+	name := 0
+	prev := -1
+	for i := 0; i < n1; i++ {
+		pos := sa[i]
+		diff := false
+		for d := 0; d < n; d++ {
+			if prev == -1 || t[pos+d] != t[prev+d] || stype[pos+d] != stype[prev+d] {
+				diff = true
+				break
+			} else if d > 0 && (isLMS(pos+d) || isLMS(prev+d)) {
+				break
+			}
+		}
+		if diff {
+			name++
+			prev = pos
+		}
+		sa[n1+pos/2] = name - 1
+	}
+	j := n - 1
+	for i := n - 1; i >= n1; i-- {
+		if sa[i] >= 0 {
+			sa[j] = sa[i]
+			j--
+		}
+	}
+
+	// Sort the reduced problem, recursively if any
+	// LMS substrings shared a name.
+	s1 := sa[n-n1:]
+	sa1 := sa[:n1]
+	if name < n1 {
+		sais(s1, sa1, name)
+	} else {
+		for i := 0; i < n1; i++ {
+			sa1[s1[i]] = i
+		}
+	}
+
+	// Induce the full suffix array from the sorted LMS suffixes.
+	j = 0
+	for i := 1; i < n; i++ {
+		if isLMS(i) {
+			s1[j] = i
+			j++
+		}
+	}
+	for i := 0; i < n1; i++ {
+		sa1[i] = s1[sa1[i]]
+	}
+	for i := n1; i < n; i++ {
+		sa[i] = -1
+	}
+	bucketEnds(t, bkt)
+	for i := n1 - 1; i >= 0; i-- {
+		p := sa[i]
+		sa[i] = -1
+		bkt[t[p]]--
+		sa[bkt[t[p]]] = p
+	}
+	induceL(t, sa, stype, bkt)
+	induceS(t, sa, stype, bkt)
+}
+
+func bucketStarts(t, bkt []int) {
+	for i := range bkt {
+		bkt[i] = 0
+	}
+	for _, c := range t {
+		bkt[c]++
+	}
+	sum := 0
+	for i, c := range bkt {
+		bkt[i] = sum
+		sum += c
+	}
+}
+
+func bucketEnds(t, bkt []int) {
+	for i := range bkt {
+		bkt[i] = 0
+	}
+	for _, c := range t {
+		bkt[c]++
+	}
+	sum := 0
+	for i, c := range bkt {
+		sum += c
+		bkt[i] = sum
+	}
+}
+
+func induceL(t, sa []int, stype []bool, bkt []int) {
+	bucketStarts(t, bkt)
+	for i := 0; i < len(sa); i++ {
+		if sa[i] > 0 && !stype[sa[i]-1] {
+			j := sa[i] - 1
+			sa[bkt[t[j]]] = j
+			bkt[t[j]]++
+		}
+	}
+}
+
+func induceS(t, sa []int, stype []bool, bkt []int) {
+	bucketEnds(t, bkt)
+	for i := len(sa) - 1; i >= 0; i-- {
+		if sa[i] > 0 && stype[sa[i]-1] {
+			j := sa[i] - 1
+			bkt[t[j]]--
+			sa[bkt[t[j]]] = j
+		}
+	}
+}
+
+// LCP returns the longest common prefix array for s and its
+// suffix array sa, computed with Kasai's algorithm.
+//
+// Element i holds the length of the longest common prefix of
+// the suffixes at sa[i-1] and sa[i]; element 0 is always 0.
+func LCP(s []int, sa []int) []int {
+	n := len(s)
+	lcp := make([]int, n, n)
+	rank := make([]int, n, n)
+	for i, p := range sa {
+		rank[p] = i
+	}
+	h := 0
+	for i := 0; i < n; i++ {
+		if rank[i] == 0 {
+			h = 0
+			continue
+		}
+		j := sa[rank[i]-1]
+		for i+h < n && j+h < n && s[i+h] == s[j+h] {
+			h++
+		}
+		lcp[rank[i]] = h
+		if h > 0 {
+			h--
+		}
+	}
+	return lcp
+}
@@ -0,0 +1,51 @@
+﻿package sais
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func naiveSuffixArray(s []int) []int {
+	sa := make([]int, len(s))
+	for i := range sa {
+		sa[i] = i
+	}
+	sort.Slice(sa, func(i, j int) bool {
+		a, b := s[sa[i]:], s[sa[j]:]
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return sa
+}
+
+func Test_Sort(t *testing.T) {
+	assert.Equal(t, []int{}, Sort(nil, 1))
+	assert.Equal(t, []int{0}, Sort([]int{0}, 1))
+
+	// banana
+	assert.Equal(t, []int{5, 3, 1, 0, 4, 2}, Sort([]int{1, 0, 2, 0, 2, 0}, 3))
+}
+
+func Test_Sort_AgreesWithNaiveSort(t *testing.T) {
+	r := rand.New(rand.NewSource(29))
+	for iteration := 0; iteration < 2000; iteration++ {
+		k := 1 + r.Intn(4)
+		s := make([]int, r.Intn(60))
+		for i := range s {
+			s[i] = r.Intn(k)
+		}
+		assert.Equal(t, naiveSuffixArray(s), Sort(s, k), "%v", s)
+	}
+}
+
+func Test_LCP(t *testing.T) {
+	banana := []int{1, 0, 2, 0, 2, 0}
+	assert.Equal(t, []int{0, 1, 3, 0, 0, 2}, LCP(banana, Sort(banana, 3)))
+	assert.Equal(t, []int{}, LCP(nil, Sort(nil, 1)))
+}
@@ -0,0 +1,64 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+/*
+Package tokenset implements the token set comparisons shared by
+the token-based similarity metrics of bytewise, runewise and
+graphemewise.
+*/
+package tokenset
+
+// Dice returns the Sorensen-Dice coefficient of the sets of
+// distinct tokens in a and b, twice the number of tokens they
+// share divided by the total number of distinct tokens in each.
+//
+// The result is undefined, and ok is false, if both a and b
+// are empty.
+func Dice(a, b []string) (coefficient float64, ok bool) {
+	if len(a) == 0 && len(b) == 0 {
+		return 0, false
+	}
+	aSet := make(map[string]bool, len(a))
+	total := 0.0
+	for _, token := range a {
+		if !aSet[token] {
+			total++
+			aSet[token] = true
+		}
+	}
+	bSet := make(map[string]bool, len(b))
+	shared := 0.0
+	for _, token := range b {
+		if !bSet[token] {
+			total++
+			bSet[token] = true
+			if aSet[token] {
+				shared++
+			}
+		}
+	}
+	return 2 * shared / total, true
+}
+
+// MultisetDice is like Dice, but accounts for repeated tokens:
+// each occurrence of a token in a may be matched with at most
+// one occurrence of it in b.
+func MultisetDice(a, b []string) (coefficient float64, ok bool) {
+	if len(a) == 0 && len(b) == 0 {
+		return 0, false
+	}
+	counts := make(map[string]int, len(a))
+	for _, token := range a {
+		counts[token]++
+	}
+	shared := 0
+	for _, token := range b {
+		if counts[token] > 0 {
+			counts[token]--
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(a)+len(b)), true
+}
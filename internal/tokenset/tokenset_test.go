@@ -0,0 +1,32 @@
+﻿package tokenset
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_Dice(t *testing.T) {
+	c, ok := Dice([]string{"a", "b", "b"}, []string{"b", "c"})
+	assert.True(t, ok)
+	assert.Equal(t, 0.5, c)
+
+	c, ok = Dice(nil, []string{"a"})
+	assert.True(t, ok)
+	assert.Equal(t, 0.0, c)
+
+	_, ok = Dice(nil, []string{})
+	assert.False(t, ok)
+}
+
+func Test_MultisetDice(t *testing.T) {
+	c, ok := MultisetDice([]string{"a", "b", "b"}, []string{"b", "c"})
+	assert.True(t, ok)
+	assert.Equal(t, 0.4, c)
+
+	c, ok = MultisetDice([]string{"b", "b"}, []string{"b", "b"})
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, c)
+
+	_, ok = MultisetDice(nil, nil)
+	assert.False(t, ok)
+}
@@ -0,0 +1,170 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package metric
+
+import (
+	"github.com/ZackPierce/stralgo/graphemewise"
+	"github.com/ZackPierce/stralgo/sequence"
+	"math"
+	"unicode/utf8"
+)
+
+// BoundedDistance is a Distance which can stop early once the
+// distance between two strings is known to exceed a bound, as
+// when searching for close matches. The built-in "levenshtein"
+// and "damerau-levenshtein" distances, in each of their forms,
+// are BoundedDistances.
+type BoundedDistance interface {
+	Distance
+
+	// DistanceWithin returns the distance between a and b and
+	// true, if the distance is no greater than maxDistance.
+	// Otherwise it returns false, along with a lower bound on
+	// the distance.
+	DistanceWithin(a, b string, maxDistance float64) (float64, bool, error)
+}
+
+// BoundedSimilarity is a Similarity which can stop early once
+// the similarity of two strings is known to fall short of a
+// bound, as when searching for close matches. The built-in
+// "jaro" and "jaro-winkler" similarities are BoundedSimilarities.
+type BoundedSimilarity interface {
+	Similarity
+
+	// SimilarityAtLeast returns the similarity of a and b and
+	// true, if the similarity is at least minSimilarity.
+	// Otherwise it returns false, along with an upper bound on
+	// the similarity.
+	SimilarityAtLeast(a, b string, minSimilarity float64) (float64, bool, error)
+}
+
+// boundedDistance implements BoundedDistance with an integer
+// edit distance function and its bounded variant.
+type boundedDistance struct {
+	DistanceFunc
+	within func(a, b string, maxDistance int) (int, bool, error)
+	// length, if not nil, counts the elements of a string, as
+	// the difference in length between two strings is a lower
+	// bound on their edit distance, and cheap to find.
+	length func(s string) int
+}
+
+func (d boundedDistance) DistanceWithin(a, b string, maxDistance float64) (float64, bool, error) {
+	if maxDistance < 0 {
+		return 0, false, nil
+	}
+	if d.length != nil {
+		aLen, bLen := d.length(a), d.length(b)
+		if diff := float64(max(aLen-bLen, bLen-aLen)); diff > maxDistance {
+			return diff, false, nil
+		}
+	}
+	if maxDistance >= math.MaxInt32 {
+		dist, err := d.DistanceFunc(a, b)
+		return dist, err == nil && dist <= maxDistance, err
+	}
+	dist, ok, err := d.within(a, b, int(maxDistance))
+	return float64(dist), ok, err
+}
+
+// NewBoundedDistance adapts a bytewise or runewise edit distance
+// and its bounded variant, such as runewise.LevenshteinDistance
+// and runewise.LevenshteinDistanceWithin, to the BoundedDistance
+// interface.
+func NewBoundedDistance[S Text](f func(a, b S) (int, error), within func(a, b S, maxDistance int) (int, bool)) BoundedDistance {
+	length := utf8.RuneCountInString
+	if _, ok := any(S("")).(string); ok {
+		length = func(s string) int { return len(s) }
+	}
+	return boundedDistance{
+		DistanceFunc: NewDistance(f),
+		within: func(a, b string, maxDistance int) (int, bool, error) {
+			d, ok := within(S(a), S(b), maxDistance)
+			return d, ok, nil
+		},
+		length: length,
+	}
+}
+
+// NewGraphemeBoundedDistance adapts a graphemewise edit distance
+// and its bounded variant to the BoundedDistance interface,
+// splitting the strings into grapheme clusters with
+// graphemewise.Split.
+func NewGraphemeBoundedDistance(f func(a, b []string) (int, error), within func(a, b []string, maxDistance int) (int, bool)) BoundedDistance {
+	return boundedDistance{
+		DistanceFunc: NewGraphemeDistance(f),
+		within: func(a, b string, maxDistance int) (int, bool, error) {
+			d, ok := within(graphemewise.Split(a), graphemewise.Split(b), maxDistance)
+			return d, ok, nil
+		},
+	}
+}
+
+// newStringsBoundedDistance adapts an edit distance of
+// runewise.Strings and its bounded variant to the BoundedDistance
+// interface.
+func newStringsBoundedDistance(f func(a, b string) (int, error), within func(a, b string, maxDistance int) (int, bool, error)) BoundedDistance {
+	return boundedDistance{
+		DistanceFunc: NewDistance(f),
+		within:       within,
+		length:       utf8.RuneCountInString,
+	}
+}
+
+// boundedSimilarity implements BoundedSimilarity with a
+// similarity function and a cheaper upper bound upon it.
+type boundedSimilarity struct {
+	SimilarityFunc
+	bound func(a, b string) float64
+}
+
+func (s boundedSimilarity) SimilarityAtLeast(a, b string, minSimilarity float64) (float64, bool, error) {
+	if bound := s.bound(a, b); bound < minSimilarity {
+		return bound, false, nil
+	}
+	sim, err := s.SimilarityFunc(a, b)
+	return sim, err == nil && sim >= minSimilarity, err
+}
+
+// NewBoundedSimilarity adapts a similarity to the
+// BoundedSimilarity interface, given a function which returns an
+// upper bound on the similarity of two strings, and is cheaper to
+// call. Only when the bound reaches the minimum similarity sought
+// is the similarity itself calculated.
+func NewBoundedSimilarity(s SimilarityFunc, bound func(a, b string) float64) BoundedSimilarity {
+	return boundedSimilarity{SimilarityFunc: s, bound: bound}
+}
+
+// jaroBound returns an upper bound on the Jaro similarity of two
+// sequences of the given lengths, reached when every element of
+// the shorter matches without transposition.
+func jaroBound(aLen, bLen int) float64 {
+	if aLen == 0 || bLen == 0 {
+		return 0.0
+	}
+	// This follows the arithmetic of the similarity itself, so
+	// that the bound is exact when every element matches.
+	matches := float64(min(aLen, bLen))
+	return (1.0 / 3.0) * (matches/float64(aLen) + matches/float64(bLen) + matches/matches)
+}
+
+// runeJaroBound returns an upper bound on the runewise Jaro
+// similarity of two strings.
+func runeJaroBound(a, b string) float64 {
+	return jaroBound(utf8.RuneCountInString(a), utf8.RuneCountInString(b))
+}
+
+// runeJaroWinklerBound returns an upper bound on the runewise
+// Jaro-Winkler similarity of two strings, with Winkler's
+// constants.
+func runeJaroWinklerBound(a, b string) float64 {
+	j := runeJaroBound(a, b)
+	if j < sequence.WinklerBoostThreshold {
+		return j
+	}
+	return j + float64(sequence.WinklerMaxPrefixLength)*sequence.WinklerPrefixScale*(1.0-j)
+}
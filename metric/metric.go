@@ -0,0 +1,147 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+/*
+Package stralgo/metric defines the Distance and Similarity
+interfaces shared by the metrics of bytewise, runewise and
+graphemewise, adapters from each of their functions to those
+interfaces, and a registry through which metrics can be chosen
+by name at runtime:
+
+	d, err := metric.LookupDistance("levenshtein")
+	if err != nil {
+		return err
+	}
+	score, err := d.Distance("kitten", "sitting")
+
+Every metric takes its inputs as strings, and reports its score
+as a float64, whatever the types used by the function it adapts.
+
+The built-in distances are "hamming", "levenshtein" and
+"damerau-levenshtein", and the built-in similarities are "dice",
+"white", "jaro" and "jaro-winkler". These compare runes, as
+runewise does. The same metrics prefixed with "grapheme-" compare
+grapheme clusters, as graphemewise does, and those of bytewise are
+prefixed with "bytewise-", as in "bytewise-levenshtein". Further
+metrics may be added with RegisterDistance and RegisterSimilarity.
+*/
+package metric
+
+import (
+	"github.com/ZackPierce/stralgo/graphemewise"
+	"unicode/utf8"
+)
+
+// Distance measures how different two strings are. A distance is
+// never negative, is 0 for identical strings, and grows as the
+// strings become more different.
+type Distance interface {
+	Distance(a, b string) (float64, error)
+}
+
+// Similarity measures how alike two strings are, as a score
+// between 0 and 1.0, where 1.0 means the strings are identical
+// (or indistinguishable, for the metric).
+type Similarity interface {
+	Similarity(a, b string) (float64, error)
+}
+
+// DistanceFunc adapts an ordinary function to the Distance
+// interface.
+type DistanceFunc func(a, b string) (float64, error)
+
+// Distance calls f(a, b).
+func (f DistanceFunc) Distance(a, b string) (float64, error) {
+	return f(a, b)
+}
+
+// SimilarityFunc adapts an ordinary function to the Similarity
+// interface.
+type SimilarityFunc func(a, b string) (float64, error)
+
+// Similarity calls f(a, b).
+func (f SimilarityFunc) Similarity(a, b string) (float64, error) {
+	return f(a, b)
+}
+
+// Text is the set of input types accepted by the bytewise and
+// runewise metrics.
+type Text interface {
+	~string | ~[]rune
+}
+
+// Number is the set of score types returned by the metrics.
+type Number interface {
+	~int | ~uint | ~float64
+}
+
+// NewDistance adapts a bytewise or runewise distance function,
+// such as runewise.LevenshteinDistance, to the Distance interface.
+func NewDistance[S Text, N Number](f func(a, b S) (N, error)) DistanceFunc {
+	return func(a, b string) (float64, error) {
+		d, err := f(S(a), S(b))
+		return float64(d), err
+	}
+}
+
+// NewSimilarity adapts a bytewise or runewise similarity function,
+// such as runewise.WhiteSimilarity, to the Similarity interface.
+func NewSimilarity[S Text, N Number](f func(a, b S) (N, error)) SimilarityFunc {
+	return func(a, b string) (float64, error) {
+		s, err := f(S(a), S(b))
+		return float64(s), err
+	}
+}
+
+// NewGraphemeDistance adapts a graphemewise distance function to
+// the Distance interface, splitting the strings into grapheme
+// clusters with graphemewise.Split.
+func NewGraphemeDistance[N Number](f func(a, b []string) (N, error)) DistanceFunc {
+	return func(a, b string) (float64, error) {
+		d, err := f(graphemewise.Split(a), graphemewise.Split(b))
+		return float64(d), err
+	}
+}
+
+// NewGraphemeSimilarity adapts a graphemewise similarity function
+// to the Similarity interface, splitting the strings into grapheme
+// clusters with graphemewise.Split.
+func NewGraphemeSimilarity[N Number](f func(a, b []string) (N, error)) SimilarityFunc {
+	return func(a, b string) (float64, error) {
+		s, err := f(graphemewise.Split(a), graphemewise.Split(b))
+		return float64(s), err
+	}
+}
+
+// NoError adapts a metric function which cannot fail, such as
+// runewise.JaroSimilarity, to the signature taken by the other
+// adapters:
+//
+//	jaro := metric.NewSimilarity(metric.NoError(runewise.JaroSimilarity))
+func NoError[S any, N Number](f func(a, b S) N) func(a, b S) (N, error) {
+	return func(a, b S) (N, error) {
+		return f(a, b), nil
+	}
+}
+
+// NormalizeDistance adapts an edit distance which counts runes,
+// such as "hamming" or "levenshtein", to the Similarity
+// interface, scoring two strings as 1 - distance/length, where
+// length is the number of runes in the longer string. The
+// similarity is clamped to 0, and two empty strings have a
+// similarity of 1.0.
+func NormalizeDistance(d Distance) SimilarityFunc {
+	return func(a, b string) (float64, error) {
+		dist, err := d.Distance(a, b)
+		if err != nil {
+			return 0, err
+		}
+		length := max(utf8.RuneCountInString(a), utf8.RuneCountInString(b))
+		if length == 0 {
+			return 1.0, nil
+		}
+		return max(0, 1.0-dist/float64(length)), nil
+	}
+}
@@ -0,0 +1,186 @@
+﻿package metric
+
+import (
+	"github.com/ZackPierce/stralgo/runewise"
+	"github.com/ZackPierce/stralgo/tokenize"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func Test_NewDistance(t *testing.T) {
+	d := NewDistance(runewise.LevenshteinDistance)
+	score, err := d.Distance("kitten", "sitting")
+	assert.Nil(t, err)
+	assert.Equal(t, 3.0, score)
+
+	_, err = NewDistance(runewise.HammingDistance).Distance("abc", "ab")
+	assert.NotNil(t, err, "Errors of the adapted function are passed through.")
+}
+
+func Test_NewSimilarity(t *testing.T) {
+	s := NewSimilarity(NoError(runewise.JaroWinklerSimilarity))
+	score, err := s.Similarity("MARTHA", "MARHTA")
+	assert.Nil(t, err)
+	EqualWithin(t, 0.961, score, 0.001)
+
+	words := tokenize.Chain(tokenize.Words, tokenize.EnglishStemmer)
+	s = NewSimilarity(func(a, b []rune) (float64, error) {
+		return runewise.DiceCoefficientTokenized(a, b, words)
+	})
+	score, err = s.Similarity("running shoes", "run shoe")
+	assert.Nil(t, err)
+	EqualWithin(t, 1.0, score, 0.0001)
+}
+
+func Test_GraphemeAdapters(t *testing.T) {
+	d, err := LookupDistance("grapheme-levenshtein")
+	assert.Nil(t, err)
+	score, err := d.Distance("cafe\u0301", "cafe")
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, score)
+
+	d, err = LookupDistance("levenshtein")
+	assert.Nil(t, err)
+	score, err = d.Distance("cafe\u0301", "cafe")
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, score)
+
+	d, err = LookupDistance("bytewise-damerau-levenshtein")
+	assert.Nil(t, err)
+	score, err = d.Distance("cafe\u0301", "cafe")
+	assert.Nil(t, err)
+	assert.Equal(t, 2.0, score, "The combining accent is two bytes.")
+}
+
+func Test_Lookup(t *testing.T) {
+	for _, name := range Names() {
+		d, dErr := LookupDistance(name)
+		s, sErr := LookupSimilarity(name)
+		if dErr == nil {
+			assert.NotNil(t, sErr, name)
+			score, err := d.Distance("martha", "marhta")
+			assert.Nil(t, err, name)
+			assert.True(t, score > 0, name)
+			score, err = d.Distance("martha", "martha")
+			assert.Nil(t, err, name)
+			assert.Equal(t, 0.0, score, name)
+		} else {
+			assert.Nil(t, sErr, name)
+			score, err := s.Similarity("martha", "marhta")
+			assert.Nil(t, err, name)
+			assert.True(t, score > 0 && score < 1, name)
+			score, err = s.Similarity("martha", "martha")
+			assert.Nil(t, err, name)
+			EqualWithin(t, 1.0, score, 0.0001, name)
+		}
+	}
+
+	_, err := LookupDistance("jaro-winkler")
+	assert.NotNil(t, err, "A Similarity is not a Distance.")
+	_, err = LookupSimilarity("levenshtein")
+	assert.NotNil(t, err, "A Distance is not a Similarity.")
+	_, err = LookupDistance("no-such-metric")
+	assert.NotNil(t, err)
+}
+
+func Test_Bounded(t *testing.T) {
+	pairs := [][2]string{
+		{"kitten", "sitting"},
+		{"martha", "marhta"},
+		{"", "abc"},
+		{"dixon", "dicksonx"},
+		{"caf\u00e9", "cafe\u0301"},
+		{"abc", "abc"},
+	}
+	bounded := 0
+	for _, name := range Names() {
+		if d, err := LookupDistance(name); err == nil {
+			b, ok := d.(BoundedDistance)
+			if !ok {
+				continue
+			}
+			bounded++
+			for _, p := range pairs {
+				exact, _ := d.Distance(p[0], p[1])
+				for _, maxDistance := range []float64{-1, 0, 1, 2.5, 3, math.Inf(1)} {
+					score, within, err := b.DistanceWithin(p[0], p[1], maxDistance)
+					assert.Nil(t, err)
+					assert.Equal(t, exact <= maxDistance, within, "%s %q %v", name, p, maxDistance)
+					if within {
+						assert.Equal(t, exact, score, "%s %q %v", name, p, maxDistance)
+					} else {
+						assert.True(t, score <= exact, "A lower bound: %s %q %v", name, p, maxDistance)
+					}
+				}
+			}
+		} else if s, err := LookupSimilarity(name); err == nil {
+			b, ok := s.(BoundedSimilarity)
+			if !ok {
+				continue
+			}
+			bounded++
+			for _, p := range pairs {
+				exact, _ := s.Similarity(p[0], p[1])
+				for _, minSimilarity := range []float64{0, 0.5, 0.8, 0.9, exact, 1} {
+					score, atLeast, err := b.SimilarityAtLeast(p[0], p[1], minSimilarity)
+					assert.Nil(t, err)
+					assert.Equal(t, exact >= minSimilarity, atLeast, "%s %q %v", name, p, minSimilarity)
+					if atLeast {
+						assert.Equal(t, exact, score, "%s %q %v", name, p, minSimilarity)
+					} else {
+						assert.True(t, score >= exact, "An upper bound: %s %q %v", name, p, minSimilarity)
+					}
+				}
+			}
+		}
+	}
+	assert.Equal(t, 8, bounded)
+}
+
+func Test_Register(t *testing.T) {
+	exact := DistanceFunc(func(a, b string) (float64, error) {
+		if a == b {
+			return 0, nil
+		}
+		return 1, nil
+	})
+	assert.Nil(t, RegisterDistance("test-exact", exact))
+	assert.Contains(t, Names(), "test-exact")
+	d, err := LookupDistance("test-exact")
+	assert.Nil(t, err)
+	score, err := d.Distance("a", "b")
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, score)
+
+	assert.NotNil(t, RegisterDistance("test-exact", exact), "Names may only be registered once.")
+	assert.NotNil(t, RegisterSimilarity("test-nil", nil), "A nil Similarity is refused.")
+	assert.NotNil(t, RegisterSimilarity("levenshtein", NewSimilarity(runewise.DiceCoefficient)), "Distances and similarities share names.")
+	assert.NotNil(t, RegisterDistance("", exact))
+}
+
+func EqualWithin(t *testing.T, a, b, delta float64, msgAndArgs ...interface{}) bool {
+	if math.Abs(a-b) > delta {
+		return assert.Fail(t, "Values not within delta", msgAndArgs...)
+	}
+	return true
+}
+
+func Test_NormalizeDistance(t *testing.T) {
+	d, _ := LookupDistance("hamming")
+	s := NormalizeDistance(d)
+	score, err := s.Similarity("SW1A 1AA", "SW1A 2AA")
+	assert.Nil(t, err)
+	assert.Equal(t, 0.875, score)
+	score, err = s.Similarity("", "")
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, score)
+	_, err = s.Similarity("SW1A", "SW1A 1AA")
+	assert.NotNil(t, err)
+
+	l, _ := LookupDistance("levenshtein")
+	score, _ = NormalizeDistance(l).Similarity("caf\u00e9", "cafe")
+	assert.Equal(t, 0.75, score)
+	score, _ = NormalizeDistance(l).Similarity("abc", "")
+	assert.Equal(t, 0.0, score)
+}
@@ -0,0 +1,137 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package metric
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ZackPierce/stralgo/bytewise"
+	"github.com/ZackPierce/stralgo/graphemewise"
+	"github.com/ZackPierce/stralgo/runewise"
+	"sort"
+	"sync"
+)
+
+var registry = struct {
+	sync.RWMutex
+	distances    map[string]Distance
+	similarities map[string]Similarity
+}{
+	distances: map[string]Distance{
+		"hamming":             NewDistance(runewise.Strings.HammingDistance),
+		"levenshtein":         newStringsBoundedDistance(runewise.Strings.LevenshteinDistance, runewise.Strings.LevenshteinDistanceWithin),
+		"damerau-levenshtein": newStringsBoundedDistance(runewise.Strings.DamerauLevenshteinDistance, runewise.Strings.DamerauLevenshteinDistanceWithin),
+
+		"bytewise-hamming":             NewDistance(bytewise.HammingDistance),
+		"bytewise-levenshtein":         NewBoundedDistance(bytewise.LevenshteinDistance, bytewise.LevenshteinDistanceWithin),
+		"bytewise-damerau-levenshtein": NewBoundedDistance(bytewise.DamerauLevenshteinDistance, bytewise.DamerauLevenshteinDistanceWithin),
+
+		"grapheme-hamming":             NewGraphemeDistance(graphemewise.HammingDistance),
+		"grapheme-levenshtein":         NewGraphemeBoundedDistance(graphemewise.LevenshteinDistance, graphemewise.LevenshteinDistanceWithin),
+		"grapheme-damerau-levenshtein": NewGraphemeBoundedDistance(graphemewise.DamerauLevenshteinDistance, graphemewise.DamerauLevenshteinDistanceWithin),
+	},
+	similarities: map[string]Similarity{
+		"dice":         NewSimilarity(runewise.Strings.DiceCoefficient),
+		"white":        NewSimilarity(runewise.Strings.WhiteSimilarity),
+		"jaro":         NewBoundedSimilarity(NewSimilarity(runewise.Strings.JaroSimilarity), runeJaroBound),
+		"jaro-winkler": NewBoundedSimilarity(NewSimilarity(runewise.Strings.JaroWinklerSimilarity), runeJaroWinklerBound),
+
+		"bytewise-dice":  NewSimilarity(bytewise.DiceCoefficient),
+		"bytewise-white": NewSimilarity(bytewise.WhiteSimilarity),
+
+		"grapheme-dice":         NewGraphemeSimilarity(graphemewise.DiceCoefficient),
+		"grapheme-white":        NewGraphemeSimilarity(graphemewise.WhiteSimilarity),
+		"grapheme-jaro":         NewGraphemeSimilarity(NoError(graphemewise.JaroSimilarity)),
+		"grapheme-jaro-winkler": NewGraphemeSimilarity(NoError(graphemewise.JaroWinklerSimilarity)),
+	},
+}
+
+// RegisterDistance makes a Distance available by name to
+// LookupDistance. An error is returned if the name is empty or
+// is already registered, as either a Distance or a Similarity.
+func RegisterDistance(name string, d Distance) error {
+	if d == nil {
+		return errors.New("A registered Distance must not be nil.")
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	if err := checkName(name); err != nil {
+		return err
+	}
+	registry.distances[name] = d
+	return nil
+}
+
+// RegisterSimilarity makes a Similarity available by name to
+// LookupSimilarity. An error is returned if the name is empty or
+// is already registered, as either a Distance or a Similarity.
+func RegisterSimilarity(name string, s Similarity) error {
+	if s == nil {
+		return errors.New("A registered Similarity must not be nil.")
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	if err := checkName(name); err != nil {
+		return err
+	}
+	registry.similarities[name] = s
+	return nil
+}
+
+func checkName(name string) error {
+	if name == "" {
+		return errors.New("A metric name must not be empty.")
+	}
+	_, isDistance := registry.distances[name]
+	_, isSimilarity := registry.similarities[name]
+	if isDistance || isSimilarity {
+		return fmt.Errorf("A metric named %q is already registered.", name)
+	}
+	return nil
+}
+
+// LookupDistance returns the Distance registered under name.
+func LookupDistance(name string) (Distance, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+	if d, ok := registry.distances[name]; ok {
+		return d, nil
+	}
+	if _, ok := registry.similarities[name]; ok {
+		return nil, fmt.Errorf("The metric %q is a Similarity, not a Distance.", name)
+	}
+	return nil, fmt.Errorf("No metric named %q is registered.", name)
+}
+
+// LookupSimilarity returns the Similarity registered under name.
+func LookupSimilarity(name string) (Similarity, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+	if s, ok := registry.similarities[name]; ok {
+		return s, nil
+	}
+	if _, ok := registry.distances[name]; ok {
+		return nil, fmt.Errorf("The metric %q is a Distance, not a Similarity.", name)
+	}
+	return nil, fmt.Errorf("No metric named %q is registered.", name)
+}
+
+// Names returns the names of all the registered metrics, both
+// distances and similarities, in sorted order.
+func Names() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	names := make([]string, 0, len(registry.distances)+len(registry.similarities))
+	for name := range registry.distances {
+		names = append(names, name)
+	}
+	for name := range registry.similarities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
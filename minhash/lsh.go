@@ -0,0 +1,191 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package minhash
+
+import (
+	"fmt"
+	"github.com/ZackPierce/stralgo/internal/hashing"
+	"math"
+	"sort"
+)
+
+// Index finds the signatures likely to be similar by
+// locality-sensitive hashing. Each signature is divided into
+// bands of rows, and two signatures become a candidate pair if
+// every row of any one band is equal, which for sets of Jaccard
+// similarity s happens with probability
+//
+//	1 - (1 - s^rows)^bands
+//
+// an S-shaped curve, rising most steeply near (1/bands)^(1/rows).
+// OptimalBands chooses the bands and rows whose curve best
+// separates the pairs above a threshold from those below it.
+//
+// An Index is not safe for concurrent use while signatures are
+// being added.
+type Index struct {
+	bands, rows int
+	// buckets maps the hash of each band of the signatures to
+	// their ids, for each band.
+	buckets []map[uint64][]int
+	size    int
+}
+
+// NewIndex returns an empty Index which divides signatures into
+// the given number of bands of the given number of rows. The
+// rows of signatures beyond bands*rows are ignored.
+//
+// NewIndex panics if bands or rows is less than 1.
+func NewIndex(bands, rows int) *Index {
+	if bands < 1 || rows < 1 {
+		panic("An Index must have at least one band of at least one row.")
+	}
+	x := &Index{bands: bands, rows: rows, buckets: make([]map[uint64][]int, bands, bands)}
+	for i := range x.buckets {
+		x.buckets[i] = make(map[uint64][]int)
+	}
+	return x
+}
+
+// Len returns the number of signatures in the index.
+func (x *Index) Len() int {
+	return x.size
+}
+
+// Add adds a signature to the index, and returns its id: the
+// number of signatures added before it.
+//
+// Add panics if the signature is shorter than bands*rows.
+func (x *Index) Add(sig Signature) int {
+	x.check(sig)
+	id := x.size
+	for band := range x.buckets {
+		key := x.bandHash(sig, band)
+		x.buckets[band][key] = append(x.buckets[band][key], id)
+	}
+	x.size++
+	return id
+}
+
+// Query returns the ids of the signatures in the index which
+// share a band with sig, in increasing order.
+//
+// Query panics if the signature is shorter than bands*rows.
+func (x *Index) Query(sig Signature) []int {
+	x.check(sig)
+	seen := make(map[int]struct{})
+	var ids []int
+	for band := range x.buckets {
+		for _, id := range x.buckets[band][x.bandHash(sig, band)] {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Candidates returns each pair of ids of signatures in the index
+// which share a band, once, with the lesser id first, ordered by
+// the first id and then the second.
+//
+// A bucket shared by many signatures, such as those of many
+// empty texts, yields a pair for every two of them; maxBucketSize,
+// if positive, skips buckets of more signatures than that.
+func (x *Index) Candidates(maxBucketSize int) [][2]int {
+	seen := make(map[[2]int]struct{})
+	var pairs [][2]int
+	for _, buckets := range x.buckets {
+		for _, ids := range buckets {
+			if len(ids) < 2 || (maxBucketSize > 0 && len(ids) > maxBucketSize) {
+				continue
+			}
+			for i, a := range ids {
+				for _, b := range ids[i+1:] {
+					pair := [2]int{a, b}
+					if _, ok := seen[pair]; !ok {
+						seen[pair] = struct{}{}
+						pairs = append(pairs, pair)
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	return pairs
+}
+
+func (x *Index) check(sig Signature) {
+	if len(sig) < x.bands*x.rows {
+		panic(fmt.Sprintf("minhash: a signature of %d rows is too short for %d bands of %d rows", len(sig), x.bands, x.rows))
+	}
+}
+
+// bandHash returns a hash of the rows of a band of a signature.
+func (x *Index) bandHash(sig Signature, band int) uint64 {
+	h := uint64(band)
+	for _, v := range sig[band*x.rows : (band+1)*x.rows] {
+		h = hashing.Mix(h ^ v)
+	}
+	return h
+}
+
+// CandidateProbability returns the probability that two
+// signatures of sets of the given Jaccard similarity share a band,
+// and so become a candidate pair, when divided into the given
+// number of bands and rows.
+func CandidateProbability(bands, rows int, similarity float64) float64 {
+	return 1 - math.Pow(1-math.Pow(similarity, float64(rows)), float64(bands))
+}
+
+// OptimalBands returns the number of bands and rows, using at
+// most the given number of permutations, which best separates the
+// pairs of sets of at least the given Jaccard similarity from
+// those of less: the one for which the sum of the probability of
+// missing a pair above the threshold and that of proposing a pair
+// below it, over similarities evenly distributed between 0 and 1,
+// is least.
+//
+// OptimalBands panics if permutations is less than 1.
+func OptimalBands(permutations int, threshold float64) (bands, rows int) {
+	if permutations < 1 {
+		panic("The number of permutations must be at least 1.")
+	}
+	best := math.Inf(1)
+	for b := 1; b <= permutations; b++ {
+		for r := 1; b*r <= permutations; r++ {
+			falsePositives := integrate(func(s float64) float64 { return CandidateProbability(b, r, s) }, 0, threshold)
+			falseNegatives := integrate(func(s float64) float64 { return 1 - CandidateProbability(b, r, s) }, threshold, 1)
+			if total := falsePositives + falseNegatives; total < best {
+				best, bands, rows = total, b, r
+			}
+		}
+	}
+	return bands, rows
+}
+
+// integrate approximates the integral of f from a to b with the
+// midpoint rule.
+func integrate(f func(x float64) float64, a, b float64) float64 {
+	const steps = 100
+	if b <= a {
+		return 0
+	}
+	width := (b - a) / steps
+	sum := 0.0
+	for i := 0; i < steps; i++ {
+		sum += f(a + (float64(i)+0.5)*width)
+	}
+	return sum * width
+}
@@ -0,0 +1,136 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+/*
+Package stralgo/minhash implements MinHash signatures, which
+estimate the Jaccard similarity of the sets of tokens of two
+texts, such as their character shingles or words, and an index
+which finds the pairs of texts likely to be similar by
+locality-sensitive hashing, without comparing every pair.
+
+Finding near-duplicates among millions of texts proceeds in
+three steps: hash each text to a short signature; add the
+signatures to an Index, banded for the least similarity sought;
+and verify the candidate pairs it returns, with Jaccard or an
+exact metric such as runewise.DiceCoefficient:
+
+	h := minhash.New(tokenize.NGrams(5), 128, 1)
+	bands, rows := minhash.OptimalBands(h.Permutations(), 0.8)
+	index := minhash.NewIndex(bands, rows)
+	for _, text := range texts {
+		index.Add(h.Signature(text))
+	}
+	for _, pair := range index.Candidates(0) {
+		... // Verify texts[pair[0]] and texts[pair[1]].
+	}
+
+See: http://en.wikipedia.org/wiki/MinHash
+
+See: http://infolab.stanford.edu/~ullman/mmds/ch3.pdf
+*/
+package minhash
+
+import (
+	"github.com/ZackPierce/stralgo"
+	"github.com/ZackPierce/stralgo/internal/hashing"
+	"github.com/ZackPierce/stralgo/tokenize"
+	"math"
+	"math/rand"
+)
+
+// Signature is the MinHash signature of a set of tokens: the
+// least hash of any of the tokens under each of a number of hash
+// functions, or math.MaxUint64 for each if there are no tokens.
+type Signature []uint64
+
+// Hasher computes the signatures of texts. It is safe for
+// concurrent use, provided its tokenizer is.
+type Hasher struct {
+	tokenizer tokenize.Tokenizer
+	// seeds holds the seed of each hash function, each one
+	// standing in for a random permutation of the tokens.
+	seeds []uint64
+}
+
+// New returns a Hasher which splits texts into tokens with the
+// given tokenizer, such as tokenize.NGrams(5) for character
+// shingles or tokenize.Words for words, and computes signatures
+// of the given number of permutations. The more permutations, the
+// more accurate the estimates of similarity, but the slower and
+// larger the signatures: the standard error of an estimate is
+// about 1/sqrt(permutations).
+//
+// The hash functions are chosen at random from a source seeded
+// with seed, so signatures are comparable only between Hashers
+// with the same seed and number of permutations. They do not
+// depend on the platform, so signatures may be stored and
+// compared later.
+//
+// New panics if permutations is less than 1.
+func New(tokenizer tokenize.Tokenizer, permutations int, seed int64) *Hasher {
+	if permutations < 1 {
+		panic("The number of permutations must be at least 1.")
+	}
+	rng := rand.New(rand.NewSource(seed))
+	h := &Hasher{tokenizer: tokenizer, seeds: make([]uint64, permutations, permutations)}
+	for i := range h.seeds {
+		h.seeds[i] = rng.Uint64()
+	}
+	return h
+}
+
+// Permutations returns the length of the signatures computed by
+// h.
+func (h *Hasher) Permutations() int {
+	return len(h.seeds)
+}
+
+// Signature returns the signature of the tokens of s.
+func (h *Hasher) Signature(s string) Signature {
+	return h.TokenSignature(h.tokenizer.Tokenize(s))
+}
+
+// TokenSignature returns the signature of a set of tokens, which
+// may be repeated without effect.
+func (h *Hasher) TokenSignature(tokens []string) Signature {
+	sig := make(Signature, len(h.seeds), len(h.seeds))
+	for i := range sig {
+		sig[i] = math.MaxUint64
+	}
+	for _, token := range tokens {
+		x := hashing.FNV1a(token)
+		for i, seed := range h.seeds {
+			if v := hashing.Mix(x ^ seed); v < sig[i] {
+				sig[i] = v
+			}
+		}
+	}
+	return sig
+}
+
+// Jaccard estimates the Jaccard similarity of the sets of tokens
+// from which two signatures were computed, as the proportion of
+// their elements which are equal. The signatures of two empty
+// sets are equal, with an estimate of 1.
+//
+// See: http://en.wikipedia.org/wiki/Jaccard_index
+//
+// Returns a *stralgo.LengthMismatchError, which matches
+// stralgo.ErrUnequalLength, if the signatures differ in length.
+func Jaccard(a, b Signature) (float64, error) {
+	if len(a) != len(b) {
+		return 0, &stralgo.LengthMismatchError{Metric: "MinHash Jaccard similarity", ALength: len(a), BLength: len(b)}
+	}
+	if len(a) == 0 {
+		return 0, nil
+	}
+	equal := 0
+	for i := range a {
+		if a[i] == b[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(a)), nil
+}
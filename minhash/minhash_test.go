@@ -0,0 +1,165 @@
+﻿package minhash
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ZackPierce/stralgo"
+	"github.com/ZackPierce/stralgo/tokenize"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// jaccard returns the Jaccard similarity of two sets of tokens.
+func jaccard(a, b []string) float64 {
+	set := make(map[string]int)
+	for _, t := range a {
+		set[t] |= 1
+	}
+	for _, t := range b {
+		set[t] |= 2
+	}
+	both := 0
+	for _, in := range set {
+		if in == 3 {
+			both++
+		}
+	}
+	return float64(both) / float64(len(set))
+}
+
+// randomText returns a text of random words.
+func randomText(r *rand.Rand, words int) string {
+	text := make([]byte, 0, words*6)
+	for i := 0; i < words; i++ {
+		if i > 0 {
+			text = append(text, ' ')
+		}
+		for j := 0; j < 2+r.Intn(6); j++ {
+			text = append(text, byte('a'+r.Intn(26)))
+		}
+	}
+	return string(text)
+}
+
+// mutate changes a random fraction of the runes of a text.
+func mutate(r *rand.Rand, text string, fraction float64) string {
+	b := []byte(text)
+	for i := range b {
+		if r.Float64() < fraction {
+			b[i] = byte('a' + r.Intn(26))
+		}
+	}
+	return string(b)
+}
+
+func Test_Signature_Stable(t *testing.T) {
+	// Signatures may be stored and compared later, so the hashes
+	// they are built from must not change.
+	h := New(tokenize.Words, 4, 1)
+	assert.Equal(t, Signature{0x182e4e079706434b, 0x72c383506b896fe1, 0xb989c6094280726, 0xad75b89a6ba07e}, h.Signature("the quick brown fox"))
+}
+
+func Test_Jaccard(t *testing.T) {
+	shingles := tokenize.NGrams(3)
+	h := New(shingles, 256, 1)
+	assert.Equal(t, 256, h.Permutations())
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		a := randomText(r, 40)
+		b := mutate(r, a, r.Float64()*0.3)
+		expected := jaccard(shingles.Tokenize(a), shingles.Tokenize(b))
+		estimate, err := Jaccard(h.Signature(a), h.Signature(b))
+		assert.Nil(t, err)
+		// Four standard errors.
+		assert.InDelta(t, expected, estimate, 4*math.Sqrt(expected*(1-expected)/256)+0.01)
+	}
+
+	sig := h.Signature("the same text")
+	assert.Equal(t, sig, New(shingles, 256, 1).Signature("the same text"), "Signatures are reproducible.")
+	assert.NotEqual(t, sig, New(shingles, 256, 2).Signature("the same text"))
+	assert.Equal(t, sig, h.TokenSignature(append(shingles.Tokenize("the same text"), "the")))
+
+	s, err := Jaccard(sig, sig)
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, s)
+	s, err = Jaccard(h.Signature(""), h.Signature("a"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, s, "Texts with no shingles have the same signature.")
+	s, err = Jaccard(h.Signature("abcdef"), h.Signature("uvwxyz"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0.0, s)
+
+	_, err = Jaccard(sig, sig[:10])
+	assert.True(t, errors.Is(err, stralgo.ErrUnequalLength))
+	assert.Panics(t, func() { New(shingles, 0, 1) })
+}
+
+func Test_OptimalBands(t *testing.T) {
+	for _, test := range []struct {
+		permutations int
+		threshold    float64
+	}{
+		{128, 0.8}, {128, 0.5}, {256, 0.9}, {64, 0.3},
+	} {
+		bands, rows := OptimalBands(test.permutations, test.threshold)
+		assert.True(t, bands*rows <= test.permutations)
+		// The steepest part of the curve is near the threshold.
+		assert.InDelta(t, test.threshold, math.Pow(1/float64(bands), 1/float64(rows)), 0.1, "%d bands of %d rows", bands, rows)
+		assert.True(t, CandidateProbability(bands, rows, test.threshold+0.1) > 0.7)
+		assert.True(t, CandidateProbability(bands, rows, test.threshold-0.2) < 0.2)
+	}
+	assert.Equal(t, 0.0, CandidateProbability(4, 8, 0))
+	assert.Equal(t, 1.0, CandidateProbability(4, 8, 1))
+}
+
+func Test_Index(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	var texts []string
+	// Texts 2i and 2i+1 are near-duplicates; others are not.
+	for i := 0; i < 100; i++ {
+		a := randomText(r, 30)
+		texts = append(texts, a, mutate(r, a, 0.02))
+	}
+
+	h := New(tokenize.NGrams(4), 128, 1)
+	bands, rows := OptimalBands(h.Permutations(), 0.6)
+	index := NewIndex(bands, rows)
+	for i, text := range texts {
+		assert.Equal(t, i, index.Add(h.Signature(text)))
+	}
+	assert.Equal(t, len(texts), index.Len())
+
+	found := 0
+	for _, pair := range index.Candidates(0) {
+		assert.True(t, pair[0] < pair[1])
+		if pair[0]%2 == 0 && pair[1] == pair[0]+1 {
+			found++
+		} else {
+			t.Errorf("Texts %d and %d are not near-duplicates.", pair[0], pair[1])
+		}
+	}
+	assert.True(t, found >= 95, fmt.Sprintf("found %d of 100 near-duplicates", found))
+
+	assert.Equal(t, []int{10, 11}, index.Query(h.Signature(texts[10])))
+	assert.Empty(t, index.Query(h.Signature(randomText(r, 30))))
+	assert.Panics(t, func() { index.Add(Signature{1, 2}) })
+	assert.Panics(t, func() { NewIndex(0, 4) })
+
+	empties := NewIndex(4, 2)
+	for i := 0; i < 5; i++ {
+		empties.Add(h.Signature(""))
+	}
+	assert.Len(t, empties.Candidates(0), 10)
+	assert.Empty(t, empties.Candidates(4))
+}
+
+func Benchmark_Signature(b *testing.B) {
+	h := New(tokenize.NGrams(5), 128, 1)
+	text := randomText(rand.New(rand.NewSource(1)), 100)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Signature(text)
+	}
+}
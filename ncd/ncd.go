@@ -0,0 +1,255 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+/*
+Package stralgo/ncd implements the normalized compression
+distance, which measures how much two strings have in common by
+how much better they compress together than apart. It needs no
+knowledge of their structure, and so suits long, messy strings
+such as log lines or HTML, whose similarity lies in shared
+substrings anywhere within them rather than in their characters
+position by position:
+
+	d := ncd.NewCache(ncd.Flate, 10000)
+	for _, line := range lines {
+		dist, _ := d.Distance(query, line) // query is compressed only once.
+	}
+
+The distance is near 0 for identical strings, and near 1 for
+strings with nothing in common, though with a real compressor it
+is never exactly either. It is not a good measure of short
+strings, whose compressed lengths are dominated by the fixed
+overhead of the compressed format.
+
+A Cache is a metric.Distance, and may be registered with
+metric.RegisterDistance for use by name.
+
+See: http://en.wikipedia.org/wiki/Normalized_compression_distance
+*/
+package ncd
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"container/list"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Compressor selects the compressor used to approximate the
+// information content of strings. Each is the DEFLATE algorithm
+// of compress/flate at its best compression, with the framing of
+// its format: Flate has none, Zlib adds 6 bytes, and Gzip 18. The
+// less the framing, the better the distances between short strings.
+type Compressor int
+
+const (
+	Flate Compressor = iota // Raw DEFLATE, with compress/flate.
+	Gzip                    // The gzip format, with compress/gzip.
+	Zlib                    // The zlib format, with compress/zlib.
+)
+
+func (c Compressor) String() string {
+	switch c {
+	case Flate:
+		return "flate"
+	case Gzip:
+		return "gzip"
+	case Zlib:
+		return "zlib"
+	}
+	return fmt.Sprintf("Compressor(%d)", int(c))
+}
+
+// resetWriter is a compressing writer which may be reused.
+type resetWriter interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// writers holds a pool of the writers of each compressor, as a
+// new writer allocates several hundred kilobytes of state.
+var writers = [...]sync.Pool{
+	Flate: {New: func() interface{} {
+		w, _ := flate.NewWriter(io.Discard, flate.BestCompression)
+		return w
+	}},
+	Gzip: {New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(io.Discard, gzip.BestCompression)
+		return w
+	}},
+	Zlib: {New: func() interface{} {
+		w, _ := zlib.NewWriterLevel(io.Discard, zlib.BestCompression)
+		return w
+	}},
+}
+
+// counter is a writer which counts the bytes written to it.
+type counter int
+
+func (c *counter) Write(p []byte) (int, error) {
+	*c += counter(len(p))
+	return len(p), nil
+}
+
+// CompressedLength returns the length in bytes of the
+// concatenation of the given strings, compressed with c.
+//
+// CompressedLength panics if c is not a known Compressor.
+func (c Compressor) CompressedLength(s ...string) int {
+	if c < Flate || c > Zlib {
+		panic(fmt.Sprintf("ncd: unknown compressor %v", c))
+	}
+	var n counter
+	w := writers[c].Get().(resetWriter)
+	w.Reset(&n)
+	for _, part := range s {
+		// Writes to a counter cannot fail.
+		io.WriteString(w, part)
+	}
+	w.Close()
+	writers[c].Put(w)
+	return int(n)
+}
+
+// Distance returns the normalized compression distance between a
+// and b under the given compressor:
+//
+//	(C(ab) - min(C(a), C(b))) / max(C(a), C(b))
+//
+// where C(x) is the compressed length of x. To compare one string
+// with many, use a Cache, which compresses each string only once.
+//
+// DEFLATE finds repeats only within the last 32 KB it has seen, so
+// were b simply appended to a, no part of b more than 32 KB from
+// its copy in a would be found there, and long strings would seem
+// to have nothing in common, even with themselves. Instead, C(ab)
+// is the compressed length of a and b in alternate blocks of
+// 16 KB, a's first, so that each block of b is compressed within
+// reach of the corresponding block of a. For strings of up to
+// 16 KB, this is simply a followed by b. Long strings whose common
+// parts lie more than 16 KB out of step, though, are still found
+// to have little in common.
+//
+// Distance panics if c is not a known Compressor.
+func Distance(a, b string, c Compressor) float64 {
+	return distance(c.CompressedLength(a), c.CompressedLength(b), c.pairLength(a, b))
+}
+
+// blockSize is the length of the blocks in which pairLength
+// alternates between its strings: half the window of DEFLATE, so
+// that each block of the second string follows the corresponding
+// block of the first within the window.
+const blockSize = 16 << 10
+
+// pairLength returns the compressed length of a and b together,
+// in alternate blocks, as described for Distance.
+func (c Compressor) pairLength(a, b string) int {
+	parts := make([]string, 0, 2*(len(a)/blockSize+1))
+	for len(a) > blockSize && len(b) > 0 {
+		n := min(blockSize, len(b))
+		parts = append(parts, a[:blockSize], b[:n])
+		a, b = a[blockSize:], b[n:]
+	}
+	parts = append(parts, a, b)
+	return c.CompressedLength(parts...)
+}
+
+func distance(ca, cb, cab int) float64 {
+	return float64(cab-min(ca, cb)) / float64(max(ca, cb))
+}
+
+// Cache computes normalized compression distances, remembering
+// the compressed length of each string it compresses, so that
+// comparing one string with many compresses the one only once.
+// The compressed length of each pair of strings together must
+// still be computed.
+//
+// A Cache is safe for concurrent use.
+type Cache struct {
+	compressor Compressor
+	maxEntries int
+
+	mu sync.Mutex
+	// lengths maps each string in the cache to its element of
+	// recent, which holds its cacheEntry.
+	lengths map[string]*list.Element
+	// recent orders the entries from the most recently used to
+	// the least.
+	recent *list.List
+}
+
+type cacheEntry struct {
+	s      string
+	length int
+}
+
+// NewCache returns an empty Cache for the given compressor,
+// which remembers the lengths of up to maxEntries strings, or of
+// every string if maxEntries is not positive. Once full, the
+// cache forgets the string it has gone longest without using to
+// make room for another, so that a query compared with many
+// strings in turn is not forgotten, provided maxEntries is at
+// least 2.
+//
+// NewCache panics if c is not a known Compressor.
+func NewCache(c Compressor, maxEntries int) *Cache {
+	if c < Flate || c > Zlib {
+		panic(fmt.Sprintf("ncd: unknown compressor %v", c))
+	}
+	return &Cache{compressor: c, maxEntries: maxEntries, lengths: make(map[string]*list.Element), recent: list.New()}
+}
+
+// Compressor returns the compressor used by the cache.
+func (c *Cache) Compressor() Compressor {
+	return c.compressor
+}
+
+// Distance returns the normalized compression distance between a
+// and b, as the package-level Distance does. Its error is always
+// nil; it is returned to satisfy the metric.Distance interface.
+func (c *Cache) Distance(a, b string) (float64, error) {
+	return distance(c.CompressedLength(a), c.CompressedLength(b), c.compressor.pairLength(a, b)), nil
+}
+
+// CompressedLength returns the compressed length of s, from the
+// cache if it is there.
+func (c *Cache) CompressedLength(s string) int {
+	c.mu.Lock()
+	if e, ok := c.lengths[s]; ok {
+		c.recent.MoveToFront(e)
+		n := e.Value.(*cacheEntry).length
+		c.mu.Unlock()
+		return n
+	}
+	c.mu.Unlock()
+
+	// Compressing is slow, so is done without holding the lock;
+	// two goroutines may compress the same string at once, to
+	// the same length.
+	n := c.compressor.CompressedLength(s)
+	c.mu.Lock()
+	if e, ok := c.lengths[s]; ok {
+		c.recent.MoveToFront(e)
+	} else {
+		c.lengths[s] = c.recent.PushFront(&cacheEntry{s: s, length: n})
+		if c.maxEntries > 0 && c.recent.Len() > c.maxEntries {
+			oldest := c.recent.Remove(c.recent.Back()).(*cacheEntry)
+			delete(c.lengths, oldest.s)
+		}
+	}
+	c.mu.Unlock()
+	return n
+}
+
+// Len returns the number of strings whose compressed lengths are
+// in the cache.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.lengths)
+}
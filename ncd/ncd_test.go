@@ -0,0 +1,137 @@
+﻿package ncd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"github.com/ZackPierce/stralgo/metric"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var logLines = []string{
+	`2013-06-01 12:00:01 INFO  [http-worker-3] GET /api/v1/users/1842/orders?page=2 200 OK 34ms user-agent="Mozilla/5.0 (X11; Linux x86_64)"`,
+	`2013-06-01 12:00:07 INFO  [http-worker-1] GET /api/v1/users/977/orders?page=1 200 OK 29ms user-agent="Mozilla/5.0 (X11; Linux x86_64)"`,
+	`2013-06-01 12:00:09 ERROR [db-pool-2] Connection to postgres://db3.internal:5432/orders refused after 3 retries; failing over to replica`,
+}
+
+func Test_Distance(t *testing.T) {
+	for _, c := range []Compressor{Flate, Gzip, Zlib} {
+		similar := Distance(logLines[0], logLines[1], c)
+		different := Distance(logLines[0], logLines[2], c)
+		same := Distance(logLines[0], logLines[0], c)
+		assert.True(t, same < similar, "%v: %v < %v", c, same, similar)
+		assert.True(t, similar < different, "%v: %v < %v", c, similar, different)
+		assert.True(t, same >= 0 && different <= 1.2, c.String())
+		assert.InDelta(t, similar, Distance(logLines[1], logLines[0], c), 0.05, "Nearly symmetric.")
+	}
+	assert.Equal(t, "zlib", Zlib.String())
+	assert.Panics(t, func() { Compressor(7).CompressedLength("a") })
+}
+
+// logText returns n bytes of log lines, varied enough that they
+// compress only a few times over.
+func logText(r *rand.Rand, n int) string {
+	words := strings.Fields("GET POST PUT /api/v1/users /api/v1/orders page=1 page=2 200 OK 404 500 INFO WARN ERROR db-pool-2 http-worker-3 retry failover replica refused timeout")
+	var b strings.Builder
+	for b.Len() < n {
+		fmt.Fprintf(&b, "2013-06-01 12:%02d:%02d %s %s/%d %s %dms\n",
+			r.Intn(60), r.Intn(60), words[r.Intn(len(words))], words[r.Intn(len(words))], r.Intn(10000), words[r.Intn(len(words))], r.Intn(500))
+	}
+	return b.String()[:n]
+}
+
+func Test_Distance_BeyondWindow(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{20000, 40000, 100000} {
+		s, other := logText(r, n), logText(r, n)
+		edited := s[:n/3] + logText(r, 500) + s[n/3:]
+		for _, c := range []Compressor{Flate, Gzip, Zlib} {
+			same := Distance(s, s, c)
+			assert.True(t, same < 0.1, "%v, %d bytes: %v", c, n, same)
+			near := Distance(s, edited, c)
+			assert.True(t, near < 0.15, "%v, %d bytes: %v", c, n, near)
+			far := Distance(s, other, c)
+			assert.True(t, far > 0.8, "%v, %d bytes: %v", c, n, far)
+		}
+	}
+
+	// Strings within a block are compressed one after the other.
+	a, b := logText(r, 10000), logText(r, 30000)
+	assert.Equal(t, Flate.CompressedLength(a, b), Flate.pairLength(a, b))
+}
+
+func Test_CompressedLength(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	w.Write([]byte(logLines[0] + logLines[1]))
+	w.Close()
+	assert.Equal(t, buf.Len(), Gzip.CompressedLength(logLines[0], logLines[1]))
+	assert.Equal(t, Gzip.CompressedLength(logLines[0]+logLines[1]), Gzip.CompressedLength(logLines[0], logLines[1]))
+
+	assert.Equal(t, Flate.CompressedLength("")+18, Gzip.CompressedLength(""))
+	assert.Equal(t, Flate.CompressedLength("")+6, Zlib.CompressedLength(""))
+	repeated := strings.Repeat(logLines[2], 20)
+	assert.True(t, Flate.CompressedLength(repeated) < 2*Flate.CompressedLength(logLines[2]))
+}
+
+func Test_Cache(t *testing.T) {
+	c := NewCache(Zlib, 0)
+	assert.Equal(t, Zlib, c.Compressor())
+	var d metric.Distance = c
+	for _, a := range logLines {
+		for _, b := range logLines {
+			score, err := d.Distance(a, b)
+			assert.Nil(t, err)
+			assert.Equal(t, Distance(a, b, Zlib), score)
+		}
+	}
+	assert.Equal(t, len(logLines), c.Len())
+
+	small := NewCache(Flate, 2)
+	for _, line := range logLines {
+		small.CompressedLength(line)
+		assert.True(t, small.Len() <= 2)
+	}
+	query := "a query compared with each line"
+	for _, line := range logLines {
+		small.Distance(query, line)
+		_, ok := small.lengths[query]
+		assert.True(t, ok, "The query is used most recently, and so is kept.")
+		assert.Equal(t, 2, small.Len())
+	}
+	small.CompressedLength(logLines[0])
+	small.CompressedLength(logLines[1])
+	small.CompressedLength(logLines[0])
+	small.CompressedLength(logLines[2])
+	_, ok := small.lengths[logLines[0]]
+	assert.True(t, ok)
+	_, ok = small.lengths[logLines[1]]
+	assert.False(t, ok, "The least recently used string is forgotten.")
+	assert.Panics(t, func() { NewCache(Compressor(-1), 0) })
+
+	var wg sync.WaitGroup
+	shared := NewCache(Flate, 0)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				line := fmt.Sprintf("%s %d", logLines[g%len(logLines)], i%5)
+				assert.Equal(t, Flate.CompressedLength(line), shared.CompressedLength(line))
+			}
+		}(g)
+	}
+	wg.Wait()
+	assert.Equal(t, 15, shared.Len())
+}
+
+func Benchmark_Cache_Distance(b *testing.B) {
+	c := NewCache(Flate, 0)
+	for i := 0; i < b.N; i++ {
+		c.Distance(logLines[0], logLines[i%len(logLines)])
+	}
+}
@@ -0,0 +1,208 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+/*
+Package stralgo/pairwise implements the parallel computation of
+the scores between every pair of a set of items, as needed to
+cluster them.
+
+The scores are stored in a condensed matrix, which holds only
+the upper triangle of the full symmetric matrix, and so needs
+half the memory:
+
+	names := [][]rune{[]rune("Jon"), []rune("John"), []rune("Joan")}
+	m, err := pairwise.Compute(ctx, names, runewise.LevenshteinDistance, nil)
+	if err != nil {
+		return err
+	}
+	d := m.At(0, 2) // The distance between "Jon" and "Joan".
+
+Any metric of bytewise, runewise or graphemewise may be used,
+adapting those which cannot fail with metric.NoError, as may the
+Distance and Similarity methods of the metric package.
+
+See: http://docs.scipy.org/doc/scipy/reference/generated/scipy.spatial.distance.pdist.html
+*/
+package pairwise
+
+import (
+	"context"
+	"fmt"
+	"github.com/ZackPierce/stralgo/metric"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Matrix is a condensed matrix of the scores between every pair
+// of a set of items.
+type Matrix[N metric.Number] struct {
+	// Size is the number of items scored.
+	Size int
+	// Values holds the score of each pair of items i and j, with
+	// i < j, in row-major order: (0, 1), (0, 2), ..., (0, Size-1),
+	// (1, 2), and so on, to (Size-2, Size-1).
+	Values []N
+}
+
+// Index returns the position in m.Values of the score between
+// items i and j, in either order. Index panics if i and j are
+// equal, or either is out of range, as the matrix holds no
+// scores between an item and itself.
+func (m *Matrix[N]) Index(i, j int) int {
+	if i > j {
+		i, j = j, i
+	}
+	if i == j || i < 0 || j >= m.Size {
+		panic(fmt.Sprintf("pairwise: no score between items %d and %d of %d", i, j, m.Size))
+	}
+	return condensedIndex(m.Size, i, j)
+}
+
+// At returns the score between items i and j, in either order.
+// At panics if i and j are equal, or either is out of range.
+func (m *Matrix[N]) At(i, j int) N {
+	return m.Values[m.Index(i, j)]
+}
+
+// condensedIndex returns the position of the pair (i, j), with
+// i < j, in a condensed matrix of n items.
+func condensedIndex(n, i, j int) int {
+	return n*i - i*(i+1)/2 + j - i - 1
+}
+
+// Options configures Compute. The zero value, like a nil
+// *Options, selects the defaults.
+type Options struct {
+	// Workers is the number of goroutines used to score pairs.
+	// It defaults to runtime.GOMAXPROCS(0).
+	Workers int
+
+	// Progress, if not nil, is called each time a row of the
+	// matrix is complete, with the number of pairs scored so
+	// far and the total number of pairs. It is called by one
+	// goroutine at a time, with done increasing from call to
+	// call, and should return quickly, as it holds up the
+	// scoring of further rows.
+	Progress func(done, total int)
+}
+
+// PairError reports that the metric failed to score a pair of
+// items.
+type PairError struct {
+	I, J int   // The positions of the items.
+	Err  error // The error returned by the metric.
+}
+
+func (e *PairError) Error() string {
+	return fmt.Sprintf("Scoring items %d and %d failed: %v", e.I, e.J, e.Err)
+}
+
+// Unwrap returns the error returned by the metric.
+func (e *PairError) Unwrap() error {
+	return e.Err
+}
+
+// cancelCheckInterval is the number of pairs scored between
+// checks for the cancellation of the context.
+const cancelCheckInterval = 256
+
+// Compute scores every pair of items with the given metric,
+// which must be safe for concurrent use, as the package-level
+// metric functions of stralgo are. Each item is compared as the
+// first argument with every item after it, as the second.
+//
+// The rows of the matrix are shared among a pool of worker
+// goroutines; the result does not depend upon how many there
+// are, or the order in which they finish.
+//
+// If the metric fails for any pair, Compute returns a *PairError
+// for the first such pair in the order of m.Values, whatever the
+// number of workers. If ctx is cancelled before every pair has
+// been scored, Compute stops early and returns ctx.Err().
+func Compute[T any, N metric.Number](ctx context.Context, items []T, score func(a, b T) (N, error), options *Options) (*Matrix[N], error) {
+	n := len(items)
+	total := n * (n - 1) / 2
+	m := &Matrix[N]{Size: n, Values: make([]N, total, total)}
+	if n < 2 {
+		return m, nil
+	}
+	if options == nil {
+		options = &Options{}
+	}
+	workers := options.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, n-1)
+
+	var (
+		nextRow  atomic.Int64
+		stop     atomic.Bool
+		mu       sync.Mutex
+		done     int
+		pairErr  *PairError
+		canceled bool
+		wg       sync.WaitGroup
+	)
+	cancel := ctx.Done()
+	worker := func() {
+		defer wg.Done()
+		// Rows are taken in increasing order, so once stop is
+		// set, every row before the one that set it has been
+		// taken, and will be finished.
+		for !stop.Load() {
+			i := int(nextRow.Add(1)) - 1
+			if i >= n-1 {
+				return
+			}
+			start := condensedIndex(n, i, i+1)
+			row := m.Values[start : start+n-1-i]
+			for j := i + 1; j < n; j++ {
+				if (j-i)%cancelCheckInterval == 0 || j == i+1 {
+					select {
+					case <-cancel:
+						mu.Lock()
+						canceled = true
+						mu.Unlock()
+						stop.Store(true)
+						return
+					default:
+					}
+				}
+				s, err := score(items[i], items[j])
+				if err != nil {
+					mu.Lock()
+					if pairErr == nil || i < pairErr.I {
+						pairErr = &PairError{I: i, J: j, Err: err}
+					}
+					mu.Unlock()
+					stop.Store(true)
+					return
+				}
+				row[j-i-1] = s
+			}
+			mu.Lock()
+			done += len(row)
+			if options.Progress != nil {
+				options.Progress(done, total)
+			}
+			mu.Unlock()
+		}
+	}
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go worker()
+	}
+	wg.Wait()
+
+	if canceled {
+		return nil, ctx.Err()
+	}
+	if pairErr != nil {
+		return nil, pairErr
+	}
+	return m, nil
+}
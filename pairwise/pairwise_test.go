@@ -0,0 +1,138 @@
+﻿package pairwise
+
+import (
+	"context"
+	"errors"
+	"github.com/ZackPierce/stralgo"
+	"github.com/ZackPierce/stralgo/metric"
+	"github.com/ZackPierce/stralgo/runewise"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+)
+
+func randomWords(count int, seed int64) [][]rune {
+	r := rand.New(rand.NewSource(seed))
+	words := make([][]rune, count, count)
+	for i := range words {
+		words[i] = make([]rune, 1+r.Intn(8), 8)
+		for j := range words[i] {
+			words[i][j] = rune('a' + r.Intn(4))
+		}
+	}
+	return words
+}
+
+func Test_Matrix_Index(t *testing.T) {
+	m := &Matrix[int]{Size: 4, Values: []int{1, 2, 3, 4, 5, 6}}
+	positions := [][2]int{{0, 1}, {0, 2}, {0, 3}, {1, 2}, {1, 3}, {2, 3}}
+	for k, p := range positions {
+		assert.Equal(t, k, m.Index(p[0], p[1]))
+		assert.Equal(t, k, m.Index(p[1], p[0]))
+		assert.Equal(t, k+1, m.At(p[1], p[0]))
+	}
+	assert.Panics(t, func() { m.At(2, 2) })
+	assert.Panics(t, func() { m.At(-1, 2) })
+	assert.Panics(t, func() { m.At(1, 4) })
+}
+
+func Test_Compute(t *testing.T) {
+	words := randomWords(150, 1)
+	for _, workers := range []int{0, 1, 3, 16, 1000} {
+		m, err := Compute(context.Background(), words, runewise.LevenshteinDistance, &Options{Workers: workers})
+		assert.Nil(t, err)
+		assert.Equal(t, len(words), m.Size)
+		assert.Len(t, m.Values, len(words)*(len(words)-1)/2)
+		for i := range words {
+			for j := i + 1; j < len(words); j++ {
+				d, _ := runewise.LevenshteinDistance(words[i], words[j])
+				assert.Equal(t, d, m.At(i, j))
+			}
+		}
+	}
+
+	jw := metric.NoError(runewise.JaroWinklerSimilarity)
+	m, err := Compute(context.Background(), words[:3], jw, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, runewise.JaroWinklerSimilarity(words[1], words[2]), m.At(2, 1))
+
+	d, _ := metric.LookupDistance("levenshtein")
+	m2, err := Compute(context.Background(), []string{"kitten", "sitting", "mitten"}, d.Distance, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{3, 1, 3}, m2.Values)
+
+	for _, items := range [][]string{nil, {"alone"}} {
+		m2, err = Compute(context.Background(), items, d.Distance, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, len(items), m2.Size)
+		assert.Empty(t, m2.Values)
+	}
+}
+
+func Test_Compute_Progress(t *testing.T) {
+	words := randomWords(100, 2)
+	total := len(words) * (len(words) - 1) / 2
+	calls, last := 0, 0
+	_, err := Compute(context.Background(), words, runewise.DamerauLevenshteinDistance, &Options{
+		Workers: 4,
+		Progress: func(done, all int) {
+			calls++
+			assert.Equal(t, total, all)
+			assert.True(t, done > last)
+			last = done
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, len(words)-1, calls)
+	assert.Equal(t, total, last)
+}
+
+func Test_Compute_FirstPairError(t *testing.T) {
+	words := randomWords(200, 3)
+	for i := range words {
+		words[i] = words[i][:1]
+	}
+	words[40] = []rune("xy")
+	words[7] = []rune("yz")
+	for _, workers := range []int{1, 2, 8} {
+		_, err := Compute(context.Background(), words, runewise.HammingDistance, &Options{Workers: workers})
+		var pairErr *PairError
+		if assert.True(t, errors.As(err, &pairErr)) {
+			assert.Equal(t, 0, pairErr.I)
+			assert.Equal(t, 7, pairErr.J)
+		}
+		assert.True(t, errors.Is(err, stralgo.ErrUnequalLength))
+		assert.Equal(t, "Scoring items 0 and 7 failed: Hamming distance is undefined between inputs of unequal length, 1 and 2.", err.Error())
+	}
+}
+
+func Test_Compute_Cancel(t *testing.T) {
+	words := randomWords(2000, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+	var scored atomic.Int64
+	score := func(a, b []rune) (int, error) {
+		if scored.Add(1) == 1000 {
+			cancel()
+		}
+		return runewise.LevenshteinDistance(a, b)
+	}
+	m, err := Compute(ctx, words, score, &Options{Workers: 4})
+	assert.Nil(t, m)
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, scored.Load() < int64(len(words)*(len(words)-1)/2))
+}
+
+func Benchmark_Compute(b *testing.B) {
+	words := randomWords(500, 5)
+	for i := 0; i < b.N; i++ {
+		Compute(context.Background(), words, runewise.LevenshteinDistance, nil)
+	}
+}
+
+func Benchmark_Compute_OneWorker(b *testing.B) {
+	words := randomWords(500, 5)
+	for i := 0; i < b.N; i++ {
+		Compute(context.Background(), words, runewise.LevenshteinDistance, &Options{Workers: 1})
+	}
+}
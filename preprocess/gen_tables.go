@@ -0,0 +1,157 @@
+﻿//go:build ignore
+// +build ignore
+
+/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+// This program generates tables.go, the normalization and
+// case folding tables, from the Unicode data embedded in
+// golang.org/x/text, which is needed only to run this program.
+//
+// Usage:
+//
+//	go run gen_tables.go
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"sort"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	hangulBase = 0xAC00
+	hangulEnd  = 0xD7A4
+)
+
+func main() {
+	canonical := make(map[rune]string)
+	compatibility := make(map[rune]string)
+	classes := make(map[rune]uint8)
+	folds := make(map[rune]string)
+	compositions := make(map[[2]rune]rune)
+	fold := cases.Fold()
+
+	for r := rune(0); r <= utf8.MaxRune; r++ {
+		if !utf8.ValidRune(r) {
+			continue
+		}
+		s := string(r)
+		if ccc := norm.NFD.PropertiesString(s).CCC(); ccc != 0 {
+			classes[r] = ccc
+		}
+		if f := caseFold(fold, r); f != s {
+			folds[r] = f
+		}
+		if hangulBase <= r && r < hangulEnd {
+			continue
+		}
+		d := norm.NFD.String(s)
+		if d != s {
+			canonical[r] = d
+		}
+		if kd := norm.NFKD.String(s); kd != d {
+			compatibility[r] = kd
+		}
+		// Primary composites are those which survive NFC intact
+		// and canonically decompose into more than one rune.
+		if d != s && norm.NFC.String(s) == s && utf8.RuneCountInString(d) > 1 {
+			last, size := utf8.DecodeLastRuneInString(d)
+			first := []rune(norm.NFC.String(d[:len(d)-size]))
+			if len(first) != 1 || norm.NFC.String(string(first[0])+string(last)) != s {
+				log.Fatalf("cannot derive the composition pair for %U", r)
+			}
+			compositions[[2]rune{first[0], last}] = r
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "// Code generated by gen_tables.go. DO NOT EDIT.")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "package preprocess")
+	fmt.Fprintln(&buf)
+	fmt.Fprintf(&buf, "// unicodeVersion is the Unicode version the tables were derived from.\n")
+	fmt.Fprintf(&buf, "const unicodeVersion = %q\n\n", norm.Version)
+	writeStrings(&buf, "canonicalDecompositions holds the full canonical decomposition\n// of every rune which has one, other than the Hangul syllables.", "canonicalDecompositions", canonical)
+	writeStrings(&buf, "compatibilityDecompositions holds the full compatibility\n// decomposition of every rune for which it differs from the\n// canonical decomposition.", "compatibilityDecompositions", compatibility)
+	writeStrings(&buf, "caseFolds holds the full case folding of every rune\n// which is changed by it.", "caseFolds", folds)
+
+	fmt.Fprintln(&buf, "// combiningClasses holds every non-zero canonical combining class.")
+	fmt.Fprintln(&buf, "var combiningClasses = map[rune]uint8{")
+	for _, r := range sortedKeys(classes) {
+		fmt.Fprintf(&buf, "\t0x%04X: %d,\n", r, classes[r])
+	}
+	fmt.Fprintln(&buf, "}")
+	fmt.Fprintln(&buf)
+
+	fmt.Fprintln(&buf, "// compositions maps the canonical decomposition pair of every")
+	fmt.Fprintln(&buf, "// primary composite, other than the Hangul syllables, to it.")
+	fmt.Fprintln(&buf, "var compositions = map[[2]rune]rune{")
+	pairs := make([][2]rune, 0, len(compositions))
+	for p := range compositions {
+		pairs = append(pairs, p)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	for _, p := range pairs {
+		fmt.Fprintf(&buf, "\t{0x%04X, 0x%04X}: 0x%04X,\n", p[0], p[1], compositions[p])
+	}
+	fmt.Fprintln(&buf, "}")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile("tables.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// caseFold returns the full case folding of r. Per rune, x/text
+// maps each Cherokee letter to its other case, so that the two
+// cases never become equal; CaseFolding.txt instead folds both to
+// the uppercase letters, which were encoded first.
+func caseFold(fold cases.Caser, r rune) string {
+	switch {
+	case 0x13A0 <= r && r <= 0x13F5:
+		return string(r)
+	case 0x13F8 <= r && r <= 0x13FD:
+		return string(r - 8)
+	case 0xAB70 <= r && r <= 0xABBF:
+		return string(r - 0xAB70 + 0x13A0)
+	}
+	return fold.String(string(r))
+}
+
+func writeStrings(buf *bytes.Buffer, doc, name string, m map[rune]string) {
+	fmt.Fprintf(buf, "// %s\n", doc)
+	fmt.Fprintf(buf, "var %s = map[rune]string{\n", name)
+	for _, r := range sortedKeys(m) {
+		fmt.Fprintf(buf, "\t0x%04X: %+q,\n", r, m[r])
+	}
+	fmt.Fprintln(buf, "}")
+	fmt.Fprintln(buf)
+}
+
+func sortedKeys[V any](m map[rune]V) []rune {
+	keys := make([]rune, 0, len(m))
+	for r := range m {
+		keys = append(keys, r)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
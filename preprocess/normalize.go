@@ -0,0 +1,147 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package preprocess
+
+//go:generate go run gen_tables.go
+
+// Hangul syllables are decomposed and composed algorithmically,
+// rather than through the tables.
+//
+// See: http://www.unicode.org/versions/Unicode15.0.0/ch03.pdf#G56669
+const (
+	hangulSBase  = 0xAC00
+	hangulLBase  = 0x1100
+	hangulVBase  = 0x1161
+	hangulTBase  = 0x11A7
+	hangulLCount = 19
+	hangulVCount = 21
+	hangulTCount = 28
+	hangulNCount = hangulVCount * hangulTCount
+	hangulSCount = hangulLCount * hangulNCount
+)
+
+// NFD converts s to Normalization Form D, canonical decomposition.
+//
+// See: http://www.unicode.org/reports/tr15/
+func NFD(s []rune) []rune {
+	return decompose(s, false)
+}
+
+// NFKD converts s to Normalization Form KD, compatibility
+// decomposition, which also replaces compatibility characters
+// such as ligatures, full-width forms and superscripts with
+// their plain equivalents.
+//
+// See: http://www.unicode.org/reports/tr15/
+func NFKD(s []rune) []rune {
+	return decompose(s, true)
+}
+
+// NFC converts s to Normalization Form C, canonical decomposition
+// followed by canonical composition. This is the form most
+// text is exchanged in.
+//
+// See: http://www.unicode.org/reports/tr15/
+func NFC(s []rune) []rune {
+	return compose(decompose(s, false))
+}
+
+// NFKC converts s to Normalization Form KC, compatibility
+// decomposition followed by canonical composition.
+//
+// See: http://www.unicode.org/reports/tr15/
+func NFKC(s []rune) []rune {
+	return compose(decompose(s, true))
+}
+
+func combiningClass(r rune) uint8 {
+	return combiningClasses[r]
+}
+
+func decompose(s []rune, compatibility bool) []rune {
+	d := make([]rune, 0, len(s))
+	for _, r := range s {
+		if hangulSBase <= r && r < hangulSBase+hangulSCount {
+			i := r - hangulSBase
+			d = append(d, hangulLBase+i/hangulNCount, hangulVBase+(i%hangulNCount)/hangulTCount)
+			if t := i % hangulTCount; t != 0 {
+				d = append(d, hangulTBase+t)
+			}
+			continue
+		}
+		if compatibility {
+			if m, ok := compatibilityDecompositions[r]; ok {
+				d = append(d, []rune(m)...)
+				continue
+			}
+		}
+		if m, ok := canonicalDecompositions[r]; ok {
+			d = append(d, []rune(m)...)
+			continue
+		}
+		d = append(d, r)
+	}
+	// Apply the canonical ordering algorithm, a stable sort of
+	// each run of non-starters by their combining class.
+	for i := 1; i < len(d); i++ {
+		c := combiningClass(d[i])
+		if c == 0 {
+			continue
+		}
+		for j := i; j > 0; j-- {
+			p := combiningClass(d[j-1])
+			if p <= c {
+				break
+			}
+			d[j-1], d[j] = d[j], d[j-1]
+		}
+	}
+	return d
+}
+
+// compose applies the canonical composition algorithm to s,
+// which must already be canonically ordered. s is composed
+// in place.
+func compose(s []rune) []rune {
+	composed := s[:0]
+	starter := -1
+	var last uint8
+	for _, r := range s {
+		c := combiningClass(r)
+		if starter >= 0 {
+			// r is blocked from the starter unless it directly follows
+			// it, or every rune between them is a non-starter of a
+			// lower combining class.
+			adjacent := starter == len(composed)-1
+			if adjacent || (last != 0 && last < c) {
+				if p, ok := composePair(composed[starter], r); ok {
+					composed[starter] = p
+					continue
+				}
+			}
+		}
+		if c == 0 {
+			starter = len(composed)
+		}
+		last = c
+		composed = append(composed, r)
+	}
+	return composed
+}
+
+func composePair(a, b rune) (rune, bool) {
+	if hangulLBase <= a && a < hangulLBase+hangulLCount &&
+		hangulVBase <= b && b < hangulVBase+hangulVCount {
+		return hangulSBase + ((a-hangulLBase)*hangulVCount+b-hangulVBase)*hangulTCount, true
+	}
+	if hangulSBase <= a && a < hangulSBase+hangulSCount && (a-hangulSBase)%hangulTCount == 0 &&
+		hangulTBase < b && b < hangulTBase+hangulTCount {
+		return a + b - hangulTBase, true
+	}
+	p, ok := compositions[[2]rune{a, b}]
+	return p, ok
+}
@@ -0,0 +1,138 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+/*
+Package stralgo/preprocess implements composable text
+transformations to be applied before the similarity metrics,
+so that inputs which a reader would consider equal are also
+scored as equal.
+
+The runewise metrics compare runes exactly, so "café" with a
+precomposed 'é' and "café" written as 'e' followed by a
+combining acute accent are one edit apart. Normalizing both
+inputs to the same form first, and optionally folding case,
+stripping diacritics and collapsing whitespace, removes such
+spurious differences:
+
+	clean := preprocess.Chain(preprocess.NFC, preprocess.CaseFold)
+	distance := preprocess.WrapWithError(runewise.LevenshteinDistance, clean)
+	d, err := distance(a, b)
+
+Names written in Greek, Cyrillic or accented Latin can also be
+transliterated to ASCII with ToASCII, and then compared with the
+bytewise metrics.
+
+The normalization and case folding tables are generated from
+Unicode 15.0.0.
+*/
+package preprocess
+
+import (
+	"unicode"
+)
+
+// Transform rewrites a sequence of runes.
+//
+// A Transform may reuse the storage of its input, so callers
+// must not rely upon the input being unchanged once it has
+// been transformed. The transforms provided by this package
+// leave their input untouched.
+type Transform func(s []rune) []rune
+
+// Chain combines transforms into a single Transform which
+// applies each of them in turn, from first to last.
+func Chain(transforms ...Transform) Transform {
+	return func(s []rune) []rune {
+		for _, t := range transforms {
+			s = t(s)
+		}
+		return s
+	}
+}
+
+// Apply applies the transform to a string.
+func (t Transform) Apply(s string) string {
+	return string(t([]rune(s)))
+}
+
+// Text is the set of input types accepted by the metrics in
+// stralgo, strings for bytewise and rune slices for runewise.
+type Text interface {
+	~string | ~[]rune
+}
+
+// Wrap returns a metric which applies t to both of its inputs
+// before scoring them with metric.
+func Wrap[S Text, R any](metric func(a, b S) R, t Transform) func(a, b S) R {
+	return func(a, b S) R {
+		return metric(S(t([]rune(a))), S(t([]rune(b))))
+	}
+}
+
+// WrapWithError is the equivalent of Wrap for metrics which
+// may also return an error.
+func WrapWithError[S Text, R any](metric func(a, b S) (R, error), t Transform) func(a, b S) (R, error) {
+	return func(a, b S) (R, error) {
+		return metric(S(t([]rune(a))), S(t([]rune(b))))
+	}
+}
+
+// CaseFold applies full Unicode case folding to s, under which
+// strings differing only in case become identical. Unlike
+// upper-casing each rune, full folding also maps characters
+// such as 'ß' to "ss", and 'ﬁ' to "fi".
+//
+// Case folding does not preserve normalization, so it is best
+// followed by one of the normalization transforms.
+//
+// See: http://www.unicode.org/versions/Unicode15.0.0/ch05.pdf#G21790
+func CaseFold(s []rune) []rune {
+	folded := make([]rune, 0, len(s))
+	for _, r := range s {
+		if f, ok := caseFolds[r]; ok {
+			folded = append(folded, []rune(f)...)
+		} else {
+			folded = append(folded, r)
+		}
+	}
+	return folded
+}
+
+// StripDiacritics removes the accents and other nonspacing marks
+// from s, so that "Crème Brûlée" becomes "Creme Brulee". The
+// result is in Normalization Form C.
+//
+// Only marks that are separated by canonical decomposition are
+// removed, so letters such as 'ø' and 'ł' are left unchanged.
+func StripDiacritics(s []rune) []rune {
+	d := NFD(s)
+	stripped := d[:0]
+	for _, r := range d {
+		if !unicode.Is(unicode.Mn, r) {
+			stripped = append(stripped, r)
+		}
+	}
+	return compose(stripped)
+}
+
+// CollapseWhitespace trims leading and trailing whitespace from
+// s and replaces each remaining run of whitespace with a single
+// space.
+func CollapseWhitespace(s []rune) []rune {
+	collapsed := make([]rune, 0, len(s))
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			space = len(collapsed) > 0
+			continue
+		}
+		if space {
+			collapsed = append(collapsed, ' ')
+			space = false
+		}
+		collapsed = append(collapsed, r)
+	}
+	return collapsed
+}
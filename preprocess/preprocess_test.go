@@ -0,0 +1,106 @@
+﻿package preprocess
+
+import (
+	"github.com/ZackPierce/stralgo/bytewise"
+	"github.com/ZackPierce/stralgo/runewise"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"unicode"
+)
+
+func apply(t Transform, s string) string {
+	return t.Apply(s)
+}
+
+func Test_NFC(t *testing.T) {
+	assert.Equal(t, "caf\u00e9", apply(NFC, "cafe\u0301"))
+	assert.Equal(t, "caf\u00e9", apply(NFC, "caf\u00e9"))
+	assert.Equal(t, "\u1e69", apply(NFC, "s\u0323\u0307"), "Marks are reordered before composing.")
+	assert.Equal(t, "\u1e69", apply(NFC, "s\u0307\u0323"))
+	assert.Equal(t, "\u0105\u0301", apply(NFC, "a\u0328\u0301"), "There is no precomposed a with ogonek and acute.")
+	assert.Equal(t, "\ud55c\uae00", apply(NFC, "\u1112\u1161\u11ab\u1100\u1173\u11af"), "Hangul jamo compose to syllables.")
+	assert.Equal(t, "\u03a9", apply(NFC, "\u2126"), "Singletons are not recomposed.")
+	assert.Equal(t, "\u0915\u093c", apply(NFC, "\u0958"), "Composition exclusions are not recomposed.")
+	assert.Equal(t, "\ufb01", apply(NFC, "\ufb01"))
+	assert.Equal(t, "", apply(NFC, ""))
+}
+
+func Test_NFD(t *testing.T) {
+	assert.Equal(t, "cafe\u0301", apply(NFD, "caf\u00e9"))
+	assert.Equal(t, "s\u0323\u0307", apply(NFD, "\u1e69"))
+	assert.Equal(t, "s\u0323\u0307", apply(NFD, "s\u0307\u0323"), "Marks are canonically ordered.")
+	assert.Equal(t, "a\u0301\u0302", apply(NFD, "a\u0301\u0302"), "Marks of equal class keep their order.")
+	assert.Equal(t, "\u1112\u1161\u11ab", apply(NFD, "\ud55c"))
+	assert.Equal(t, "\ufb01", apply(NFD, "\ufb01"))
+}
+
+func Test_NFKC_NFKD(t *testing.T) {
+	assert.Equal(t, "fi", apply(NFKC, "\ufb01"))
+	assert.Equal(t, "ABC 123", apply(NFKC, "\uff21\uff22\uff23\u3000\uff11\uff12\uff13"))
+	assert.Equal(t, "x2", apply(NFKC, "x\u00b2"))
+	assert.Equal(t, "\u1e69", apply(NFKC, "\u1e9b\u0323"))
+	assert.Equal(t, "s\u0323\u0307", apply(NFKD, "\u1e9b\u0323"))
+	assert.Equal(t, "1\u20444", apply(NFKD, "\u00bc"))
+}
+
+func Test_CaseFold(t *testing.T) {
+	assert.Equal(t, "strasse", apply(CaseFold, "STRASSE"))
+	assert.Equal(t, "strasse", apply(CaseFold, "Stra\u00dfe"))
+	assert.Equal(t, "\u03c3\u03bf\u03c6\u03bf\u03c3", apply(CaseFold, "\u03a3\u03bf\u03c6\u03bf\u03c2"), "Final sigma folds to sigma.")
+	assert.Equal(t, "fi", apply(CaseFold, "\ufb01"))
+	assert.Equal(t, "i\u0307", apply(CaseFold, "\u0130"))
+	assert.Equal(t, "\u65e5\u672c", apply(CaseFold, "\u65e5\u672c"))
+}
+
+func Test_CaseFold_Cherokee(t *testing.T) {
+	assert.Equal(t, "\u13a0", apply(CaseFold, "\u13a0"), "Cherokee folds to uppercase.")
+	assert.Equal(t, "\u13a0", apply(CaseFold, "\uab70"))
+	assert.Equal(t, "\u13f0", apply(CaseFold, "\u13f8"))
+	for upper := rune(0x13A0); upper <= 0x13F5; upper++ {
+		lower := unicode.ToLower(upper)
+		assert.NotEqual(t, upper, lower)
+		assert.Equal(t, apply(CaseFold, string(upper)), apply(CaseFold, string(lower)), "%U", upper)
+	}
+}
+
+func Test_StripDiacritics(t *testing.T) {
+	assert.Equal(t, "Creme Brulee", apply(StripDiacritics, "Cr\u00e8me Br\u00fbl\u00e9e"))
+	assert.Equal(t, "Creme Brulee", apply(StripDiacritics, "Cre\u0300me Bru\u0302le\u0301e"))
+	assert.Equal(t, "\u00f8\u0142", apply(StripDiacritics, "\u00f8\u0142"))
+	assert.Equal(t, "\ud55c\uae00", apply(StripDiacritics, "\ud55c\uae00"), "Hangul syllables are not stripped.")
+}
+
+func Test_CollapseWhitespace(t *testing.T) {
+	assert.Equal(t, "a b c", apply(CollapseWhitespace, "  a \t\n b\u00a0\u2003c \r\n"))
+	assert.Equal(t, "", apply(CollapseWhitespace, " \t "))
+	assert.Equal(t, "abc", apply(CollapseWhitespace, "abc"))
+}
+
+func Test_Chain(t *testing.T) {
+	clean := Chain(CaseFold, StripDiacritics, CollapseWhitespace)
+	assert.Equal(t, "creme brulee", clean.Apply("  CR\u00c8ME   Bru\u0302le\u0301e "))
+	assert.Equal(t, "abc", Chain().Apply("abc"))
+
+	input := []rune("Stra\u00dfe")
+	Chain(NFD, CaseFold, NFC)(input)
+	assert.Equal(t, "Stra\u00dfe", string(input), "The input is left unchanged.")
+}
+
+func Test_Wrap(t *testing.T) {
+	d, err := runewise.LevenshteinDistance([]rune("caf\u00e9"), []rune("cafe\u0301"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, d)
+
+	distance := WrapWithError(runewise.LevenshteinDistance, NFC)
+	d, err = distance([]rune("caf\u00e9"), []rune("cafe\u0301"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, d)
+
+	similarity := Wrap(runewise.JaroWinklerSimilarity, Chain(CaseFold, StripDiacritics))
+	assert.Equal(t, 1.0, similarity([]rune("M\u00dcLLER"), []rune("mu\u0308ller")))
+
+	hamming := WrapWithError(bytewise.HammingDistance, Chain(NFC, CaseFold))
+	h, err := hamming("CAFE\u0301", "caf\u00e9")
+	assert.Nil(t, err)
+	assert.Equal(t, uint(0), h)
+}
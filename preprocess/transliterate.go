@@ -0,0 +1,302 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package preprocess
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ToASCII transliterates Greek, Cyrillic and Latin text to ASCII,
+// by applying GreekLatin, CyrillicLatin and LatinASCII in turn,
+// so that names written in any of those scripts can be compared
+// with one another, and with ASCII records, using the bytewise
+// metrics.
+//
+// Runes of other scripts, which cannot be transliterated, are
+// left unchanged.
+var ToASCII = Chain(GreekLatin, CyrillicLatin, LatinASCII)
+
+// latinASCII holds the ASCII replacements for the Latin letters and
+// punctuation which do not reduce to ASCII through decomposition.
+var latinASCII = map[rune]string{
+	'Æ': "AE", 'æ': "ae", 'Ð': "D", 'ð': "d", 'Ø': "O", 'ø': "o",
+	'Þ': "TH", 'þ': "th", 'ß': "ss", 'ẞ': "SS", 'Đ': "D", 'đ': "d",
+	'Ħ': "H", 'ħ': "h", 'ı': "i", 'ĸ': "q", 'Ŀ': "L", 'ŀ': "l",
+	'Ł': "L", 'ł': "l", 'Ŋ': "NG", 'ŋ': "ng", 'Œ': "OE", 'œ': "oe",
+	'Ŧ': "T", 'ŧ': "t", 'ƀ': "b", 'Ɓ': "B", 'Ƈ': "C", 'ƈ': "c",
+	'Ɖ': "D", 'Ɗ': "D", 'ƌ': "d", 'Ƒ': "F", 'ƒ': "f", 'Ɠ': "G",
+	'Ɨ': "I", 'Ƙ': "K", 'ƙ': "k", 'ƚ': "l", 'Ɲ': "N", 'ƞ': "n",
+	'Ƥ': "P", 'ƥ': "p", 'ƫ': "t", 'Ƭ': "T", 'ƭ': "t", 'Ʈ': "T",
+	'Ʋ': "V", 'Ƴ': "Y", 'ƴ': "y", 'Ƶ': "Z", 'ƶ': "z", 'Ǥ': "G",
+	'ǥ': "g", 'ȡ': "d", 'Ȥ': "Z", 'ȥ': "z", 'ȴ': "l", 'ȵ': "n",
+	'ȶ': "t", 'ȷ': "j", 'ȸ': "db", 'ȹ': "qp", 'Ⱥ': "A", 'Ȼ': "C",
+	'ȼ': "c", 'Ƚ': "L", 'Ⱦ': "T", 'ȿ': "s", 'ɀ': "z", 'Ƀ': "B",
+	'Ʉ': "U", 'Ɇ': "E", 'ɇ': "e", 'Ɉ': "J", 'ɉ': "j", 'Ɍ': "R",
+	'ɍ': "r", 'Ɏ': "Y", 'ɏ': "y", 'ɓ': "b", 'ɕ': "c", 'ɖ': "d",
+	'ɗ': "d", 'ɛ': "e", 'ɟ': "j", 'ɠ': "g", 'ɡ': "g", 'ɢ': "G",
+	'ɦ': "h", 'ɧ': "h", 'ɨ': "i", 'ɪ': "I", 'ɫ': "l", 'ɬ': "l",
+	'ɭ': "l", 'ɱ': "m", 'ɲ': "n", 'ɳ': "n", 'ɴ': "N", 'ɶ': "OE",
+	'ɼ': "r", 'ɽ': "r", 'ɾ': "r", 'ʀ': "R", 'ʂ': "s", 'ʈ': "t",
+	'ʉ': "u", 'ʋ': "v", 'ʏ': "Y", 'ʐ': "z", 'ʑ': "z", 'ʙ': "B",
+	'ʛ': "G", 'ʜ': "H", 'ʝ': "j", 'ʟ': "L", 'ʠ': "q", 'ʣ': "dz",
+	'ʥ': "dz", 'ʦ': "ts", 'ʪ': "ls", 'ʫ': "lz", 'ᴀ': "A", 'ᴁ': "AE",
+	'ᴃ': "B", 'ᴄ': "C", 'ᴅ': "D", 'ᴆ': "D", 'ᴇ': "E", 'ᴊ': "J",
+	'ᴋ': "K", 'ᴌ': "L", 'ᴍ': "M", 'ᴏ': "O", 'ᴘ': "P", 'ᴛ': "T",
+	'ᴜ': "U", 'ᴠ': "V", 'ᴡ': "W", 'ᴢ': "Z",
+	'‐': "-", '‑': "-", '‒': "-", '–': "-", '—': "-", '―': "-",
+	'⁃': "-", '−': "-", '‘': "'", '’': "'", '‚': ",", '‛': "'",
+	'′': "'", 'ʹ': "'", 'ʼ': "'", 'ˈ': "'", '“': "\"", '”': "\"",
+	'„': ",,", '‟': "\"", '″': "\"", 'ʺ': "\"", '‹': "<", '›': ">",
+	'«': "<<", '»': ">>", '⁄': "/", '©': "(C)", '®': "(R)",
+}
+
+// LatinASCII reduces Latin letters and common punctuation to their
+// closest ASCII equivalents, in the manner of the ICU Latin-ASCII
+// transform: accents are removed, ligatures and letters such as
+// 'ß', 'ø' and 'ł' are spelled out or replaced, and typographic
+// quotes and dashes become their ASCII counterparts.
+//
+// Runes which have no ASCII equivalent are left unchanged.
+//
+// See: http://unicode.org/cldr/trac/browser/trunk/common/transforms/Latin-ASCII.xml
+func LatinASCII(s []rune) []rune {
+	s = NFC(s)
+	reduced := make([]rune, 0, len(s))
+	for _, r := range s {
+		if unicode.Is(unicode.Mn, r) && len(reduced) > 0 && reduced[len(reduced)-1] <= unicode.MaxASCII {
+			// A mark left upon a letter that has already been reduced.
+			continue
+		}
+		if a, ok := latinRune(r); ok {
+			reduced = append(reduced, []rune(a)...)
+		} else {
+			reduced = append(reduced, r)
+		}
+	}
+	return reduced
+}
+
+func latinRune(r rune) (string, bool) {
+	if r <= unicode.MaxASCII {
+		return string(r), true
+	}
+	if a, ok := latinASCII[r]; ok {
+		return a, true
+	}
+	d := NFKD([]rune{r})
+	if len(d) == 1 && d[0] == r {
+		return "", false
+	}
+	var b strings.Builder
+	for _, x := range d {
+		switch {
+		case unicode.Is(unicode.Mn, x):
+		case x <= unicode.MaxASCII:
+			b.WriteRune(x)
+		default:
+			a, ok := latinASCII[x]
+			if !ok {
+				return "", false
+			}
+			b.WriteString(a)
+		}
+	}
+	return b.String(), b.Len() > 0
+}
+
+// cyrillicLatin holds the BGN/PCGN romanization of each lower-case
+// Cyrillic letter, following the Russian system where a letter is
+// shared by several languages.
+var cyrillicLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e",
+	'ё': "ë", 'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k",
+	'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "ʺ", 'ы': "y", 'ь': "ʹ",
+	'э': "e", 'ю': "yu", 'я': "ya",
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "w", 'ђ': "đ",
+	'ј': "j", 'љ': "lj", 'њ': "nj", 'ћ': "ć", 'џ': "dž", 'ѕ': "dz",
+	'ѓ': "ǵ", 'ќ': "ḱ",
+}
+
+// CyrillicLatin romanizes Cyrillic text per the BGN/PCGN system,
+// so that "Хрущёв" becomes "Khrushchëv" and "Елена" becomes "Yelena".
+// Letters of languages other than Russian use the BGN/PCGN
+// romanization of their own language, but letters shared with
+// Russian are always romanized as in Russian.
+//
+// The romanization is not entirely ASCII; follow it with
+// LatinASCII, or use ToASCII, to reduce it further.
+//
+// See: http://en.wikipedia.org/wiki/BGN/PCGN_romanization_of_Russian
+func CyrillicLatin(s []rune) []rune {
+	return transliterate(s, cyrillicRule)
+}
+
+func cyrillicRule(s []rune, i int) (string, int, bool) {
+	r := s[i]
+	if r == 'е' || r == 'ё' {
+		// Е and Ё are iotated at the start of a word, and after
+		// a vowel, Й, Ъ or Ь.
+		if i == 0 || !unicode.IsLetter(s[i-1]) || strings.ContainsRune("аеёиоуыэюяіїєйъь", s[i-1]) {
+			return "y" + cyrillicLatin[r], 1, true
+		}
+	}
+	l, ok := cyrillicLatin[r]
+	return l, 1, ok
+}
+
+// greekLatin holds the ELOT 743 transcription of each lower-case
+// Greek letter when it is not part of a digraph.
+var greekLatin = map[rune]string{
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z",
+	'η': "i", 'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m",
+	'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s",
+	'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps",
+	'ω': "o", 'ϊ': "i", 'ϋ': "y",
+}
+
+// GreekLatin transcribes Greek text per ELOT 743 (ISO 843), so that
+// "Αθήνα" becomes "Athina" and "Ευάγγελος" becomes "Evangelos".
+// Accents and breathings are discarded; a diaeresis is honored
+// only in keeping its vowel apart from the one before it.
+//
+// See: http://en.wikipedia.org/wiki/Romanization_of_Greek
+func GreekLatin(s []rune) []rune {
+	return transliterate(stripGreekMarks(s), greekRule)
+}
+
+func isGreek(r rune) bool {
+	return (0x0370 <= r && r <= 0x03FF) || (0x1F00 <= r && r <= 0x1FFF)
+}
+
+// stripGreekMarks removes the diacritics from Greek letters,
+// other than a diaeresis upon an iota or upsilon, which is
+// recomposed with its letter.
+func stripGreekMarks(s []rune) []rune {
+	stripped := make([]rune, 0, len(s))
+	greek := false
+	for _, r := range s {
+		var d []rune
+		switch {
+		case isGreek(r):
+			d = NFD([]rune{r})
+			greek = true
+		case greek && unicode.Is(unicode.Mn, r):
+			d = []rune{r}
+		default:
+			stripped = append(stripped, r)
+			greek = false
+			continue
+		}
+		for _, x := range d {
+			if !unicode.Is(unicode.Mn, x) {
+				stripped = append(stripped, x)
+			} else if x == 0x0308 && len(stripped) > 0 {
+				last := &stripped[len(stripped)-1]
+				switch *last {
+				case 'ι', 'Ι', 'υ', 'Υ':
+					*last = NFC([]rune{*last, x})[0]
+				}
+			}
+		}
+	}
+	return stripped
+}
+
+func greekRule(s []rune, i int) (string, int, bool) {
+	r := s[i]
+	next := at(s, i+1)
+	switch r {
+	case 'γ':
+		switch next {
+		case 'γ':
+			return "ng", 2, true
+		case 'ξ':
+			return "nx", 2, true
+		case 'χ':
+			return "nch", 2, true
+		}
+	case 'μ':
+		// ΜΠ is B at either end of a word, and MP within it.
+		if next == 'π' && (!isGreekLetter(at(s, i-1)) || !isGreekLetter(at(s, i+2))) {
+			return "b", 2, true
+		}
+	case 'ο':
+		if next == 'υ' {
+			return "ou", 2, true
+		}
+	case 'α', 'ε', 'η':
+		// ΑΥ, ΕΥ and ΗΥ are AV, EV and IV before a vowel or a
+		// voiced consonant, and AF, EF and IF otherwise.
+		if next == 'υ' {
+			v := "f"
+			if strings.ContainsRune("αεηιουωϊϋβγδζλμνρ", at(s, i+2)) {
+				v = "v"
+			}
+			return greekLatin[r] + v, 2, true
+		}
+	}
+	l, ok := greekLatin[r]
+	return l, 1, ok
+}
+
+func isGreekLetter(r rune) bool {
+	_, ok := greekLatin[r]
+	return ok
+}
+
+func at(s []rune, i int) rune {
+	if i < 0 || i >= len(s) {
+		return -1
+	}
+	return s[i]
+}
+
+// transliterate replaces the letters of s using rule, which is
+// given s in lower case and reports the lower-case replacement
+// for the runes at i, along with how many runes it replaces.
+//
+// The case of the source letters is carried over: an upper-case
+// letter becomes wholly upper case when it is part of an
+// upper-case word, and title case otherwise.
+func transliterate(s []rune, rule func(s []rune, i int) (string, int, bool)) []rune {
+	lower := make([]rune, len(s), len(s))
+	for i, r := range s {
+		lower[i] = unicode.ToLower(r)
+	}
+	result := make([]rune, 0, len(s))
+	for i := 0; i < len(s); {
+		l, n, ok := rule(lower, i)
+		if !ok {
+			result = append(result, s[i])
+			i++
+			continue
+		}
+		out := []rune(l)
+		if unicode.IsUpper(s[i]) && len(out) > 0 {
+			if isUpperWord(s, i, n) {
+				for j, r := range out {
+					out[j] = unicode.ToUpper(r)
+				}
+			} else {
+				out[0] = unicode.ToUpper(out[0])
+			}
+		}
+		result = append(result, out...)
+		i += n
+	}
+	return result
+}
+
+func isUpperWord(s []rune, i, n int) bool {
+	if n > 1 || i+n < len(s) && unicode.IsLetter(s[i+n]) {
+		return unicode.IsUpper(s[i+n-1]) && (i+n >= len(s) || !unicode.IsLetter(s[i+n]) || unicode.IsUpper(s[i+n]))
+	}
+	return i > 0 && unicode.IsUpper(s[i-1])
+}
@@ -0,0 +1,48 @@
+﻿package preprocess
+
+import (
+	"github.com/ZackPierce/stralgo/bytewise"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_LatinASCII(t *testing.T) {
+	assert.Equal(t, "Creme Brulee", apply(LatinASCII, "Crème Brûlée"))
+	assert.Equal(t, "Creme Brulee", apply(LatinASCII, "Crème Brûlée"))
+	assert.Equal(t, "Lodz Strasse Oresund AEsir", apply(LatinASCII, "Łódź Straße Øresund Æsir"))
+	assert.Equal(t, "final ,,quotes\" - 1/2", apply(LatinASCII, "ﬁnal „quotes“ — ½"))
+	assert.Equal(t, "ABC", apply(LatinASCII, "ＡＢＣ"))
+	assert.Equal(t, "q", apply(LatinASCII, "q́"), "Marks upon reduced letters are dropped.")
+	assert.Equal(t, "東京 Москва", apply(LatinASCII, "東京 Москва"))
+}
+
+func Test_CyrillicLatin(t *testing.T) {
+	assert.Equal(t, "Khrushchëv", apply(CyrillicLatin, "Хрущёв"))
+	assert.Equal(t, "Yelena Dostoyevskiy", apply(CyrillicLatin, "Елена Достоевский"))
+	assert.Equal(t, "Podʺyezd Olʹga", apply(CyrillicLatin, "Подъезд Ольга"))
+	assert.Equal(t, "ZHENYA Zhanna SHCHI", apply(CyrillicLatin, "ЖЕНЯ Жанна ЩИ"))
+	assert.Equal(t, "Đoković", apply(CyrillicLatin, "Ђоковић"))
+	assert.Equal(t, "Athens", apply(CyrillicLatin, "Athens"))
+}
+
+func Test_GreekLatin(t *testing.T) {
+	assert.Equal(t, "Athina", apply(GreekLatin, "Αθήνα"))
+	assert.Equal(t, "Evangelos", apply(GreekLatin, "Ευάγγελος"))
+	assert.Equal(t, "Bampis", apply(GreekLatin, "Μπάμπης"), "ΜΠ is B only at either end of a word.")
+	assert.Equal(t, "EVROPI Lavrio Kafsimo", apply(GreekLatin, "ΕΥΡΩΠΗ Λαύριο Καύσιμο"))
+	assert.Equal(t, "Aypnia", apply(GreekLatin, "Αϋπνία"), "A diaeresis separates vowels.")
+	assert.Equal(t, "Ouranos CHARIS Charis", apply(GreekLatin, "Ουρανός ΧΑΡΗΣ Χάρης"))
+	assert.Equal(t, "Sokratis", apply(GreekLatin, "Σωκράτης"))
+}
+
+func Test_ToASCII(t *testing.T) {
+	assert.Equal(t, "Khrushchev", ToASCII.Apply("Хрущёв"))
+	assert.Equal(t, "Pod\"yezd Yel'tsin", ToASCII.Apply("Подъезд Ельцин"))
+	assert.Equal(t, "Dokovic", ToASCII.Apply("Ђоковић"))
+	assert.Equal(t, "Athina Moskva Lodz", ToASCII.Apply("Αθήνα Москва Łódź"))
+
+	distance := WrapWithError(bytewise.DamerauLevenshteinDistance, ToASCII)
+	d, err := distance("Ђоковић", "Djokovic")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, d)
+}
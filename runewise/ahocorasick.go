@@ -0,0 +1,187 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package runewise
+
+import (
+	"github.com/ZackPierce/stralgo"
+	"sort"
+	"unicode"
+)
+
+// PatternMatch describes an exact occurrence of one of
+// a set of patterns within a text.
+//
+// Pattern is the index of the matched pattern in the slice
+// the matcher was built from. Start and End are rune indices
+// into the searched text, such that text[Start:End] is the
+// matched pattern (or its case-insensitive equivalent).
+type PatternMatch struct {
+	Pattern int
+	Start   int
+	End     int
+}
+
+// AhoCorasick is a compiled multi-pattern matcher, built
+// with NewAhoCorasick, which finds exact occurrences of
+// any number of patterns in a single pass over a text.
+//
+// An AhoCorasick is immutable once built and may be shared
+// between goroutines.
+//
+// See: http://en.wikipedia.org/wiki/Aho-Corasick_string_matching_algorithm
+type AhoCorasick struct {
+	nodes           []acNode
+	patternLengths  []int
+	caseInsensitive bool
+}
+
+type acNode struct {
+	next map[rune]int
+	// fail is the node for the longest proper suffix of this
+	// node's path which is also a path in the trie.
+	fail int
+	// outputs holds the patterns which end exactly at this node.
+	outputs []int
+	// dict is the nearest node along the fail chain which has
+	// outputs, or -1 if there is none.
+	dict int
+}
+
+// NewAhoCorasick builds an AhoCorasick automaton over the
+// given patterns, using rune-level transitions.
+//
+// If caseInsensitive is true, both the patterns and the
+// searched texts are passed through the same per-rune
+// upper-case filter applied by WhiteSimilarity, so that
+// "Ünited" matches "üNITED".
+//
+// Returns stralgo.ErrEmptyPattern if any of the patterns is empty.
+func NewAhoCorasick(patterns [][]rune, caseInsensitive bool) (*AhoCorasick, error) {
+	ac := &AhoCorasick{
+		nodes:           []acNode{{next: make(map[rune]int), dict: -1}},
+		patternLengths:  make([]int, len(patterns), len(patterns)),
+		caseInsensitive: caseInsensitive,
+	}
+	for p, pattern := range patterns {
+		if len(pattern) == 0 {
+			return nil, stralgo.ErrEmptyPattern
+		}
+		ac.patternLengths[p] = len(pattern)
+		n := 0
+		for _, r := range pattern {
+			r = ac.fold(r)
+			child, ok := ac.nodes[n].next[r]
+			if !ok {
+				child = len(ac.nodes)
+				ac.nodes = append(ac.nodes, acNode{next: make(map[rune]int), dict: -1})
+				ac.nodes[n].next[r] = child
+			}
+			n = child
+		}
+		ac.nodes[n].outputs = append(ac.nodes[n].outputs, p)
+	}
+
+	// Breadth-first, so that every fail target is complete
+	// before the nodes that depend upon it.
+	queue := make([]int, 0, len(ac.nodes))
+	for _, child := range ac.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for r, child := range ac.nodes[n].next {
+			f := ac.nodes[n].fail
+			target, ok := ac.nodes[f].next[r]
+			for !ok && f != 0 {
+				f = ac.nodes[f].fail
+				target, ok = ac.nodes[f].next[r]
+			}
+			if ok {
+				ac.nodes[child].fail = target
+			}
+			fail := ac.nodes[child].fail
+			if len(ac.nodes[fail].outputs) > 0 {
+				ac.nodes[child].dict = fail
+			} else {
+				ac.nodes[child].dict = ac.nodes[fail].dict
+			}
+			queue = append(queue, child)
+		}
+	}
+	return ac, nil
+}
+
+// FindAll returns every occurrence of every pattern within
+// text, including occurrences which overlap one another.
+//
+// Matches are ordered by ascending End, and matches sharing
+// an End are ordered from longest to shortest.
+func (ac *AhoCorasick) FindAll(text []rune) []PatternMatch {
+	matches := make([]PatternMatch, 0)
+	n := 0
+	for i, r := range text {
+		n = ac.step(n, ac.fold(r))
+		for o := n; o > 0; o = ac.nodes[o].dict {
+			for _, p := range ac.nodes[o].outputs {
+				matches = append(matches, PatternMatch{Pattern: p, Start: i + 1 - ac.patternLengths[p], End: i + 1})
+			}
+		}
+	}
+	return matches
+}
+
+// FindLeftmostLongest returns the non-overlapping occurrences
+// of the patterns within text, scanning from left to right
+// and preferring, at each position, the match which starts
+// earliest and then the longest match starting there.
+//
+// When several identical patterns match, the one with the
+// lowest index is reported.
+func (ac *AhoCorasick) FindLeftmostLongest(text []rune) []PatternMatch {
+	return leftmostLongest(ac.FindAll(text))
+}
+
+func (ac *AhoCorasick) step(n int, r rune) int {
+	for {
+		if child, ok := ac.nodes[n].next[r]; ok {
+			return child
+		}
+		if n == 0 {
+			return 0
+		}
+		n = ac.nodes[n].fail
+	}
+}
+
+func (ac *AhoCorasick) fold(r rune) rune {
+	if ac.caseInsensitive {
+		return unicode.ToUpper(r)
+	}
+	return r
+}
+
+func leftmostLongest(all []PatternMatch) []PatternMatch {
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].Start != all[j].Start {
+			return all[i].Start < all[j].Start
+		}
+		if all[i].End != all[j].End {
+			return all[i].End > all[j].End
+		}
+		return all[i].Pattern < all[j].Pattern
+	})
+	chosen := all[:0]
+	cursor := 0
+	for _, m := range all {
+		if m.Start >= cursor {
+			chosen = append(chosen, m)
+			cursor = m.End
+		}
+	}
+	return chosen
+}
@@ -0,0 +1,47 @@
+﻿package runewise
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_AhoCorasick_FindAll(t *testing.T) {
+	ac, err := NewAhoCorasick([][]rune{[]rune("he"), []rune("she"), []rune("his"), []rune("hers")}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []PatternMatch{
+		{Pattern: 1, Start: 1, End: 4},
+		{Pattern: 0, Start: 2, End: 4},
+		{Pattern: 3, Start: 2, End: 6},
+	}, ac.FindAll([]rune("ushers")))
+	assert.Equal(t, 0, len(ac.FindAll([]rune("USHERS"))))
+
+	ac, err = NewAhoCorasick([][]rune{[]rune("日本")}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []PatternMatch{{Pattern: 0, Start: 1, End: 3}}, ac.FindAll([]rune("x日本語")), "Offsets are rune indices.")
+}
+
+func Test_AhoCorasick_CaseInsensitive(t *testing.T) {
+	ac, err := NewAhoCorasick([][]rune{[]rune("GmbH"), []rune("ünited")}, true)
+	assert.Nil(t, err)
+	assert.Equal(t, []PatternMatch{
+		{Pattern: 1, Start: 0, End: 6},
+		{Pattern: 0, Start: 15, End: 19},
+	}, ac.FindAll([]rune("ÜNITED Widgets GMBH")))
+
+	assert.Equal(t, []PatternMatch{{Pattern: 0, Start: 5, End: 9}}, ac.FindLeftmostLongest([]rune("acme gmbh")))
+}
+
+func Test_AhoCorasick_FindLeftmostLongest(t *testing.T) {
+	ac, err := NewAhoCorasick([][]rune{[]rune("inc"), []rune("inc."), []rune("co"), []rune("corp")}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []PatternMatch{
+		{Pattern: 3, Start: 5, End: 9},
+		{Pattern: 1, Start: 10, End: 14},
+	}, ac.FindLeftmostLongest([]rune("acme corp inc.")))
+}
+
+func Test_NewAhoCorasick_Errors(t *testing.T) {
+	ac, err := NewAhoCorasick([][]rune{[]rune("a"), nil}, false)
+	assert.NotNil(t, err)
+	assert.Nil(t, ac)
+}
@@ -0,0 +1,189 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package runewise
+
+import (
+	"github.com/ZackPierce/stralgo"
+)
+
+// BitapMaxPatternLength is the longest pattern, in runes,
+// that BitapSearch is able to handle. Each pattern rune
+// occupies one bit of a machine word.
+const BitapMaxPatternLength = 64
+
+// ApproximateMatch describes an occurrence of a pattern
+// found within a larger text by an approximate substring
+// search.
+//
+// Start and End are rune indices into the searched text,
+// such that text[Start:End] is the matched substring.
+// Distance is the Levenshtein distance between the matched
+// substring and the pattern.
+type ApproximateMatch struct {
+	Start    int
+	End      int
+	Distance int
+}
+
+// SellersSearch finds the approximate occurrences of pattern
+// within text, runewise, using Sellers' semi-global variant
+// of the Levenshtein dynamic programming algorithm.
+//
+// Unlike LevenshteinDistance, which compares two whole strings,
+// SellersSearch lets a match begin and end anywhere in the text,
+// so a short pattern can be located inside a long document.
+//
+// A match is reported for every end position in the text at
+// which some substring ending there is within maxDistance
+// single-rune edits (insertions, deletions, or substitutions)
+// of the pattern. The reported Start belongs to an optimal
+// substring for that end position. Note that a single fuzzy
+// occurrence will usually produce a run of matches with
+// adjacent End values; callers wanting one match per
+// occurrence may keep only the local minima of Distance.
+//
+// Matches are returned in ascending order of End.
+//
+// See: http://en.wikipedia.org/wiki/Approximate_string_matching
+//
+// See also : P. H. Sellers, "The theory and computation of evolutionary
+// distances: pattern recognition", Journal of Algorithms 1 (1980).
+//
+// Returns stralgo.ErrEmptyPattern if the pattern is empty, or
+// stralgo.ErrNegativeDistance if maxDistance is negative.
+func SellersSearch(pattern, text []rune, maxDistance int) ([]ApproximateMatch, error) {
+	if len(pattern) == 0 {
+		return nil, stralgo.ErrEmptyPattern
+	}
+	if maxDistance < 0 {
+		return nil, stralgo.ErrNegativeDistance
+	}
+	return sellers(pattern, text, maxDistance), nil
+}
+
+// sellers fills the semi-global edit distance table one text column
+// at a time, carrying along the text offset at which the optimal
+// alignment for each cell began.
+func sellers(pattern, text []rune, maxDistance int) []ApproximateMatch {
+	m := len(pattern)
+	n := len(text)
+	prevCost := make([]int, m+1, m+1)
+	currCost := make([]int, m+1, m+1)
+	prevStart := make([]int, m+1, m+1)
+	currStart := make([]int, m+1, m+1)
+	for i := 0; i <= m; i++ {
+		prevCost[i] = i
+	}
+	matches := make([]ApproximateMatch, 0)
+	for j := 1; j <= n; j++ {
+		currCost[0] = 0
+		currStart[0] = j
+		t := text[j-1]
+		for i := 1; i <= m; i++ {
+			cost := prevCost[i-1]
+			if pattern[i-1] != t {
+				cost++
+			}
+			start := prevStart[i-1]
+			if ins := prevCost[i] + 1; ins < cost {
+				cost, start = ins, prevStart[i]
+			}
+			if del := currCost[i-1] + 1; del < cost {
+				cost, start = del, currStart[i-1]
+			}
+			currCost[i] = cost
+			currStart[i] = start
+		}
+		if currCost[m] <= maxDistance {
+			matches = append(matches, ApproximateMatch{Start: currStart[m], End: j, Distance: currCost[m]})
+		}
+		prevCost, currCost = currCost, prevCost
+		prevStart, currStart = currStart, prevStart
+	}
+	return matches
+}
+
+// BitapSearch finds the approximate occurrences of pattern
+// within text, runewise, using the Wu-Manber extension of
+// the Bitap (shift-or) algorithm.
+//
+// The results are identical to those of SellersSearch, but
+// the per-rune work is proportional to maxDistance rather
+// than to the pattern length, which makes BitapSearch the
+// faster choice for short patterns. Start offsets are
+// recovered by a small dynamic programming pass over each
+// match window, so the search is quickest when matches are rare.
+//
+// See: http://en.wikipedia.org/wiki/Bitap_algorithm
+//
+// See also : S. Wu and U. Manber, "Fast text searching allowing
+// errors", Communications of the ACM 35 (1992).
+//
+// Returns stralgo.ErrEmptyPattern if the pattern is empty,
+// stralgo.ErrPatternTooLong if it is longer than
+// BitapMaxPatternLength runes, or stralgo.ErrNegativeDistance if
+// maxDistance is negative.
+func BitapSearch(pattern, text []rune, maxDistance int) ([]ApproximateMatch, error) {
+	m := len(pattern)
+	if m == 0 {
+		return nil, stralgo.ErrEmptyPattern
+	}
+	if m > BitapMaxPatternLength {
+		return nil, stralgo.ErrPatternTooLong
+	}
+	if maxDistance < 0 {
+		return nil, stralgo.ErrNegativeDistance
+	}
+	// No end position is ever further than m edits away.
+	if maxDistance > m {
+		maxDistance = m
+	}
+	masks := make(map[rune]uint64, m)
+	for i := 0; i < m; i++ {
+		masks[pattern[i]] |= 1 << uint(i)
+	}
+	accept := uint64(1) << uint(m-1)
+
+	// Bit i of rows[d] is set when the first i+1 pattern runes
+	// match a suffix of the text read so far with at most d edits.
+	rows := make([]uint64, maxDistance+1, maxDistance+1)
+	for d := range rows {
+		rows[d] = (1 << uint(d)) - 1
+	}
+	matches := make([]ApproximateMatch, 0)
+	for j := 0; j < len(text); j++ {
+		mask := masks[text[j]]
+		prevOld := rows[0]
+		rows[0] = ((rows[0] << 1) | 1) & mask
+		for d := 1; d <= maxDistance; d++ {
+			old := rows[d]
+			rows[d] = ((old<<1)|1)&mask | prevOld | ((prevOld | rows[d-1]) << 1) | 1
+			prevOld = old
+		}
+		for d := 0; d <= maxDistance; d++ {
+			if rows[d]&accept != 0 {
+				end := j + 1
+				matches = append(matches, ApproximateMatch{Start: bitapStart(pattern, text, end, d), End: end, Distance: d})
+				break
+			}
+		}
+	}
+	return matches, nil
+}
+
+// bitapStart recovers the start offset of an optimal match of
+// pattern ending at end with the given distance. Such a match is
+// never longer than len(pattern)+distance runes, so only that
+// window of the text needs to be examined.
+func bitapStart(pattern, text []rune, end, distance int) int {
+	from := end - len(pattern) - distance
+	if from < 0 {
+		from = 0
+	}
+	window := sellers(pattern, text[from:end], distance)
+	return from + window[len(window)-1].Start
+}
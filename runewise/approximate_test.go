@@ -0,0 +1,96 @@
+﻿package runewise
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func Test_SellersSearch(t *testing.T) {
+	m, err := SellersSearch([]rune("abc"), []rune("xabcx"), 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []ApproximateMatch{{Start: 1, End: 4, Distance: 0}}, m)
+
+	m, err = SellersSearch([]rune("abc"), []rune("xabcx"), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []ApproximateMatch{
+		{Start: 1, End: 3, Distance: 1},
+		{Start: 1, End: 4, Distance: 0},
+		{Start: 1, End: 5, Distance: 1},
+	}, m)
+
+	m, err = SellersSearch([]rune("日本語"), []rune("これは日本ゴです"), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []ApproximateMatch{
+		{Start: 3, End: 5, Distance: 1},
+		{Start: 3, End: 6, Distance: 1},
+	}, m, "Offsets are rune indices.")
+
+	m, err = SellersSearch([]rune("abc"), nil, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(m))
+
+	m, err = SellersSearch(nil, []rune("abc"), 1)
+	assert.NotNil(t, err)
+	assert.Nil(t, m)
+
+	m, err = SellersSearch([]rune("abc"), []rune("abc"), -1)
+	assert.NotNil(t, err)
+	assert.Nil(t, m)
+}
+
+func Test_BitapSearch(t *testing.T) {
+	m, err := BitapSearch([]rune("abc"), []rune("xabcx"), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []ApproximateMatch{
+		{Start: 1, End: 3, Distance: 1},
+		{Start: 1, End: 4, Distance: 0},
+		{Start: 1, End: 5, Distance: 1},
+	}, m)
+
+	m, err = BitapSearch([]rune("日本語"), []rune("これは日本ゴです"), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []ApproximateMatch{
+		{Start: 3, End: 5, Distance: 1},
+		{Start: 3, End: 6, Distance: 1},
+	}, m)
+
+	m, err = BitapSearch(nil, []rune("abc"), 1)
+	assert.NotNil(t, err)
+	assert.Nil(t, m)
+
+	m, err = BitapSearch([]rune("abc"), []rune("abc"), -1)
+	assert.NotNil(t, err)
+	assert.Nil(t, m)
+
+	m, err = BitapSearch([]rune(strings.Repeat("語", BitapMaxPatternLength+1)), []rune("語"), 1)
+	assert.NotNil(t, err)
+	assert.Nil(t, m)
+}
+
+func Test_ApproximateSearch_Distances(t *testing.T) {
+	texts := []string{
+		"",
+		"the quick brown fox jumps over the lazy dog",
+		"Cedarinia scabra Sjöstedt 1921",
+		"mississippi",
+	}
+	patterns := []string{"a", "the", "qick", "Söjstedt", "issi", "dgo"}
+	for _, text := range texts {
+		for _, pattern := range patterns {
+			for k := 0; k <= 3; k++ {
+				p := []rune(pattern)
+				x := []rune(text)
+				s, err := SellersSearch(p, x, k)
+				assert.Nil(t, err)
+				b, err := BitapSearch(p, x, k)
+				assert.Nil(t, err)
+				assert.Equal(t, s, b, "pattern %q, text %q, k %d", pattern, text, k)
+				for _, match := range s {
+					d, _ := LevenshteinDistance(p, x[match.Start:match.End])
+					assert.Equal(t, d, match.Distance, "pattern %q, text %q, match %v", pattern, text, match)
+				}
+			}
+		}
+	}
+}
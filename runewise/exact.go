@@ -0,0 +1,431 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package runewise
+
+// Matcher is implemented by the precompiled single-pattern
+// exact matchers KMP, Horspool and TwoWay.
+type Matcher interface {
+	// Index returns the rune index of the first occurrence
+	// of the pattern within text, or -1 if there is none.
+	// An empty pattern matches at index 0.
+	Index(text []rune) int
+	// Len returns the length of the pattern in runes.
+	Len() int
+}
+
+// Occurrences iterates over the exact occurrences of a
+// pattern within a text, as found by FindAll.
+//
+// Typical usage:
+//
+//	occ := FindAll(m, text)
+//	for occ.Next() {
+//		fmt.Println(occ.Index())
+//	}
+type Occurrences struct {
+	m     Matcher
+	text  []rune
+	from  int
+	index int
+	// scan, if not nil, continues the search from the state
+	// the matcher reached at the previous occurrence.
+	scan func() int
+}
+
+// resumable is implemented by the matchers which can continue a
+// search after an occurrence from the state they reached there,
+// rather than starting afresh one rune further on, so that FindAll
+// takes a single pass over the text however many occurrences
+// overlap.
+type resumable interface {
+	// scanner returns a function which returns the index of each
+	// occurrence of the non-empty pattern within text in turn,
+	// and -1 once there are no more.
+	scanner(text []rune) func() int
+}
+
+// FindAll returns an iterator over every occurrence of the
+// matcher's pattern within text, in ascending order of index.
+//
+// Occurrences which overlap one another are all reported, so
+// searching for "aa" in "aaaa" yields indices 0, 1 and 2.
+// An empty pattern occurs at every index from 0 to len(text).
+//
+// The search for each occurrence resumes where the search for the
+// previous one left off, so for KMP and TwoWay finding every
+// occurrence takes time linear in the length of the text, as
+// finding the first does.
+func FindAll(m Matcher, text []rune) *Occurrences {
+	o := &Occurrences{m: m, text: text, index: -1}
+	if r, ok := m.(resumable); ok && m.Len() > 0 {
+		o.scan = r.scanner(text)
+	}
+	return o
+}
+
+// Next advances the iterator to the next occurrence,
+// returning false once no occurrences remain.
+func (o *Occurrences) Next() bool {
+	if o.scan != nil {
+		o.index = o.scan()
+		return o.index >= 0
+	}
+	if o.from > len(o.text) {
+		o.index = -1
+		return false
+	}
+	i := o.m.Index(o.text[o.from:])
+	if i < 0 {
+		o.from = len(o.text) + 1
+		o.index = -1
+		return false
+	}
+	o.index = o.from + i
+	o.from = o.index + 1
+	return true
+}
+
+// Index returns the rune index of the current occurrence,
+// or -1 if Next has not been called or has returned false.
+func (o *Occurrences) Index() int {
+	return o.index
+}
+
+// Count returns the number of occurrences of the matcher's
+// pattern within text.
+//
+// Note that, unlike strings.Count, overlapping occurrences
+// are all counted, consistent with FindAll.
+func Count(m Matcher, text []rune) int {
+	c := 0
+	occ := FindAll(m, text)
+	for occ.Next() {
+		c++
+	}
+	return c
+}
+
+// KMP is a precompiled Knuth-Morris-Pratt matcher.
+//
+// The pattern's failure function lets the search proceed
+// without ever moving backwards in the text, giving a worst
+// case linear in the combined length of pattern and text.
+//
+// See: http://en.wikipedia.org/wiki/Knuth-Morris-Pratt_algorithm
+type KMP struct {
+	pattern []rune
+	failure []int
+}
+
+// NewKMP compiles pattern into a KMP matcher.
+func NewKMP(pattern []rune) *KMP {
+	m := len(pattern)
+	failure := make([]int, m, m)
+	k := 0
+	for i := 1; i < m; i++ {
+		for k > 0 && pattern[i] != pattern[k] {
+			k = failure[k-1]
+		}
+		if pattern[i] == pattern[k] {
+			k++
+		}
+		failure[i] = k
+	}
+	return &KMP{pattern: pattern, failure: failure}
+}
+
+// Index returns the rune index of the first occurrence
+// of the pattern within text, or -1 if there is none.
+func (k *KMP) Index(text []rune) int {
+	m := len(k.pattern)
+	if m == 0 {
+		return 0
+	}
+	index, _, _ := k.search(text, 0, 0)
+	return index
+}
+
+// search continues a search of text from index i, with the first q
+// runes of the pattern matched by those before i. It returns the
+// index of the next occurrence, or -1, along with the index and
+// matched length from which to resume after it.
+func (k *KMP) search(text []rune, i, q int) (int, int, int) {
+	m := len(k.pattern)
+	for ; i < len(text); i++ {
+		for q > 0 && text[i] != k.pattern[q] {
+			q = k.failure[q-1]
+		}
+		if text[i] == k.pattern[q] {
+			q++
+		}
+		if q == m {
+			return i - m + 1, i + 1, k.failure[m-1]
+		}
+	}
+	return -1, i, q
+}
+
+func (k *KMP) scanner(text []rune) func() int {
+	i, q := 0, 0
+	return func() int {
+		var index int
+		index, i, q = k.search(text, i, q)
+		return index
+	}
+}
+
+// Len returns the length of the pattern in runes.
+func (k *KMP) Len() int {
+	return len(k.pattern)
+}
+
+// Horspool is a precompiled Boyer-Moore-Horspool matcher.
+//
+// The bad-character shift table lets the search skip over
+// large stretches of text, which makes Horspool fast on
+// average for longer patterns over large alphabets, though
+// its worst case is proportional to the product of the
+// pattern and text lengths.
+//
+// See: http://en.wikipedia.org/wiki/Boyer-Moore-Horspool_algorithm
+type Horspool struct {
+	pattern []rune
+	shift   map[rune]int
+}
+
+// NewHorspool compiles pattern into a Horspool matcher.
+func NewHorspool(pattern []rune) *Horspool {
+	m := len(pattern)
+	h := &Horspool{pattern: pattern, shift: make(map[rune]int, m)}
+	for i := 0; i < m-1; i++ {
+		h.shift[pattern[i]] = m - 1 - i
+	}
+	return h
+}
+
+// Index returns the rune index of the first occurrence
+// of the pattern within text, or -1 if there is none.
+func (h *Horspool) Index(text []rune) int {
+	m := len(h.pattern)
+	if m == 0 {
+		return 0
+	}
+	index, _ := h.search(text, 0)
+	return index
+}
+
+// search continues a search of text from the alignment of the
+// pattern at index j. It returns the index of the next occurrence,
+// or -1, along with the alignment from which to resume after it.
+func (h *Horspool) search(text []rune, j int) (int, int) {
+	m := len(h.pattern)
+	last := h.pattern[m-1]
+	for j <= len(text)-m {
+		c := text[j+m-1]
+		if c == last && runesEqual(text[j:j+m-1], h.pattern[:m-1]) {
+			return j, j + h.skip(c)
+		}
+		j += h.skip(c)
+	}
+	return -1, j
+}
+
+// skip returns the shift of the pattern past an alignment whose
+// last rune in the text is c.
+func (h *Horspool) skip(c rune) int {
+	if shift, ok := h.shift[c]; ok {
+		return shift
+	}
+	return len(h.pattern)
+}
+
+func (h *Horspool) scanner(text []rune) func() int {
+	j := 0
+	return func() int {
+		var index int
+		index, j = h.search(text, j)
+		return index
+	}
+}
+
+// Len returns the length of the pattern in runes.
+func (h *Horspool) Len() int {
+	return len(h.pattern)
+}
+
+// TwoWay is a precompiled Crochemore-Perrin Two-Way matcher.
+//
+// The pattern is split at a critical factorization, and the
+// right part is compared left-to-right before the left part
+// is compared right-to-left. This guarantees a linear worst
+// case while using only constant extra space.
+//
+// See: http://en.wikipedia.org/wiki/Two-way_string-matching_algorithm
+//
+// See also : M. Crochemore and D. Perrin, "Two-way string-matching",
+// Journal of the ACM 38 (1991).
+type TwoWay struct {
+	pattern []rune
+	// ell is the index of the last rune of the left
+	// half of the critical factorization.
+	ell int
+	// per is the period of the pattern if periodic
+	// is true, and otherwise a safe shift length.
+	per      int
+	periodic bool
+}
+
+// NewTwoWay compiles pattern into a TwoWay matcher.
+func NewTwoWay(pattern []rune) *TwoWay {
+	m := len(pattern)
+	i, p := twoWayMaximalSuffix(pattern, false)
+	j, q := twoWayMaximalSuffix(pattern, true)
+	tw := &TwoWay{pattern: pattern}
+	if i > j {
+		tw.ell, tw.per = i, p
+	} else {
+		tw.ell, tw.per = j, q
+	}
+	if tw.per+tw.ell+1 <= m && runesEqual(pattern[:tw.ell+1], pattern[tw.per:tw.per+tw.ell+1]) {
+		tw.periodic = true
+	} else {
+		left := tw.ell + 1
+		right := m - tw.ell - 1
+		if left > right {
+			tw.per = left + 1
+		} else {
+			tw.per = right + 1
+		}
+	}
+	return tw
+}
+
+// twoWayMaximalSuffix returns the start index, less one, of
+// the lexicographically maximal suffix of x under the normal
+// or reversed rune ordering, along with that suffix's period.
+func twoWayMaximalSuffix(x []rune, reversed bool) (int, int) {
+	ms := -1
+	j := 0
+	k := 1
+	p := 1
+	for j+k < len(x) {
+		a := x[j+k]
+		b := x[ms+k]
+		if (!reversed && a < b) || (reversed && a > b) {
+			j += k
+			k = 1
+			p = j - ms
+		} else if a == b {
+			if k != p {
+				k++
+			} else {
+				j += p
+				k = 1
+			}
+		} else {
+			ms = j
+			j = ms + 1
+			k = 1
+			p = 1
+		}
+	}
+	return ms, p
+}
+
+// Index returns the rune index of the first occurrence
+// of the pattern within text, or -1 if there is none.
+func (tw *TwoWay) Index(text []rune) int {
+	if len(tw.pattern) == 0 {
+		return 0
+	}
+	index, _, _ := tw.search(text, 0, -1)
+	return index
+}
+
+// search continues a search of text from the alignment of the
+// pattern at index j, where for a periodic pattern the first
+// memory+1 runes are known to match, as after a shift by the
+// period. It returns the index of the next occurrence, or -1,
+// along with the alignment and memory from which to resume after
+// it.
+func (tw *TwoWay) search(text []rune, j, memory int) (int, int, int) {
+	x := tw.pattern
+	m := len(x)
+	n := len(text)
+	if tw.periodic {
+		for j <= n-m {
+			i := tw.ell
+			if memory > i {
+				i = memory
+			}
+			i++
+			for i < m && x[i] == text[i+j] {
+				i++
+			}
+			if i >= m {
+				i = tw.ell
+				for i > memory && x[i] == text[i+j] {
+					i--
+				}
+				if i <= memory {
+					return j, j + tw.per, m - tw.per - 1
+				}
+				j += tw.per
+				memory = m - tw.per - 1
+			} else {
+				j += i - tw.ell
+				memory = -1
+			}
+		}
+		return -1, j, memory
+	}
+	for j <= n-m {
+		i := tw.ell + 1
+		for i < m && x[i] == text[i+j] {
+			i++
+		}
+		if i >= m {
+			i = tw.ell
+			for i >= 0 && x[i] == text[i+j] {
+				i--
+			}
+			if i < 0 {
+				return j, j + tw.per, -1
+			}
+			j += tw.per
+		} else {
+			j += i - tw.ell
+		}
+	}
+	return -1, j, -1
+}
+
+func (tw *TwoWay) scanner(text []rune) func() int {
+	j, memory := 0, -1
+	return func() int {
+		var index int
+		index, j, memory = tw.search(text, j, memory)
+		return index
+	}
+}
+
+// Len returns the length of the pattern in runes.
+func (tw *TwoWay) Len() int {
+	return len(tw.pattern)
+}
+
+func runesEqual(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, r := range a {
+		if r != b[i] {
+			return false
+		}
+	}
+	return true
+}
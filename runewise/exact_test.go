@@ -0,0 +1,171 @@
+﻿package runewise
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func matchers(pattern []rune) []Matcher {
+	return []Matcher{NewKMP(pattern), NewHorspool(pattern), NewTwoWay(pattern)}
+}
+
+func Test_Matcher_Index(t *testing.T) {
+	for _, m := range matchers([]rune("needle")) {
+		assert.Equal(t, 6, m.Len())
+		assert.Equal(t, 9, m.Index([]rune("haystack needle haystack")), "%T", m)
+		assert.Equal(t, -1, m.Index([]rune("needl")), "%T", m)
+		assert.Equal(t, -1, m.Index(nil), "%T", m)
+	}
+	for _, m := range matchers(nil) {
+		assert.Equal(t, 0, m.Index([]rune("abc")), "%T", m)
+	}
+	for _, m := range matchers([]rune("本語")) {
+		assert.Equal(t, 2, m.Len())
+		assert.Equal(t, 1, m.Index([]rune("日本語")), "%T reports rune indices", m)
+	}
+}
+
+func Test_Matcher_AgreesWithStringsIndex(t *testing.T) {
+	texts := []string{
+		"",
+		"abababababcabababcababc",
+		"日本語日本日本語語日本語",
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaab",
+	}
+	patterns := []string{"a", "ab", "ababc", "abababc", "日本語", "語日本", "本日本", "aaab", "aaaaa", "zebra"}
+	for _, text := range texts {
+		runes := []rune(text)
+		for _, pattern := range patterns {
+			for _, m := range matchers([]rune(pattern)) {
+				for from := 0; from <= len(runes); from++ {
+					expected := strings.Index(string(runes[from:]), pattern)
+					if expected > 0 {
+						expected = len([]rune(string(runes[from:])[:expected]))
+					}
+					assert.Equal(t, expected, m.Index(runes[from:]), "%T %q in %q", m, pattern, string(runes[from:]))
+				}
+			}
+		}
+	}
+}
+
+func Test_FindAll(t *testing.T) {
+	for _, m := range matchers([]rune("日日")) {
+		occ := FindAll(m, []rune("日日日日"))
+		found := make([]int, 0)
+		for occ.Next() {
+			found = append(found, occ.Index())
+		}
+		assert.Equal(t, []int{0, 1, 2}, found, "%T reports overlapping occurrences", m)
+		assert.False(t, occ.Next())
+	}
+}
+
+func Test_FindAll_AgreesWithNaiveSearch(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	random := func(n int) []rune {
+		s := make([]rune, n, n)
+		for i := range s {
+			s[i] = []rune("a\u65e5")[r.Intn(2)]
+		}
+		return s
+	}
+	patterns := [][]rune{[]rune("a"), []rune("aa"), []rune("aaaa"), []rune("a\u65e5a"), []rune("a\u65e5aa\u65e5")}
+	for i := 0; i < 50; i++ {
+		patterns = append(patterns, random(1+r.Intn(8)))
+	}
+	texts := [][]rune{[]rune(strings.Repeat("a", 40)), []rune(strings.Repeat("a\u65e5", 20))}
+	for i := 0; i < 50; i++ {
+		texts = append(texts, random(r.Intn(60)))
+	}
+	for _, text := range texts {
+		for _, pattern := range patterns {
+			expected := make([]int, 0)
+			for i := 0; i+len(pattern) <= len(text); i++ {
+				if runesEqual(text[i:i+len(pattern)], pattern) {
+					expected = append(expected, i)
+				}
+			}
+			for _, m := range matchers(pattern) {
+				found := make([]int, 0)
+				for occ := FindAll(m, text); occ.Next(); {
+					found = append(found, occ.Index())
+				}
+				assert.Equal(t, expected, found, "%T %q in %q", m, string(pattern), string(text))
+			}
+		}
+	}
+}
+
+func Test_Count(t *testing.T) {
+	for _, m := range matchers([]rune("ana")) {
+		assert.Equal(t, 2, Count(m, []rune("banana")), "%T", m)
+		assert.Equal(t, 0, Count(m, nil), "%T", m)
+	}
+}
+
+var benchmarkText = strings.Repeat("the quick brown fox jumps over the lazy dog ", 200) + "the quick brown fox jumps over the lazy cat"
+
+const benchmarkPattern = "over the lazy cat"
+
+func Benchmark_KMP_Index(b *testing.B) {
+	m := NewKMP([]rune(benchmarkPattern))
+	text := []rune(benchmarkText)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Index(text)
+	}
+}
+
+func Benchmark_Horspool_Index(b *testing.B) {
+	m := NewHorspool([]rune(benchmarkPattern))
+	text := []rune(benchmarkText)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Index(text)
+	}
+}
+
+func Benchmark_TwoWay_Index(b *testing.B) {
+	m := NewTwoWay([]rune(benchmarkPattern))
+	text := []rune(benchmarkText)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Index(text)
+	}
+}
+
+func Benchmark_StringsIndex(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		strings.Index(benchmarkText, benchmarkPattern)
+	}
+}
+
+// periodicText and periodicPattern overlap at every index, which
+// makes restarting the search after each occurrence quadratic.
+var periodicText = []rune(strings.Repeat("a", 200000))
+
+var periodicPattern = []rune(strings.Repeat("a", 1000))
+
+func Benchmark_KMP_Count_Periodic(b *testing.B) {
+	m := NewKMP(periodicPattern)
+	for i := 0; i < b.N; i++ {
+		Count(m, periodicText)
+	}
+}
+
+func Benchmark_Horspool_Count_Periodic(b *testing.B) {
+	m := NewHorspool(periodicPattern)
+	for i := 0; i < b.N; i++ {
+		Count(m, periodicText)
+	}
+}
+
+func Benchmark_TwoWay_Count_Periodic(b *testing.B) {
+	m := NewTwoWay(periodicPattern)
+	for i := 0; i < b.N; i++ {
+		Count(m, periodicText)
+	}
+}
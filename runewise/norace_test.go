@@ -0,0 +1,11 @@
+﻿//go:build !race
+
+/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package runewise
+
+const raceEnabled = false
@@ -11,9 +11,10 @@ support for multi-byte runes.
 package runewise
 
 import (
-	"errors"
-	"fmt"
-	"sort"
+	"github.com/ZackPierce/stralgo"
+	"github.com/ZackPierce/stralgo/internal/tokenset"
+	"github.com/ZackPierce/stralgo/sequence"
+	"github.com/ZackPierce/stralgo/tokenize"
 	"unicode"
 )
 
@@ -33,21 +34,10 @@ const (
 
 // See: http://en.wikipedia.org/wiki/Hamming_distance
 //
-// Returns an error if the string rune counts are not equal.
+// Returns a *stralgo.LengthMismatchError, which matches
+// stralgo.ErrUnequalLength, if the string rune counts are not equal.
 func HammingDistance(a, b []rune) (uint, error) {
-	aLen := len(a)
-	bLen := len(b)
-
-	if aLen != bLen {
-		return 0, errors.New("Hamming distance is undefined between strings of unequal length.")
-	}
-	var d uint
-	for i := 0; i < aLen; i++ {
-		if a[i] != b[i] {
-			d++
-		}
-	}
-	return d, nil
+	return sequence.HammingDistance(a, b)
 }
 
 // DiceCoefficent calculates the simiarlity of two
@@ -63,42 +53,36 @@ func HammingDistance(a, b []rune) (uint, error) {
 // account for bigram frequency count differences between
 // the compared strings.
 //
+// Runes are compared by value, so rune values which are not
+// valid Unicode, such as surrogate halves, remain distinct.
+//
 // See: http://en.wikipedia.org/wiki/Dice_coefficient
 //
-// Returns an error if both of the input strings contain
+// Returns stralgo.ErrInsufficientBigrams if both of the input strings contain
 // less than two runes.
 func DiceCoefficient(a, b []rune) (float64, error) {
-	aLen := len(a)
-	bLen := len(b)
-	aLimit := aLen - 1
-	bLimit := bLen - 1
-	if aLimit < 1 && bLimit < 1 {
-		return 0, errors.New("At least one of the input strings must contain 2 or more runes for the bigram-based DiceCoefficient to be calculated.")
-	}
-	aSet := make(map[runeBigram]bool, aLimit)
-	totalBigrams := 0.0
-	var bigram runeBigram
-	for i := 0; i < aLimit; i++ {
-		bigram = runeBigram{rA: a[i], rB: a[i+1]}
-		if !aSet[bigram] {
-			totalBigrams++
-			aSet[bigram] = true
-		}
-	}
+	var w Workspace
+	return w.DiceCoefficient(a, b)
+}
 
-	bSet := make(map[runeBigram]bool, bLimit)
-	sharedBigrams := 0.0
-	for i := 0; i < bLimit; i++ {
-		bigram = runeBigram{rA: b[i], rB: b[i+1]}
-		if !bSet[bigram] {
-			totalBigrams++
-			bSet[bigram] = true
-			if aSet[bigram] {
-				sharedBigrams++
-			}
-		}
+// DiceCoefficientTokenized calculates the similarity of two
+// strings per the Sorensen-Dice coefficient of the sets of
+// distinct tokens produced from each by tokenizer.
+//
+// DiceCoefficient is DiceCoefficientTokenized with rune
+// bigrams, tokenize.NGrams(2), as the tokens, except that the
+// tokenizer is given the strings as UTF-8, so each invalid rune
+// value, such as a surrogate half or a value above U+10FFFF, is
+// replaced with U+FFFD.
+//
+// Returns stralgo.ErrNoTokens if neither of the input strings
+// produces any tokens.
+func DiceCoefficientTokenized(a, b []rune, tokenizer tokenize.Tokenizer) (float64, error) {
+	c, ok := tokenset.Dice(tokenizer.Tokenize(encode(a, false)), tokenizer.Tokenize(encode(b, false)))
+	if !ok {
+		return 0, stralgo.ErrNoTokens
 	}
-	return 2 * sharedBigrams / totalBigrams, nil
+	return c, nil
 }
 
 // WhiteSimilarity calculates the similarity of two
@@ -111,56 +95,59 @@ func DiceCoefficient(a, b []rune) (float64, error) {
 // WhiteSimilarity differs from DiceCoefficient in that
 // it disregards bigrams that include whitespace, and
 // applies an upper-case filter, and accounts for bigram
-// frequency.
+// frequency. As for DiceCoefficient, invalid rune values remain
+// distinct.
 //
 // See: http://www.catalysoft.com/articles/strikeamatch.html
 //
-// Returns an error if neither of the input strings
-// contains at least one rune bigram without whitespace.
+// Returns stralgo.ErrInsufficientBigrams if neither of the
+// input strings contains at least one rune bigram without whitespace.
 func WhiteSimilarity(a, b []rune) (float64, error) {
-	aPairs, aLen := upperWordLetterPairs(a)
-	bPairs, bLen := upperWordLetterPairs(b)
-	union := aLen + bLen
-	if union == 0 {
-		return 0.0, errors.New("At least one of the input strings must contain two or more non-whitespace rune bigrams in order to calculate the White Similarity.")
-	}
-	intersection := 0.0
-	for _, aBigram := range aPairs {
-		for j, bBigram := range bPairs {
-			if aBigram == bBigram {
-				intersection++
-				bPairs[j] = runeBigram{}
-				break
-			}
-		}
+	var w Workspace
+	return w.WhiteSimilarity(a, b)
+}
+
+// WhiteSimilarityTokenized calculates the similarity of two
+// strings through a variation on the Sorensen-Dice
+// Coefficient algorithm, over the tokens produced from
+// each by tokenizer.
+//
+// The strings are upper-cased before being tokenized,
+// and repeated tokens are accounted for.
+//
+// WhiteSimilarity is WhiteSimilarityTokenized with the rune
+// bigrams within each whitespace separated word,
+// tokenize.Chain(tokenize.Whitespace, tokenize.NGrams(2)),
+// as the tokens, except that, as for DiceCoefficientTokenized,
+// invalid rune values are replaced with U+FFFD.
+//
+// Returns stralgo.ErrNoTokens if neither of the input strings
+// produces any tokens.
+func WhiteSimilarityTokenized(a, b []rune, tokenizer tokenize.Tokenizer) (float64, error) {
+	s, ok := tokenset.MultisetDice(tokenizer.Tokenize(encode(a, true)), tokenizer.Tokenize(encode(b, true)))
+	if !ok {
+		return 0.0, stralgo.ErrNoTokens
 	}
-	return 2 * intersection / float64(union), nil
+	return s, nil
 }
 
-func upperWordLetterPairs(runes []rune) ([]runeBigram, int) {
-	limit := len(runes) - 1
-	if limit < 1 {
-		return make([]runeBigram, 0), 0
+// encode returns the UTF-8 encoding of runes, upper-cased if
+// upper is true, for a tokenizer. A tokenizer takes a string, and
+// rune values with no UTF-8 encoding, such as surrogate halves
+// and values above unicode.MaxRune, are encoded as U+FFFD, as in
+// a string(runes) conversion; so unlike the runes themselves,
+// they cannot be told apart from one another or from U+FFFD.
+// Runes decoded from strings by StringMetrics are always valid,
+// whatever its Invalid policy, and so are encoded unchanged.
+func encode(runes []rune, upper bool) string {
+	if !upper {
+		return string(runes)
 	}
-	bigrams := make([]runeBigram, limit)
-	var a rune
-	var b rune
-	numPairs := 0
-	for i := 0; i < limit; i++ {
-		a = runes[i]
-		b = runes[i+1]
-		if unicode.IsSpace(b) {
-			i++
-			continue
-		}
-		if unicode.IsSpace(a) {
-			continue
-		}
-		bigrams[numPairs] = runeBigram{rA: unicode.ToUpper(a), rB: unicode.ToUpper(b)}
-		numPairs++
+	upperRunes := make([]rune, len(runes), len(runes))
+	for i, r := range runes {
+		upperRunes[i] = unicode.ToUpper(r)
 	}
-	bigrams = bigrams[0:numPairs]
-	return bigrams, numPairs
+	return string(upperRunes)
 }
 
 // LevenshteinDistance calculates the magnitude of
@@ -175,38 +162,7 @@ func upperWordLetterPairs(runes []rune) ([]runeBigram, int) {
 //
 // See: http://en.wikipedia.org/wiki/Levenshtein_distance
 func LevenshteinDistance(a, b []rune) (int, error) {
-	aLen := len(a)
-	bLen := len(b)
-	if aLen == 0 {
-		return bLen, nil
-	}
-	if bLen == 0 {
-		return aLen, nil
-	}
-
-	rowLen := bLen + 1
-	prevRow := make([]int, rowLen, rowLen)
-	currRow := make([]int, rowLen, rowLen)
-	for h := 0; h < rowLen; h++ {
-		prevRow[h] = h
-	}
-	cost := 0
-	for i := 0; i < aLen; i++ {
-		currRow[0] = i + 1
-		for j := 0; j < bLen; j++ {
-			if a[i] == b[j] {
-				cost = 0
-			} else {
-				cost = 1
-			}
-			currRow[j+1] = min(
-				currRow[j]+1,
-				prevRow[j+1]+1,
-				prevRow[j]+cost)
-		}
-		prevRow, currRow = currRow, prevRow
-	}
-	return prevRow[bLen], nil
+	return sequence.LevenshteinDistance(a, b)
 }
 
 // DamerauLevenshteinDistance calculates the magnitude
@@ -224,65 +180,29 @@ func LevenshteinDistance(a, b []rune) (int, error) {
 //
 // See: http://en.wikipedia.org/wiki/Damerau-Levenshtein_distance
 func DamerauLevenshteinDistance(a, b []rune) (int, error) {
-	aLen := len(a)
-	bLen := len(b)
-	if aLen == 0 {
-		return bLen, nil
-	} else if bLen == 0 {
-		return aLen, nil
-	}
-
-	// Swap to ensure a contains the shorter slice
-	if aLen > bLen {
-		a, aLen, b, bLen = b, bLen, a, aLen
-	}
-	rowLen := aLen + 1
-	tranRow := make([]int, rowLen, rowLen)
-	prevRow := make([]int, rowLen, rowLen)
-	currRow := make([]int, rowLen, rowLen)
-	for h := 0; h < rowLen; h++ {
-		prevRow[h] = h
-	}
-	var prevB rune
-	var cost int
-	for i := 1; i <= bLen; i++ {
-		currB := b[i-1]
-		currRow[0] = i
+	return sequence.DamerauLevenshteinDistance(a, b)
+}
 
-		start := i - bLen - 1
-		if start < 1 {
-			start = 1
-		}
-		end := i + bLen + 1
-		if end > aLen {
-			end = aLen
-		}
+// LevenshteinDistanceWithin calculates the Levenshtein distance
+// between two strings if it is no greater than maxDistance,
+// stopping early once it is known to be greater, which makes it
+// much faster than LevenshteinDistance when searching for close
+// matches.
+//
+// It returns the distance and true if the distance is no greater
+// than maxDistance. Otherwise it returns false, along with
+// maxDistance+1, a lower bound on the distance, or 0 if
+// maxDistance is negative.
+func LevenshteinDistanceWithin(a, b []rune, maxDistance int) (int, bool) {
+	return sequence.LevenshteinDistanceWithin(a, b, maxDistance)
+}
 
-		var prevA rune
-		for j := start; j <= end; j++ {
-			currA := a[j-1]
-			if currA == currB {
-				cost = 0
-			} else {
-				cost = 1
-			}
-			entry := min(
-				currRow[j-1]+1,
-				prevRow[j]+1,
-				prevRow[j-1]+cost)
-			if currA == prevB && currB == prevA {
-				trans := tranRow[j-2] + cost
-				if trans < entry {
-					entry = trans
-				}
-			}
-			currRow[j] = entry
-			prevA = currA
-		}
-		prevB = currB
-		tranRow, prevRow, currRow = prevRow, currRow, tranRow
-	}
-	return prevRow[aLen], nil
+// DamerauLevenshteinDistanceWithin calculates the
+// Damerau-Levenshtein distance between two strings if it is no
+// greater than maxDistance, stopping early once it is known to be
+// greater. Its results are as for LevenshteinDistanceWithin.
+func DamerauLevenshteinDistanceWithin(a, b []rune, maxDistance int) (int, bool) {
+	return sequence.DamerauLevenshteinDistanceWithin(a, b, maxDistance)
 }
 
 // JaroSimilarity calculates the similarity between two strings
@@ -300,75 +220,7 @@ func DamerauLevenshteinDistance(a, b []rune) (int, error) {
 //
 // See also : http://alias-i.com/lingpipe/docs/api/com/aliasi/spell/JaroWinklerDistance.html
 func JaroSimilarity(a, b []rune) float64 {
-	matches, transpositions := jaroMatchesAndHalfTranspositions(a, b)
-
-	if matches == 0 {
-		return 0.0
-	}
-
-	matchFloat := float64(matches)
-	return (1.0 / 3.0) * (matchFloat/float64(len(a)) + matchFloat/float64(len(b)) + (matchFloat-float64(transpositions/2))/matchFloat)
-}
-
-// jaroMatchesAndHalfTranspositions calculates the number of
-// matches and half-transpositions defined by the Jaro distance
-// formula.
-func jaroMatchesAndHalfTranspositions(a, b []rune) (int, int) {
-	aLen := len(a)
-	bLen := len(b)
-	if aLen == 0 || bLen == 0 {
-		return 0, 0
-	}
-	if aLen < bLen {
-		a, aLen, b, bLen = b, bLen, a, aLen
-	}
-	matchMax := (aLen / 2) - 1
-	if matchMax < 0 {
-		matchMax = 0
-	}
-	aCommon := make([]rune, aLen, aLen)
-	numAMatched := 0
-	bMatchedIndices := make(map[int]bool, aLen)
-	for i, aRune := range a {
-		from := i - matchMax
-		if from < 0 {
-			from = 0
-		}
-		to := i + matchMax
-		if to >= bLen {
-			to = bLen - 1
-		}
-		aMatched := false
-		for j := from; j <= to; j++ {
-			if aRune != b[j] {
-				continue
-			}
-			if !aMatched {
-				aCommon[numAMatched] = aRune
-				aMatched = true
-				numAMatched++
-			}
-			if _, ok := bMatchedIndices[j]; !ok {
-				bMatchedIndices[j] = true
-			}
-		}
-	}
-
-	bIndices := make([]int, numAMatched, numAMatched)
-	c := 0
-	for s, _ := range bMatchedIndices {
-		bIndices[c] = s
-		c++
-	}
-	sort.Ints(bIndices)
-
-	transCount := 0
-	for k := 0; k < numAMatched; k++ {
-		if aCommon[k] != b[bIndices[k]] {
-			transCount++
-		}
-	}
-	return numAMatched, transCount
+	return sequence.JaroSimilarity(a, b)
 }
 
 // JaroWinklerSimilarity calculates the similarity between
@@ -442,39 +294,5 @@ func JaroWinklerSimilarity(a, b []rune) float64 {
 //
 //    Min(calculatedLengthOfCommonPrefix, maxPrefixLength)*prefixScale*(1 - calculatedJaroSimilarity)/
 func JaroWinklerSimilarityParametric(a, b []rune, prefixScale float64, maxPrefixLength int, boostThreshold float64) float64 {
-	j := JaroSimilarity(a, b)
-	if j < boostThreshold {
-		return j
-	}
-	return j + float64(clampedSharedPrefixLength(a, b, maxPrefixLength))*prefixScale*(1.0-j)
-}
-
-func clampedSharedPrefixLength(a, b []rune, maxPrefixLength int) int {
-	minLen := min(len(a), len(b), maxPrefixLength)
-	i := 0
-	for ; i < minLen; i++ {
-		if a[i] != b[i] {
-			return i
-		}
-	}
-	return i
-}
-
-func min(a, b, c int) int {
-	m := a
-	if b < m {
-		m = b
-	}
-	if c < m {
-		return c
-	}
-	return m
-}
-
-type runeBigram struct {
-	rA, rB rune
-}
-
-func (r runeBigram) String() string {
-	return fmt.Sprintf("{%q, %q}", r.rA, r.rB)
+	return sequence.JaroWinklerSimilarityParametric(a, b, prefixScale, maxPrefixLength, boostThreshold)
 }
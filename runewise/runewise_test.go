@@ -2,6 +2,7 @@
 
 import (
 	"fmt"
+	"github.com/ZackPierce/stralgo/tokenize"
 	"github.com/stretchr/testify/assert"
 	"math"
 	"testing"
@@ -78,6 +79,18 @@ func Test_DiceCoefficient(t *testing.T) {
 	c, err = DiceCoefficient([]rune("日"), []rune("旨"))
 	assert.NotNil(t, err, "DiceCoefficient runewise reports an error due to lack of rune-bigrams.")
 	assert.Equal(t, 0.0, c)
+
+	c, err = DiceCoefficient([]rune{'a', 0xD800}, []rune{'a', 0xDFFF})
+	assert.Nil(t, err)
+	assert.Equal(t, 0.0, c, "Invalid rune values remain distinct.")
+
+	c, err = WhiteSimilarity([]rune{'a', 0xD800}, []rune{'a', 0x110000})
+	assert.Nil(t, err)
+	assert.Equal(t, 0.0, c, "Invalid rune values remain distinct.")
+
+	c, err = DiceCoefficientTokenized([]rune{'a', 0xD800}, []rune{'a', 0xFFFD}, tokenize.NGrams(2))
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, c, "A tokenizer is given invalid rune values as U+FFFD.")
 }
 
 func Test_WhiteSimilarity(t *testing.T) {
@@ -150,6 +163,42 @@ func Test_WhiteSimilarity(t *testing.T) {
 	EqualWithin(t, 0.4, c, 0.01)
 }
 
+func Test_DiceCoefficientTokenized(t *testing.T) {
+	c, err := DiceCoefficientTokenized([]rune("日本語"), []rune("日本"), tokenize.NGrams(2))
+	assert.Nil(t, err)
+	EqualWithin(t, 0.666, c, 0.01)
+
+	c, err = DiceCoefficientTokenized([]rune("the quick brown fox"), []rune("the lazy brown dog"), tokenize.Words)
+	assert.Nil(t, err)
+	assert.Equal(t, 0.5, c)
+
+	c, err = DiceCoefficientTokenized([]rune("running shoes"), []rune("run shoe"), tokenize.Chain(tokenize.Words, tokenize.EnglishStemmer))
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, c)
+
+	c, err = DiceCoefficientTokenized([]rune("a"), []rune("b"), tokenize.NGrams(2))
+	assert.NotNil(t, err)
+	assert.Equal(t, 0.0, c)
+}
+
+func Test_WhiteSimilarityTokenized(t *testing.T) {
+	c, err := WhiteSimilarityTokenized([]rune("REPUBLIC OF FRANCE"), []rune("FRANCE"), tokenize.Chain(tokenize.Whitespace, tokenize.NGrams(2)))
+	assert.Nil(t, err)
+	EqualWithin(t, 0.56, c, 0.01)
+
+	c, err = WhiteSimilarityTokenized([]rune("Müller, Hans-Peter"), []rune("HANS-PETER MÜLLER"), tokenize.Punctuation)
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, c)
+
+	c, err = WhiteSimilarityTokenized([]rune("Müller, Hans-Peter"), []rune("HANS-PETER MÜLLER"), tokenize.Whitespace)
+	assert.Nil(t, err)
+	assert.Equal(t, 0.5, c, "Whitespace tokens keep their punctuation, so only HANS-PETER is shared.")
+
+	c, err = WhiteSimilarityTokenized([]rune(""), []rune(" "), tokenize.Whitespace)
+	assert.NotNil(t, err)
+	assert.Equal(t, 0.0, c)
+}
+
 func Test_LevenshteinDistance_Easy(t *testing.T) {
 	d, err := LevenshteinDistance([]rune("test"), []rune("test"))
 	assert.Nil(t, err)
@@ -266,6 +315,9 @@ func Test_Jaro_Unequal(t *testing.T) {
 
 	c = JaroSimilarity([]rune("abcd"), []rune("qrsd"))
 	EqualWithin(t, (1.0/3.0)*(1.0/4.0+1.0/4.0+1.0/1.0), c, 0.0001)
+
+	c = JaroSimilarity([]rune("cacd"), []rune("aad"))
+	EqualWithin(t, (1.0/3.0)*(2.0/4.0+2.0/3.0+1.0), c, 0.0001, "Each rune matches at most one rune of the other string.")
 }
 
 func Test_JaroWinkler_Empty(t *testing.T) {
@@ -0,0 +1,278 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package runewise
+
+import (
+	"github.com/ZackPierce/stralgo"
+	"github.com/ZackPierce/stralgo/tokenize"
+	"sync"
+	"unicode/utf8"
+)
+
+// InvalidUTF8 selects how the methods of StringMetrics treat
+// strings which are not valid UTF-8.
+type InvalidUTF8 int
+
+const (
+	ReplaceInvalidUTF8 InvalidUTF8 = iota // Each invalid byte is decoded as U+FFFD, the Unicode replacement character, as in a []rune(s) conversion.
+	RejectInvalidUTF8                     // Strings which are not valid UTF-8 are rejected with stralgo.ErrInvalidUTF8.
+	InvalidUTF8AsRunes                    // Each invalid byte is decoded as the rune of the same value, so that distinct invalid bytes remain distinct. Note that byte 0xE9 then equals the valid "é".
+)
+
+// StringMetrics provides the runewise metrics for string inputs,
+// decoding the strings with its Invalid policy rather than
+// requiring callers to convert them with []rune(s).
+//
+// The strings are decoded into buffers drawn from a shared pool,
+// so that repeated comparisons do not allocate a pair of rune
+// slices each time. HammingDistance decodes its input as it goes,
+// and needs no buffers at all.
+//
+// Every method returns stralgo.ErrInvalidUTF8 if its policy is
+// RejectInvalidUTF8 and either string is not valid UTF-8. Other
+// errors are those of the corresponding runewise function.
+// StringMetrics values are safe for concurrent use.
+type StringMetrics struct {
+	Invalid InvalidUTF8
+}
+
+// Strings provides the runewise metrics for string inputs,
+// replacing invalid UTF-8 with U+FFFD:
+//
+//	d, err := runewise.Strings.LevenshteinDistance("kitten", "sitting")
+var Strings = StringMetrics{Invalid: ReplaceInvalidUTF8}
+
+// maxPooledRunes bounds the capacity of the buffers returned to
+// the pool, so that one very long string does not pin a large
+// buffer for the life of the program.
+const maxPooledRunes = 1 << 16
+
+// runeBuffers holds the buffers into which pairs of strings
+// are decoded, along with a Workspace for comparing them.
+var runeBuffers = sync.Pool{
+	New: func() interface{} {
+		return &runePair{
+			a: make([]rune, 0, 64),
+			b: make([]rune, 0, 64),
+		}
+	},
+}
+
+type runePair struct {
+	a, b      []rune
+	workspace Workspace
+}
+
+// decodeRune decodes the first rune of s per the policy, and
+// returns it along with its width in bytes.
+func (m StringMetrics) decodeRune(s string) (rune, int, error) {
+	if s[0] < utf8.RuneSelf {
+		return rune(s[0]), 1, nil
+	}
+	r, width := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError && width == 1 {
+		switch m.Invalid {
+		case RejectInvalidUTF8:
+			return 0, 0, stralgo.ErrInvalidUTF8
+		case InvalidUTF8AsRunes:
+			return rune(s[0]), 1, nil
+		}
+	}
+	return r, width, nil
+}
+
+// decode appends the runes of s, decoded per the policy, to buf.
+func (m StringMetrics) decode(s string, buf []rune) ([]rune, error) {
+	for len(s) > 0 {
+		if s[0] < utf8.RuneSelf {
+			buf = append(buf, rune(s[0]))
+			s = s[1:]
+			continue
+		}
+		r, width, err := m.decodeRune(s)
+		if err != nil {
+			return buf, err
+		}
+		buf = append(buf, r)
+		s = s[width:]
+	}
+	return buf, nil
+}
+
+// with decodes a and b into pooled buffers and calls f with
+// them, and a pooled Workspace. f must not retain the slices or
+// the Workspace it is given.
+func (m StringMetrics) with(a, b string, f func(w *Workspace, a, b []rune)) error {
+	buffers := runeBuffers.Get().(*runePair)
+	var err error
+	buffers.a, err = m.decode(a, buffers.a[:0])
+	if err == nil {
+		buffers.b, err = m.decode(b, buffers.b[:0])
+	}
+	if err == nil {
+		f(&buffers.workspace, buffers.a, buffers.b)
+	}
+	if cap(buffers.a) <= maxPooledRunes && cap(buffers.b) <= maxPooledRunes {
+		runeBuffers.Put(buffers)
+	}
+	return err
+}
+
+// HammingDistance calculates the Hamming distance between two
+// strings containing equal numbers of runes. See HammingDistance.
+func (m StringMetrics) HammingDistance(a, b string) (uint, error) {
+	var d uint
+	n := 0
+	for len(a) > 0 && len(b) > 0 {
+		ra, aWidth, err := m.decodeRune(a)
+		if err != nil {
+			return 0, err
+		}
+		rb, bWidth, err := m.decodeRune(b)
+		if err != nil {
+			return 0, err
+		}
+		if ra != rb {
+			d++
+		}
+		a, b = a[aWidth:], b[bWidth:]
+		n++
+	}
+	if len(a) > 0 || len(b) > 0 {
+		// Reject invalid UTF-8 before reporting the unequal lengths.
+		if _, err := m.decode(a+b, nil); err != nil {
+			return 0, err
+		}
+		return 0, &stralgo.LengthMismatchError{
+			Metric:  "Hamming distance",
+			ALength: n + utf8.RuneCountInString(a),
+			BLength: n + utf8.RuneCountInString(b),
+		}
+	}
+	return d, nil
+}
+
+// DiceCoefficient calculates the Sorensen-Dice coefficient of
+// two strings. See DiceCoefficient.
+func (m StringMetrics) DiceCoefficient(a, b string) (c float64, err error) {
+	if decodeErr := m.with(a, b, func(w *Workspace, a, b []rune) {
+		c, err = w.DiceCoefficient(a, b)
+	}); decodeErr != nil {
+		return 0, decodeErr
+	}
+	return c, err
+}
+
+// DiceCoefficientTokenized calculates the Sorensen-Dice
+// coefficient of the tokens of two strings. See
+// DiceCoefficientTokenized.
+func (m StringMetrics) DiceCoefficientTokenized(a, b string, tokenizer tokenize.Tokenizer) (c float64, err error) {
+	if decodeErr := m.with(a, b, func(_ *Workspace, a, b []rune) {
+		c, err = DiceCoefficientTokenized(a, b, tokenizer)
+	}); decodeErr != nil {
+		return 0, decodeErr
+	}
+	return c, err
+}
+
+// WhiteSimilarity calculates the White similarity of two
+// strings. See WhiteSimilarity.
+func (m StringMetrics) WhiteSimilarity(a, b string) (s float64, err error) {
+	if decodeErr := m.with(a, b, func(w *Workspace, a, b []rune) {
+		s, err = w.WhiteSimilarity(a, b)
+	}); decodeErr != nil {
+		return 0, decodeErr
+	}
+	return s, err
+}
+
+// WhiteSimilarityTokenized calculates the White similarity of
+// the tokens of two strings. See WhiteSimilarityTokenized.
+func (m StringMetrics) WhiteSimilarityTokenized(a, b string, tokenizer tokenize.Tokenizer) (s float64, err error) {
+	if decodeErr := m.with(a, b, func(_ *Workspace, a, b []rune) {
+		s, err = WhiteSimilarityTokenized(a, b, tokenizer)
+	}); decodeErr != nil {
+		return 0, decodeErr
+	}
+	return s, err
+}
+
+// LevenshteinDistance calculates the Levenshtein distance
+// between two strings. See LevenshteinDistance.
+func (m StringMetrics) LevenshteinDistance(a, b string) (d int, err error) {
+	if decodeErr := m.with(a, b, func(w *Workspace, a, b []rune) {
+		d, err = w.LevenshteinDistance(a, b)
+	}); decodeErr != nil {
+		return 0, decodeErr
+	}
+	return d, err
+}
+
+// DamerauLevenshteinDistance calculates the Damerau-Levenshtein
+// distance between two strings. See DamerauLevenshteinDistance.
+func (m StringMetrics) DamerauLevenshteinDistance(a, b string) (d int, err error) {
+	if decodeErr := m.with(a, b, func(w *Workspace, a, b []rune) {
+		d, err = w.DamerauLevenshteinDistance(a, b)
+	}); decodeErr != nil {
+		return 0, decodeErr
+	}
+	return d, err
+}
+
+// LevenshteinDistanceWithin calculates the Levenshtein distance
+// between two strings, if it is no greater than maxDistance. See
+// LevenshteinDistanceWithin.
+func (m StringMetrics) LevenshteinDistanceWithin(a, b string, maxDistance int) (d int, ok bool, err error) {
+	err = m.with(a, b, func(w *Workspace, a, b []rune) {
+		d, ok = w.LevenshteinDistanceWithin(a, b, maxDistance)
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	return d, ok, nil
+}
+
+// DamerauLevenshteinDistanceWithin calculates the
+// Damerau-Levenshtein distance between two strings, if it is no
+// greater than maxDistance. See DamerauLevenshteinDistanceWithin.
+func (m StringMetrics) DamerauLevenshteinDistanceWithin(a, b string, maxDistance int) (d int, ok bool, err error) {
+	err = m.with(a, b, func(w *Workspace, a, b []rune) {
+		d, ok = w.DamerauLevenshteinDistanceWithin(a, b, maxDistance)
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	return d, ok, nil
+}
+
+// JaroSimilarity calculates the Jaro similarity of two strings.
+// See JaroSimilarity.
+func (m StringMetrics) JaroSimilarity(a, b string) (s float64, err error) {
+	err = m.with(a, b, func(w *Workspace, a, b []rune) {
+		s = w.JaroSimilarity(a, b)
+	})
+	return s, err
+}
+
+// JaroWinklerSimilarity calculates the Jaro-Winkler similarity
+// of two strings. See JaroWinklerSimilarity.
+func (m StringMetrics) JaroWinklerSimilarity(a, b string) (s float64, err error) {
+	err = m.with(a, b, func(w *Workspace, a, b []rune) {
+		s = w.JaroWinklerSimilarity(a, b)
+	})
+	return s, err
+}
+
+// JaroWinklerSimilarityParametric calculates the Jaro-Winkler
+// similarity of two strings with the given parameters. See
+// JaroWinklerSimilarityParametric.
+func (m StringMetrics) JaroWinklerSimilarityParametric(a, b string, prefixScale float64, maxPrefixLength int, boostThreshold float64) (s float64, err error) {
+	err = m.with(a, b, func(w *Workspace, a, b []rune) {
+		s = w.JaroWinklerSimilarityParametric(a, b, prefixScale, maxPrefixLength, boostThreshold)
+	})
+	return s, err
+}
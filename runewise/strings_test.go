@@ -0,0 +1,184 @@
+﻿package runewise
+
+import (
+	"errors"
+	"github.com/ZackPierce/stralgo"
+	"github.com/ZackPierce/stralgo/tokenize"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_Strings_AgreesWithRunes(t *testing.T) {
+	pairs := [][2]string{
+		{"kitten", "sitting"},
+		{"", "abc"},
+		{"", ""},
+		{"caf\u00e9 cr\u00e8me", "cafe creme"},
+		{"日本語", "日本"},
+		{"martha", "marhta"},
+		{"bad \xff byte", "bad \xfe byte"},
+	}
+	words := tokenize.Chain(tokenize.Whitespace, tokenize.NGrams(2))
+	for _, p := range pairs {
+		a, b := []rune(p[0]), []rune(p[1])
+
+		d, err := LevenshteinDistance(a, b)
+		sd, sErr := Strings.LevenshteinDistance(p[0], p[1])
+		assert.Equal(t, d, sd, p[0], p[1])
+		assert.Equal(t, err, sErr)
+
+		d, err = DamerauLevenshteinDistance(a, b)
+		sd, sErr = Strings.DamerauLevenshteinDistance(p[0], p[1])
+		assert.Equal(t, d, sd, p[0], p[1])
+		assert.Equal(t, err, sErr)
+
+		for maxDistance := 0; maxDistance < 4; maxDistance++ {
+			d, ok := LevenshteinDistanceWithin(a, b, maxDistance)
+			sd, sOk, sErr := Strings.LevenshteinDistanceWithin(p[0], p[1], maxDistance)
+			assert.Equal(t, d, sd, p[0], p[1])
+			assert.Equal(t, ok, sOk)
+			assert.Nil(t, sErr)
+
+			d, ok = DamerauLevenshteinDistanceWithin(a, b, maxDistance)
+			sd, sOk, sErr = Strings.DamerauLevenshteinDistanceWithin(p[0], p[1], maxDistance)
+			assert.Equal(t, d, sd, p[0], p[1])
+			assert.Equal(t, ok, sOk)
+			assert.Nil(t, sErr)
+		}
+
+		h, err := HammingDistance(a, b)
+		sh, sErr := Strings.HammingDistance(p[0], p[1])
+		assert.Equal(t, h, sh, p[0], p[1])
+		assert.Equal(t, err == nil, sErr == nil)
+
+		s, err := DiceCoefficient(a, b)
+		ss, sErr := Strings.DiceCoefficient(p[0], p[1])
+		assert.Equal(t, s, ss, p[0], p[1])
+		assert.Equal(t, err, sErr)
+
+		s, err = DiceCoefficientTokenized(a, b, words)
+		ss, sErr = Strings.DiceCoefficientTokenized(p[0], p[1], words)
+		assert.Equal(t, s, ss, p[0], p[1])
+		assert.Equal(t, err, sErr)
+
+		s, err = WhiteSimilarity(a, b)
+		ss, sErr = Strings.WhiteSimilarity(p[0], p[1])
+		assert.Equal(t, s, ss, p[0], p[1])
+		assert.Equal(t, err, sErr)
+
+		s, err = WhiteSimilarityTokenized(a, b, words)
+		ss, sErr = Strings.WhiteSimilarityTokenized(p[0], p[1], words)
+		assert.Equal(t, s, ss, p[0], p[1])
+		assert.Equal(t, err, sErr)
+
+		ss, sErr = Strings.JaroSimilarity(p[0], p[1])
+		assert.Nil(t, sErr)
+		assert.Equal(t, JaroSimilarity(a, b), ss, p[0], p[1])
+
+		ss, sErr = Strings.JaroWinklerSimilarity(p[0], p[1])
+		assert.Nil(t, sErr)
+		assert.Equal(t, JaroWinklerSimilarity(a, b), ss, p[0], p[1])
+
+		ss, sErr = Strings.JaroWinklerSimilarityParametric(p[0], p[1], 0.2, 2, 0.5)
+		assert.Nil(t, sErr)
+		assert.Equal(t, JaroWinklerSimilarityParametric(a, b, 0.2, 2, 0.5), ss, p[0], p[1])
+	}
+}
+
+func Test_Strings_InvalidUTF8(t *testing.T) {
+	d, err := Strings.LevenshteinDistance("a\xffb", "a\xfeb")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, d, "Both invalid bytes are replaced with U+FFFD.")
+
+	asRunes := StringMetrics{Invalid: InvalidUTF8AsRunes}
+	d, err = asRunes.LevenshteinDistance("a\xffb", "a\xfeb")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, d, "Distinct invalid bytes remain distinct.")
+
+	d, err = asRunes.LevenshteinDistance("\xe9t\xe9", "\u00e9t\u00e9")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, d, "Latin-1 bytes decode as the runes of the same value.")
+
+	h, err := asRunes.HammingDistance("a\xffb", "a\xfeb")
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), h)
+
+	reject := StringMetrics{Invalid: RejectInvalidUTF8}
+	_, err = reject.LevenshteinDistance("valid", "in\xffvalid")
+	assert.NotNil(t, err)
+	_, err = reject.JaroSimilarity("in\xffvalid", "valid")
+	assert.NotNil(t, err)
+	_, err = reject.HammingDistance("ab", "a\xff")
+	assert.NotNil(t, err)
+	_, err = reject.HammingDistance("ab", "abc\xff")
+	assert.True(t, errors.Is(err, stralgo.ErrInvalidUTF8), "Invalid input is reported before unequal lengths.")
+	_, err = reject.HammingDistance("ab", "abc")
+	assert.True(t, errors.Is(err, stralgo.ErrUnequalLength))
+	var mismatch *stralgo.LengthMismatchError
+	_, err = Strings.HammingDistance("\u00e9t\u00e9", "ete\xff")
+	assert.True(t, errors.As(err, &mismatch))
+	assert.Equal(t, 3, mismatch.ALength)
+	assert.Equal(t, 4, mismatch.BLength, "Lengths are counted in runes.")
+
+	d, err = reject.LevenshteinDistance("caf\u00e9", "cafe")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, d)
+}
+
+func Test_Strings_DoesNotAllocateBuffers(t *testing.T) {
+	Strings.JaroSimilarity("warm", "pool")
+	allocs := testing.AllocsPerRun(100, func() {
+		Strings.JaroSimilarity("martha", "marhta")
+	})
+	jaroAllocs := testing.AllocsPerRun(100, func() {
+		JaroSimilarity([]rune("martha"), []rune("marhta"))
+	})
+	assert.True(t, allocs <= jaroAllocs, "Decoding adds no allocations to those of the metric itself.")
+
+	allocs = testing.AllocsPerRun(100, func() {
+		Strings.HammingDistance("karolin", "kathrin")
+	})
+	assert.Equal(t, 0.0, allocs)
+
+	if raceEnabled {
+		t.Skip("The race detector defeats sync.Pool.")
+	}
+	Strings.DiceCoefficient("warm", "pool")
+	allocs = testing.AllocsPerRun(100, func() {
+		Strings.DiceCoefficient("night at the opera", "a night at the opera")
+		Strings.WhiteSimilarity("Healed", "Sealed")
+	})
+	assert.Equal(t, 0.0, allocs, "The bigram metrics reuse the pooled Workspace.")
+}
+
+func Benchmark_Strings_LevenshteinDistance(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Strings.LevenshteinDistance("kitten", "sitting")
+		Strings.LevenshteinDistance("gumbo", "gambol")
+	}
+}
+
+func Benchmark_LevenshteinDistance_Converted(b *testing.B) {
+	x, y, z, w := "kitten", "sitting", "gumbo", "gambol"
+	for i := 0; i < b.N; i++ {
+		LevenshteinDistance([]rune(x), []rune(y))
+		LevenshteinDistance([]rune(z), []rune(w))
+	}
+}
+
+var (
+	longA = "The quick brown fox jumps over the lazy dog, and keeps running through the fields until dusk."
+	longB = "The quick brown fox jumped over the lazy dogs, then kept running through the field until dark."
+)
+
+func Benchmark_Strings_JaroWinklerSimilarity_Long(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Strings.JaroWinklerSimilarity(longA, longB)
+	}
+}
+
+func Benchmark_JaroWinklerSimilarity_Long_Converted(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		JaroWinklerSimilarity([]rune(longA), []rune(longB))
+	}
+}
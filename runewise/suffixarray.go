@@ -0,0 +1,192 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package runewise
+
+import (
+	"github.com/ZackPierce/stralgo/internal/sais"
+	"sort"
+)
+
+// SuffixArray returns the suffix array of r, runewise: the
+// rune indices of every non-empty suffix of r, sorted in
+// lexicographic order of rune values.
+//
+// The array is built in linear time (after an initial sort
+// of the distinct runes) using the SA-IS induced sorting
+// algorithm.
+//
+// See: http://en.wikipedia.org/wiki/Suffix_array
+func SuffixArray(r []rune) []int {
+	symbols, alphabetSize := runeSymbols(r)
+	return sais.Sort(symbols, alphabetSize)
+}
+
+// LCPArray returns the longest common prefix array for r
+// and its suffix array sa, as returned by SuffixArray.
+//
+// Element i holds the length, in runes, of the longest common
+// prefix of the suffixes starting at sa[i-1] and sa[i].
+// Element 0 is always 0.
+//
+// The array is built in linear time using Kasai's algorithm.
+//
+// See: http://en.wikipedia.org/wiki/LCP_array
+func LCPArray(r []rune, sa []int) []int {
+	symbols, _ := runeSymbols(r)
+	return sais.LCP(symbols, sa)
+}
+
+// runeSymbols replaces each rune with its rank among the
+// distinct runes of r, keeping the alphabet as small as
+// the input allows.
+func runeSymbols(r []rune) ([]int, int) {
+	distinct := make([]int, 0)
+	seen := make(map[rune]bool)
+	for _, c := range r {
+		if !seen[c] {
+			seen[c] = true
+			distinct = append(distinct, int(c))
+		}
+	}
+	sort.Ints(distinct)
+	ranks := make(map[rune]int, len(distinct))
+	for i, c := range distinct {
+		ranks[rune(c)] = i
+	}
+	symbols := make([]int, len(r), len(r))
+	for i, c := range r {
+		symbols[i] = ranks[c]
+	}
+	return symbols, len(distinct)
+}
+
+// SuffixIndex pairs a text with its suffix and LCP arrays
+// in order to answer substring queries without rescanning
+// the text.
+//
+// A SuffixIndex is immutable once built and may be shared
+// between goroutines, provided the indexed slice is not
+// modified.
+type SuffixIndex struct {
+	text []rune
+	sa   []int
+	lcp  []int
+}
+
+// NewSuffixIndex builds a SuffixIndex over text, runewise.
+func NewSuffixIndex(text []rune) *SuffixIndex {
+	symbols, alphabetSize := runeSymbols(text)
+	sa := sais.Sort(symbols, alphabetSize)
+	return &SuffixIndex{text: text, sa: sa, lcp: sais.LCP(symbols, sa)}
+}
+
+// SuffixArray returns the suffix array of the indexed text.
+// The returned slice must not be modified.
+func (x *SuffixIndex) SuffixArray() []int {
+	return x.sa
+}
+
+// LCPArray returns the LCP array of the indexed text.
+// The returned slice must not be modified.
+func (x *SuffixIndex) LCPArray() []int {
+	return x.lcp
+}
+
+// Count returns the number of (possibly overlapping)
+// occurrences of pattern within the indexed text, in time
+// proportional to the pattern length times the logarithm of
+// the text length.
+//
+// An empty pattern occurs at every index from 0 to len(text).
+func (x *SuffixIndex) Count(pattern []rune) int {
+	if len(pattern) == 0 {
+		return len(x.text) + 1
+	}
+	lo, hi := x.bounds(pattern)
+	return hi - lo
+}
+
+// Locate returns the rune indices of every (possibly
+// overlapping) occurrence of pattern within the indexed
+// text, in ascending order.
+//
+// An empty pattern occurs at every index from 0 to len(text).
+func (x *SuffixIndex) Locate(pattern []rune) []int {
+	if len(pattern) == 0 {
+		indices := make([]int, len(x.text)+1, len(x.text)+1)
+		for i := range indices {
+			indices[i] = i
+		}
+		return indices
+	}
+	lo, hi := x.bounds(pattern)
+	indices := make([]int, hi-lo, hi-lo)
+	copy(indices, x.sa[lo:hi])
+	sort.Ints(indices)
+	return indices
+}
+
+// bounds returns the half-open range of suffix array
+// entries whose suffixes begin with pattern.
+func (x *SuffixIndex) bounds(pattern []rune) (int, int) {
+	m := len(pattern)
+	prefix := func(i int) []rune {
+		suffix := x.text[x.sa[i]:]
+		if len(suffix) > m {
+			return suffix[:m]
+		}
+		return suffix
+	}
+	lo := sort.Search(len(x.sa), func(i int) bool {
+		return compareRunes(prefix(i), pattern) >= 0
+	})
+	hi := sort.Search(len(x.sa), func(i int) bool {
+		return compareRunes(prefix(i), pattern) > 0
+	})
+	return lo, hi
+}
+
+func compareRunes(a, b []rune) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
+
+// LongestRepeatedSubstring returns the longest substring which
+// occurs at least twice within the indexed text, where the
+// occurrences may overlap. If several candidates share the
+// greatest length, the lexicographically smallest is returned.
+//
+// Returns an empty slice if no rune is repeated.
+func (x *SuffixIndex) LongestRepeatedSubstring() []rune {
+	best := 0
+	at := 0
+	for i, l := range x.lcp {
+		if l > best {
+			best = l
+			at = x.sa[i]
+		}
+	}
+	return x.text[at : at+best]
+}
+
+// DistinctSubstringCount returns the number of distinct
+// non-empty substrings of the indexed text.
+func (x *SuffixIndex) DistinctSubstringCount() int {
+	n := len(x.text)
+	total := n * (n + 1) / 2
+	for _, l := range x.lcp {
+		total -= l
+	}
+	return total
+}
@@ -0,0 +1,31 @@
+﻿package runewise
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_SuffixArray(t *testing.T) {
+	assert.Equal(t, []int{5, 3, 1, 0, 4, 2}, SuffixArray([]rune("banana")))
+	assert.Equal(t, []int{5, 3, 1, 0, 4, 2}, SuffixArray([]rune("本日語日語日")))
+	assert.Equal(t, []int{}, SuffixArray(nil))
+}
+
+func Test_LCPArray(t *testing.T) {
+	r := []rune("本日語日語日")
+	assert.Equal(t, []int{0, 1, 3, 0, 0, 2}, LCPArray(r, SuffixArray(r)))
+}
+
+func Test_SuffixIndex(t *testing.T) {
+	x := NewSuffixIndex([]rune("日本語の日本"))
+	assert.Equal(t, 2, x.Count([]rune("日本")))
+	assert.Equal(t, []int{0, 4}, x.Locate([]rune("日本")), "Offsets are rune indices.")
+	assert.Equal(t, 0, x.Count([]rune("本日")))
+	assert.Equal(t, 7, x.Count(nil))
+	assert.Equal(t, []rune("日本"), x.LongestRepeatedSubstring())
+	assert.Equal(t, 18, x.DistinctSubstringCount())
+	assert.Equal(t, x.LCPArray(), LCPArray([]rune("日本語の日本"), x.SuffixArray()))
+
+	x = NewSuffixIndex([]rune("abc"))
+	assert.Equal(t, 0, len(x.LongestRepeatedSubstring()))
+}
@@ -0,0 +1,143 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package runewise
+
+import (
+	"github.com/ZackPierce/stralgo"
+	"github.com/ZackPierce/stralgo/sequence"
+	"slices"
+	"unicode"
+)
+
+// Workspace holds the buffers used by the runewise metrics, so
+// that they may be reused from one comparison to the next. Once
+// its buffers have grown to fit the longest strings compared,
+// its methods do not allocate, which suits batch jobs making
+// many comparisons.
+//
+// Each method returns the same result as the package-level
+// function of the same name.
+//
+// The zero value is an empty Workspace ready for use. A
+// Workspace must not be used by more than one goroutine at a
+// time; keep one per goroutine instead:
+//
+//	var w runewise.Workspace
+//	for _, pair := range pairs {
+//		d, err := w.LevenshteinDistance(pair.a, pair.b)
+//		...
+//	}
+type Workspace struct {
+	sequence           sequence.Workspace[rune]
+	bigramsA, bigramsB []uint64
+}
+
+// LevenshteinDistance calculates the Levenshtein distance
+// between two strings. See LevenshteinDistance.
+func (w *Workspace) LevenshteinDistance(a, b []rune) (int, error) {
+	return w.sequence.LevenshteinDistance(a, b)
+}
+
+// DamerauLevenshteinDistance calculates the Damerau-Levenshtein
+// distance between two strings. See DamerauLevenshteinDistance.
+func (w *Workspace) DamerauLevenshteinDistance(a, b []rune) (int, error) {
+	return w.sequence.DamerauLevenshteinDistance(a, b)
+}
+
+// LevenshteinDistanceWithin calculates the Levenshtein distance
+// between two strings, if it is no greater than maxDistance. See
+// LevenshteinDistanceWithin.
+func (w *Workspace) LevenshteinDistanceWithin(a, b []rune, maxDistance int) (int, bool) {
+	return w.sequence.LevenshteinDistanceWithin(a, b, maxDistance)
+}
+
+// DamerauLevenshteinDistanceWithin calculates the
+// Damerau-Levenshtein distance between two strings, if it is no
+// greater than maxDistance. See DamerauLevenshteinDistanceWithin.
+func (w *Workspace) DamerauLevenshteinDistanceWithin(a, b []rune, maxDistance int) (int, bool) {
+	return w.sequence.DamerauLevenshteinDistanceWithin(a, b, maxDistance)
+}
+
+// JaroSimilarity calculates the Jaro similarity of two strings.
+// See JaroSimilarity.
+func (w *Workspace) JaroSimilarity(a, b []rune) float64 {
+	return w.sequence.JaroSimilarity(a, b)
+}
+
+// JaroWinklerSimilarity calculates the Jaro-Winkler similarity
+// of two strings. See JaroWinklerSimilarity.
+func (w *Workspace) JaroWinklerSimilarity(a, b []rune) float64 {
+	return w.sequence.JaroWinklerSimilarity(a, b)
+}
+
+// JaroWinklerSimilarityParametric calculates the Jaro-Winkler
+// similarity of two strings with the given parameters. See
+// JaroWinklerSimilarityParametric.
+func (w *Workspace) JaroWinklerSimilarityParametric(a, b []rune, prefixScale float64, maxPrefixLength int, boostThreshold float64) float64 {
+	return w.sequence.JaroWinklerSimilarityParametric(a, b, prefixScale, maxPrefixLength, boostThreshold)
+}
+
+// DiceCoefficient calculates the Sorensen-Dice coefficient of
+// two strings. See DiceCoefficient.
+func (w *Workspace) DiceCoefficient(a, b []rune) (float64, error) {
+	if len(a) < 2 && len(b) < 2 {
+		return 0, stralgo.ErrInsufficientBigrams
+	}
+	w.bigramsA = slices.Compact(sortedBigrams(w.bigramsA[:0], a, false))
+	w.bigramsB = slices.Compact(sortedBigrams(w.bigramsB[:0], b, false))
+	shared := sharedBigrams(w.bigramsA, w.bigramsB)
+	return 2 * float64(shared) / float64(len(w.bigramsA)+len(w.bigramsB)), nil
+}
+
+// WhiteSimilarity calculates the White similarity of two
+// strings. See WhiteSimilarity.
+func (w *Workspace) WhiteSimilarity(a, b []rune) (float64, error) {
+	w.bigramsA = sortedBigrams(w.bigramsA[:0], a, true)
+	w.bigramsB = sortedBigrams(w.bigramsB[:0], b, true)
+	total := len(w.bigramsA) + len(w.bigramsB)
+	if total == 0 {
+		return 0.0, stralgo.ErrInsufficientBigrams
+	}
+	return 2 * float64(sharedBigrams(w.bigramsA, w.bigramsB)) / float64(total), nil
+}
+
+// sortedBigrams appends the rune bigrams of s to buf, packed
+// into uint64s, and sorts them. For WhiteSimilarity, the runes
+// are upper-cased and bigrams including whitespace are skipped.
+func sortedBigrams(buf []uint64, s []rune, white bool) []uint64 {
+	for i := 1; i < len(s); i++ {
+		first, second := s[i-1], s[i]
+		if white {
+			first, second = unicode.ToUpper(first), unicode.ToUpper(second)
+			if unicode.IsSpace(first) || unicode.IsSpace(second) {
+				continue
+			}
+		}
+		buf = append(buf, uint64(uint32(first))<<32|uint64(uint32(second)))
+	}
+	slices.Sort(buf)
+	return buf
+}
+
+// sharedBigrams counts the bigrams common to the sorted a and
+// b, matching each occurrence in a with at most one in b.
+func sharedBigrams(a, b []uint64) int {
+	shared := 0
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			shared++
+			i++
+			j++
+		}
+	}
+	return shared
+}
@@ -0,0 +1,146 @@
+﻿package runewise
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+func Test_Workspace_AgreesWithFunctions(t *testing.T) {
+	alphabet := []rune{'a', 'b', 'A', 'B', ' ', '\t', 'é', 'É', -1, 0xD800}
+	r := rand.New(rand.NewSource(7))
+	random := func() []rune {
+		s := make([]rune, r.Intn(12), 12)
+		for i := range s {
+			s[i] = alphabet[r.Intn(len(alphabet))]
+		}
+		return s
+	}
+	var w Workspace
+	for i := 0; i < 20000; i++ {
+		a, b := random(), random()
+
+		d, err := LevenshteinDistance(a, b)
+		wd, wErr := w.LevenshteinDistance(a, b)
+		assert.Equal(t, d, wd)
+		assert.Equal(t, err, wErr)
+
+		d, err = DamerauLevenshteinDistance(a, b)
+		wd, wErr = w.DamerauLevenshteinDistance(a, b)
+		assert.Equal(t, d, wd)
+		assert.Equal(t, err, wErr)
+
+		assert.Equal(t, JaroSimilarity(a, b), w.JaroSimilarity(a, b))
+		assert.Equal(t, JaroWinklerSimilarity(a, b), w.JaroWinklerSimilarity(a, b))
+		assert.Equal(t, JaroWinklerSimilarityParametric(a, b, 0.2, 3, 0.5), w.JaroWinklerSimilarityParametric(a, b, 0.2, 3, 0.5))
+
+		s, err := DiceCoefficient(a, b)
+		ws, wErr := w.DiceCoefficient(a, b)
+		EqualWithin(t, s, ws, 1e-12, string(a), string(b))
+		assert.Equal(t, err, wErr)
+
+		s, err = WhiteSimilarity(a, b)
+		ws, wErr = w.WhiteSimilarity(a, b)
+		EqualWithin(t, s, ws, 1e-12, string(a), string(b))
+		assert.Equal(t, err, wErr)
+	}
+}
+
+func Test_Workspace_DoesNotAllocate(t *testing.T) {
+	var w Workspace
+	a, b := []rune("The quick brown fox jumps over the lazy dog"), []rune("The quick brown dog jumps over the lazy fox")
+	w.LevenshteinDistance(a, b)
+	w.DamerauLevenshteinDistance(a, b)
+	w.JaroWinklerSimilarity(a, b)
+	w.DiceCoefficient(a, b)
+	w.WhiteSimilarity(a, b)
+	short, long := []rune("kitten"), []rune("sitting")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		w.LevenshteinDistance(a, b)
+		w.LevenshteinDistance(short, long)
+		w.DamerauLevenshteinDistance(a, b)
+		w.JaroSimilarity(a, b)
+		w.JaroWinklerSimilarity(short, long)
+		w.DiceCoefficient(a, b)
+		w.WhiteSimilarity(short, long)
+	})
+	assert.Equal(t, 0.0, allocs, "A warmed-up Workspace allocates nothing.")
+}
+
+func Benchmark_Workspace_LevenshteinDistance(b *testing.B) {
+	var w Workspace
+	x, y := []rune("kitten"), []rune("sitting")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.LevenshteinDistance(x, y)
+	}
+}
+
+func Benchmark_Workspace_DamerauLevenshteinDistance(b *testing.B) {
+	var w Workspace
+	x, y := []rune("azertyuiop"), []rune("aeryuop")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.DamerauLevenshteinDistance(x, y)
+	}
+}
+
+func Benchmark_DamerauLevenshteinDistance(b *testing.B) {
+	x, y := []rune("azertyuiop"), []rune("aeryuop")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		DamerauLevenshteinDistance(x, y)
+	}
+}
+
+func Benchmark_Workspace_JaroWinklerSimilarity(b *testing.B) {
+	var w Workspace
+	x, y := []rune("martha"), []rune("marhta")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.JaroWinklerSimilarity(x, y)
+	}
+}
+
+func Benchmark_JaroWinklerSimilarity(b *testing.B) {
+	x, y := []rune("martha"), []rune("marhta")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		JaroWinklerSimilarity(x, y)
+	}
+}
+
+func Benchmark_Workspace_DiceCoefficient(b *testing.B) {
+	var w Workspace
+	x, y := []rune("night at the opera"), []rune("a night at the opera")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.DiceCoefficient(x, y)
+	}
+}
+
+func Benchmark_DiceCoefficient(b *testing.B) {
+	x, y := []rune("night at the opera"), []rune("a night at the opera")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		DiceCoefficient(x, y)
+	}
+}
+
+func Benchmark_Workspace_WhiteSimilarity(b *testing.B) {
+	var w Workspace
+	x, y := []rune("Healed the sick"), []rune("Sealed the deal")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.WhiteSimilarity(x, y)
+	}
+}
+
+func Benchmark_WhiteSimilarity(b *testing.B) {
+	x, y := []rune("Healed the sick"), []rune("Sealed the deal")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		WhiteSimilarity(x, y)
+	}
+}
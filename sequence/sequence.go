@@ -0,0 +1,467 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+/*
+Package stralgo/sequence implements the edit distances and
+similarity metrics of runewise upon slices of any element type,
+such as token slices, DNA sequences or []int64 event streams.
+
+Each metric is offered for slices of comparable elements, which
+are compared with ==, and as a Func variant taking an equality
+function, for elements which are not comparable or which should
+be compared more loosely. The comparable variants avoid the cost
+of calling an equality function, and are the faster of the two:
+
+	d, err := sequence.LevenshteinDistanceFunc(a, b, strings.EqualFold)
+
+The comparable variants are also offered as methods of
+Workspace, which reuses its buffers from one comparison to the
+next, and so does not allocate once it has grown to fit the
+sequences compared.
+
+The edit distances of runewise and bytewise, and the Jaro
+metrics of runewise, are implemented upon this package.
+*/
+package sequence
+
+import (
+	"github.com/ZackPierce/stralgo"
+)
+
+const (
+	WinklerBoostThreshold  = 0.7 // JaroWinklerSimilarity suggested parameter. If the JaroSimilarity for the compared sequences is above this value, add an additional boost factor based on the shared prefix length and prefix scale.
+	WinklerMaxPrefixLength = 4   // JaroWinklerSimilarity suggested parameter. Used to control the maximum size of identical prefixes used in the prefix boost factor.
+	WinklerPrefixScale     = 0.1 // JaroWinklerSimilarity suggested parameter. Used to control the scale of bonus added for a pair having a JaroSimilarity above the threshold and with shared prefixes.
+)
+
+func equal[T comparable](x, y T) bool {
+	return x == y
+}
+
+// HammingDistance calculates the Hamming distance between
+// two sequences of equal length.
+//
+// The Hamming distance is the total number of elements
+// that differ at the same index within the two sequences.
+//
+// The higher the result, the more different the sequences.
+//
+// See: http://en.wikipedia.org/wiki/Hamming_distance
+//
+// Returns a *stralgo.LengthMismatchError, which matches
+// stralgo.ErrUnequalLength, if the sequence lengths are not equal.
+func HammingDistance[T comparable](a, b []T) (uint, error) {
+	if len(a) != len(b) {
+		return 0, &stralgo.LengthMismatchError{Metric: "Hamming distance", ALength: len(a), BLength: len(b)}
+	}
+	var d uint
+	for i := range a {
+		if a[i] != b[i] {
+			d++
+		}
+	}
+	return d, nil
+}
+
+// HammingDistanceFunc is like HammingDistance, but compares
+// elements with the given equality function.
+func HammingDistanceFunc[T any](a, b []T, equal func(x, y T) bool) (uint, error) {
+	if len(a) != len(b) {
+		return 0, &stralgo.LengthMismatchError{Metric: "Hamming distance", ALength: len(a), BLength: len(b)}
+	}
+	var d uint
+	for i := range a {
+		if !equal(a[i], b[i]) {
+			d++
+		}
+	}
+	return d, nil
+}
+
+// LevenshteinDistance calculates the magnitude of
+// difference between two sequences using the
+// Levenshtein Distance metric.
+//
+// This edit distance is the minimum number of single-element
+// edits (insertions, deletions, or substitutions) needed
+// to transform one sequence into the other.
+//
+// The larger the result, the more different the sequences.
+//
+// See: http://en.wikipedia.org/wiki/Levenshtein_distance
+func LevenshteinDistance[T comparable](a, b []T) (int, error) {
+	var w Workspace[T]
+	return w.LevenshteinDistance(a, b)
+}
+
+// LevenshteinDistanceFunc is like LevenshteinDistance, but
+// compares elements with the given equality function.
+func LevenshteinDistanceFunc[T any](a, b []T, equal func(x, y T) bool) (int, error) {
+	aLen := len(a)
+	bLen := len(b)
+	if aLen == 0 {
+		return bLen, nil
+	}
+	if bLen == 0 {
+		return aLen, nil
+	}
+
+	rowLen := bLen + 1
+	prevRow := make([]int, rowLen, rowLen)
+	currRow := make([]int, rowLen, rowLen)
+	for h := 0; h < rowLen; h++ {
+		prevRow[h] = h
+	}
+	cost := 0
+	for i := 0; i < aLen; i++ {
+		currRow[0] = i + 1
+		for j := 0; j < bLen; j++ {
+			if equal(a[i], b[j]) {
+				cost = 0
+			} else {
+				cost = 1
+			}
+			currRow[j+1] = min(
+				currRow[j]+1,
+				prevRow[j+1]+1,
+				prevRow[j]+cost)
+		}
+		prevRow, currRow = currRow, prevRow
+	}
+	return prevRow[bLen], nil
+}
+
+// DamerauLevenshteinDistance calculates the magnitude
+// of difference between two sequences using the Damerau-
+// Levenshtein algorithm with adjacent-only transpositions.
+//
+// This edit distance is the minimum number of single-element
+// edits (insertions, deletions, substitutions, or
+// transpositions) to transform one sequence into the other.
+// DamerauLevenshtein differs from Levenshtein primarily
+// in that DamerauLevenshtein considers adjacent-element
+// transpositions.
+//
+// The larger the result, the more different the sequences.
+//
+// See: http://en.wikipedia.org/wiki/Damerau-Levenshtein_distance
+func DamerauLevenshteinDistance[T comparable](a, b []T) (int, error) {
+	var w Workspace[T]
+	return w.DamerauLevenshteinDistance(a, b)
+}
+
+// DamerauLevenshteinDistanceFunc is like DamerauLevenshteinDistance,
+// but compares elements with the given equality function.
+func DamerauLevenshteinDistanceFunc[T any](a, b []T, equal func(x, y T) bool) (int, error) {
+	aLen := len(a)
+	bLen := len(b)
+	if aLen == 0 {
+		return bLen, nil
+	} else if bLen == 0 {
+		return aLen, nil
+	}
+
+	// Swap to ensure a contains the shorter slice
+	if aLen > bLen {
+		a, aLen, b, bLen = b, bLen, a, aLen
+	}
+	rowLen := aLen + 1
+	tranRow := make([]int, rowLen, rowLen)
+	prevRow := make([]int, rowLen, rowLen)
+	currRow := make([]int, rowLen, rowLen)
+	for h := 0; h < rowLen; h++ {
+		prevRow[h] = h
+	}
+	var cost int
+	for i := 1; i <= bLen; i++ {
+		currB := b[i-1]
+		currRow[0] = i
+		for j := 1; j <= aLen; j++ {
+			currA := a[j-1]
+			same := equal(currA, currB)
+			if same {
+				cost = 0
+			} else {
+				cost = 1
+			}
+			entry := min(
+				currRow[j-1]+1,
+				prevRow[j]+1,
+				prevRow[j-1]+cost)
+			if !same && i > 1 && j > 1 && equal(currA, b[i-2]) && equal(a[j-2], currB) {
+				trans := tranRow[j-2] + 1
+				if trans < entry {
+					entry = trans
+				}
+			}
+			currRow[j] = entry
+		}
+		tranRow, prevRow, currRow = prevRow, currRow, tranRow
+	}
+	return prevRow[aLen], nil
+}
+
+// LevenshteinDistanceWithin calculates the Levenshtein distance
+// between two sequences if it is no greater than maxDistance,
+// stopping early once it is known to be greater.
+//
+// It returns the distance and true if the distance is no greater
+// than maxDistance. Otherwise it returns false, along with
+// maxDistance+1, a lower bound on the distance, or 0 if
+// maxDistance is negative.
+//
+// The result takes time proportional to the length of the
+// sequences multiplied by maxDistance, rather than by each
+// other's length, and so suits searches for close matches.
+func LevenshteinDistanceWithin[T comparable](a, b []T, maxDistance int) (int, bool) {
+	var w Workspace[T]
+	return w.LevenshteinDistanceWithin(a, b, maxDistance)
+}
+
+// DamerauLevenshteinDistanceWithin calculates the
+// Damerau-Levenshtein distance between two sequences if it is no
+// greater than maxDistance, stopping early once it is known to be
+// greater. Its results are as for LevenshteinDistanceWithin.
+func DamerauLevenshteinDistanceWithin[T comparable](a, b []T, maxDistance int) (int, bool) {
+	var w Workspace[T]
+	return w.DamerauLevenshteinDistanceWithin(a, b, maxDistance)
+}
+
+// LongestCommonSubsequence returns a longest sequence of
+// elements which occur in both a and b in the same order,
+// though not necessarily contiguously. Where there are several
+// such sequences, the elements are taken from a.
+//
+// The result takes time and space proportional to the product
+// of the sequence lengths. LongestCommonSubsequenceLength finds
+// only the length, in space proportional to the shorter
+// sequence.
+//
+// See: http://en.wikipedia.org/wiki/Longest_common_subsequence_problem
+func LongestCommonSubsequence[T comparable](a, b []T) []T {
+	return LongestCommonSubsequenceFunc(a, b, equal[T])
+}
+
+// LongestCommonSubsequenceFunc is like LongestCommonSubsequence,
+// but compares elements with the given equality function.
+func LongestCommonSubsequenceFunc[T any](a, b []T, equal func(x, y T) bool) []T {
+	aLen := len(a)
+	bLen := len(b)
+	// lengths[i*(bLen+1)+j] is the length of the longest
+	// common subsequence of a[i:] and b[j:].
+	rowLen := bLen + 1
+	lengths := make([]int, (aLen+1)*rowLen, (aLen+1)*rowLen)
+	for i := aLen - 1; i >= 0; i-- {
+		for j := bLen - 1; j >= 0; j-- {
+			if equal(a[i], b[j]) {
+				lengths[i*rowLen+j] = lengths[(i+1)*rowLen+j+1] + 1
+			} else if down, right := lengths[(i+1)*rowLen+j], lengths[i*rowLen+j+1]; down >= right {
+				lengths[i*rowLen+j] = down
+			} else {
+				lengths[i*rowLen+j] = right
+			}
+		}
+	}
+	subsequence := make([]T, 0, lengths[0])
+	for i, j := 0, 0; i < aLen && j < bLen; {
+		switch {
+		case equal(a[i], b[j]):
+			subsequence = append(subsequence, a[i])
+			i++
+			j++
+		case lengths[(i+1)*rowLen+j] >= lengths[i*rowLen+j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return subsequence
+}
+
+// LongestCommonSubsequenceLength returns the length of the
+// longest sequence of elements which occur in both a and b in
+// the same order, though not necessarily contiguously.
+//
+// See: http://en.wikipedia.org/wiki/Longest_common_subsequence_problem
+func LongestCommonSubsequenceLength[T comparable](a, b []T) int {
+	var w Workspace[T]
+	return w.LongestCommonSubsequenceLength(a, b)
+}
+
+// LongestCommonSubsequenceLengthFunc is like
+// LongestCommonSubsequenceLength, but compares elements with
+// the given equality function.
+func LongestCommonSubsequenceLengthFunc[T any](a, b []T, equal func(x, y T) bool) int {
+	if len(a) < len(b) {
+		a, b = b, a
+		eq := equal
+		equal = func(x, y T) bool {
+			return eq(y, x)
+		}
+	}
+	rowLen := len(b) + 1
+	prevRow := make([]int, rowLen, rowLen)
+	currRow := make([]int, rowLen, rowLen)
+	for i := range a {
+		for j := range b {
+			if equal(a[i], b[j]) {
+				currRow[j+1] = prevRow[j] + 1
+			} else if prevRow[j+1] >= currRow[j] {
+				currRow[j+1] = prevRow[j+1]
+			} else {
+				currRow[j+1] = currRow[j]
+			}
+		}
+		prevRow, currRow = currRow, prevRow
+	}
+	return prevRow[len(b)]
+}
+
+// JaroSimilarity calculates the similarity between two sequences
+// using the original Jaro distance formula.
+//
+// The result is between 0 and 1.0, and the higher the score,
+// the more similar the two sequences are. 1.0 is a perfect match.
+//
+// If either sequence is empty or nil, the result will be 0.0.
+// This is due to a quirk in the formal definition of the
+// algorithm which counts the number of matching elements.
+// In the empty or nil cases, no matches may be found at all.
+//
+// See (the first half of) : http://en.wikipedia.org/wiki/Jaro-Winkler_distance
+//
+// See also : http://alias-i.com/lingpipe/docs/api/com/aliasi/spell/JaroWinklerDistance.html
+func JaroSimilarity[T comparable](a, b []T) float64 {
+	var w Workspace[T]
+	return w.JaroSimilarity(a, b)
+}
+
+// JaroSimilarityFunc is like JaroSimilarity, but compares
+// elements with the given equality function.
+func JaroSimilarityFunc[T any](a, b []T, equal func(x, y T) bool) float64 {
+	matches, transpositions := jaroMatchesAndHalfTranspositions(a, b, equal)
+
+	if matches == 0 {
+		return 0.0
+	}
+
+	matchFloat := float64(matches)
+	return (1.0 / 3.0) * (matchFloat/float64(len(a)) + matchFloat/float64(len(b)) + (matchFloat-float64(transpositions/2))/matchFloat)
+}
+
+// jaroMatchesAndHalfTranspositions calculates the number of
+// matches and half-transpositions defined by the Jaro distance
+// formula. Each element of a is matched with the first unmatched
+// equal element of b within the match window.
+func jaroMatchesAndHalfTranspositions[T any](a, b []T, equal func(x, y T) bool) (int, int) {
+	aLen := len(a)
+	bLen := len(b)
+	if aLen == 0 || bLen == 0 {
+		return 0, 0
+	}
+	longest := aLen
+	if bLen > longest {
+		longest = bLen
+	}
+	matchMax := (longest / 2) - 1
+	if matchMax < 0 {
+		matchMax = 0
+	}
+	aMatched := make([]bool, aLen, aLen)
+	bMatched := make([]bool, bLen, bLen)
+	matches := 0
+	for i := range a {
+		from := i - matchMax
+		if from < 0 {
+			from = 0
+		}
+		to := i + matchMax
+		if to >= bLen {
+			to = bLen - 1
+		}
+		for j := from; j <= to; j++ {
+			if !bMatched[j] && equal(a[i], b[j]) {
+				aMatched[i] = true
+				bMatched[j] = true
+				matches++
+				break
+			}
+		}
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range a {
+		if !aMatched[i] {
+			continue
+		}
+		for !bMatched[j] {
+			j++
+		}
+		if !equal(a[i], b[j]) {
+			transpositions++
+		}
+		j++
+	}
+	return matches, transpositions
+}
+
+// JaroWinklerSimilarity calculates the similarity between
+// two sequences using the Jaro-Winkler distance formula, with
+// Winkler's suggested constants for max considered common
+// prefix length (4), common prefix scaling factor (0.1), and
+// boost threshold (0.7).
+//
+// The result is between 0 and 1.0, and the higher the score,
+// the more similar the two sequences are. 1.0 is a perfect match.
+//
+// See runewise.JaroWinklerSimilarity for a description of the
+// boost threshold.
+//
+// See : http://en.wikipedia.org/wiki/Jaro-Winkler_distance
+func JaroWinklerSimilarity[T comparable](a, b []T) float64 {
+	var w Workspace[T]
+	return w.JaroWinklerSimilarity(a, b)
+}
+
+// JaroWinklerSimilarityFunc is like JaroWinklerSimilarity, but
+// compares elements with the given equality function.
+func JaroWinklerSimilarityFunc[T any](a, b []T, equal func(x, y T) bool) float64 {
+	return JaroWinklerSimilarityParametricFunc(a, b, equal, WinklerPrefixScale, WinklerMaxPrefixLength, WinklerBoostThreshold)
+}
+
+// JaroWinklerSimilarityParametric calculates similarity between
+// two sequences using the Jaro-Winkler distance formula.
+//
+// The product of prefixScale and maxPrefixLength should be between 0.0 and 1.0.
+// Assuming this is true, the result will be between 0 and 1.0.
+//
+// See : http://en.wikipedia.org/wiki/Jaro-Winkler_distance
+func JaroWinklerSimilarityParametric[T comparable](a, b []T, prefixScale float64, maxPrefixLength int, boostThreshold float64) float64 {
+	var w Workspace[T]
+	return w.JaroWinklerSimilarityParametric(a, b, prefixScale, maxPrefixLength, boostThreshold)
+}
+
+// JaroWinklerSimilarityParametricFunc is like
+// JaroWinklerSimilarityParametric, but compares elements with
+// the given equality function.
+func JaroWinklerSimilarityParametricFunc[T any](a, b []T, equal func(x, y T) bool, prefixScale float64, maxPrefixLength int, boostThreshold float64) float64 {
+	j := JaroSimilarityFunc(a, b, equal)
+	if j < boostThreshold {
+		return j
+	}
+	return j + float64(clampedSharedPrefixLength(a, b, equal, maxPrefixLength))*prefixScale*(1.0-j)
+}
+
+func clampedSharedPrefixLength[T any](a, b []T, equal func(x, y T) bool, maxPrefixLength int) int {
+	minLen := min(len(a), len(b), maxPrefixLength)
+	i := 0
+	for ; i < minLen; i++ {
+		if !equal(a[i], b[i]) {
+			return i
+		}
+	}
+	return i
+}
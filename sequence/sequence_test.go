@@ -0,0 +1,185 @@
+﻿package sequence
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func Test_HammingDistance(t *testing.T) {
+	d, err := HammingDistance([]int64{1, 2, 3, 4}, []int64{1, 5, 3, 6})
+	assert.Nil(t, err)
+	assert.Equal(t, uint(2), d)
+
+	_, err = HammingDistance([]int64{1, 2}, []int64{1})
+	assert.NotNil(t, err, "Sequences of unequal length have no Hamming distance.")
+
+	d, err = HammingDistanceFunc(strings.Fields("The quick fox"), strings.Fields("the QUICK dog"), strings.EqualFold)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), d)
+}
+
+func Test_LevenshteinDistance(t *testing.T) {
+	d, err := LevenshteinDistance([]byte("GATTACA"), []byte("GCATGCU"))
+	assert.Nil(t, err)
+	assert.Equal(t, 4, d)
+
+	d, err = LevenshteinDistance(strings.Fields("the cat sat on the mat"), strings.Fields("the cat sat on a mat"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, d, "Tokens are compared whole.")
+
+	d, err = LevenshteinDistance([]int64{}, []int64{7, 8})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, d)
+
+	d, err = LevenshteinDistance[int64](nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, d)
+
+	d, err = LevenshteinDistanceFunc(strings.Fields("The Cat sat"), strings.Fields("the cat SAT down"), strings.EqualFold)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, d)
+}
+
+func Test_DamerauLevenshteinDistance(t *testing.T) {
+	d, err := DamerauLevenshteinDistance([]rune("ca"), []rune("ac"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, d, "An adjacent transposition is a single edit.")
+
+	d, err = DamerauLevenshteinDistance([]rune("ca"), []rune("abc"))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, d, "Transposed elements may not be edited again.")
+
+	d, err = DamerauLevenshteinDistance([]int64{1, 2, 3, 4}, []int64{2, 1, 3})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, d)
+
+	d, err = DamerauLevenshteinDistance([]rune{0, 'a'}, []rune{'a', 0})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, d, "Zero values are ordinary elements.")
+
+	d, err = DamerauLevenshteinDistanceFunc(strings.Fields("Jane Q Public"), strings.Fields("public jane q"), strings.EqualFold)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, d)
+}
+
+func Test_DistanceWithin(t *testing.T) {
+	d, ok := LevenshteinDistanceWithin([]byte("kitten"), []byte("sitting"), 3)
+	assert.Equal(t, 3, d)
+	assert.True(t, ok)
+	d, ok = LevenshteinDistanceWithin([]byte("kitten"), []byte("sitting"), 2)
+	assert.Equal(t, 3, d)
+	assert.False(t, ok)
+	d, ok = DamerauLevenshteinDistanceWithin([]byte("abcd"), []byte("bacd"), 1)
+	assert.Equal(t, 1, d)
+	assert.True(t, ok)
+	d, ok = LevenshteinDistanceWithin([]byte("abcd"), []byte("bacd"), 1)
+	assert.Equal(t, 2, d)
+	assert.False(t, ok)
+	d, ok = LevenshteinDistanceWithin([]byte("a"), []byte("a"), -1)
+	assert.Equal(t, 0, d)
+	assert.False(t, ok)
+	d, ok = LevenshteinDistanceWithin(nil, []byte("abc"), 3)
+	assert.Equal(t, 3, d)
+	assert.True(t, ok)
+
+	r := rand.New(rand.NewSource(11))
+	random := func() []byte {
+		s := make([]byte, r.Intn(14), 14)
+		for i := range s {
+			s[i] = "abc"[r.Intn(3)]
+		}
+		return s
+	}
+	var w Workspace[byte]
+	for i := 0; i < 20000; i++ {
+		a, b := random(), random()
+		maxDistance := r.Intn(8)
+		exact, _ := LevenshteinDistance(a, b)
+		d, ok := w.LevenshteinDistanceWithin(a, b, maxDistance)
+		if exact <= maxDistance {
+			assert.True(t, ok && d == exact, "%q %q %d", a, b, maxDistance)
+		} else {
+			assert.True(t, !ok && d == maxDistance+1, "%q %q %d", a, b, maxDistance)
+		}
+		exact, _ = DamerauLevenshteinDistance(a, b)
+		d, ok = w.DamerauLevenshteinDistanceWithin(a, b, maxDistance)
+		if exact <= maxDistance {
+			assert.True(t, ok && d == exact, "%q %q %d", a, b, maxDistance)
+		} else {
+			assert.True(t, !ok && d == maxDistance+1, "%q %q %d", a, b, maxDistance)
+		}
+	}
+}
+
+func Test_LongestCommonSubsequence(t *testing.T) {
+	lcs := LongestCommonSubsequence([]rune("AGGTAB"), []rune("GXTXAYB"))
+	assert.Equal(t, "GTAB", string(lcs))
+	assert.Equal(t, 4, LongestCommonSubsequenceLength([]rune("AGGTAB"), []rune("GXTXAYB")))
+	assert.Equal(t, 4, LongestCommonSubsequenceLength([]rune("GXTXAYB"), []rune("AGGTAB")))
+
+	assert.Equal(t, 0, len(LongestCommonSubsequence([]int64{1, 2}, nil)))
+	assert.Equal(t, 0, LongestCommonSubsequenceLength(nil, []int64{1, 2}))
+
+	events := LongestCommonSubsequence([]int64{10, 20, 30, 40, 50}, []int64{20, 25, 40, 50, 60})
+	assert.Equal(t, []int64{20, 40, 50}, events)
+
+	words := LongestCommonSubsequenceFunc(strings.Fields("A b C d"), strings.Fields("a x c D"), strings.EqualFold)
+	assert.Equal(t, []string{"A", "C", "d"}, words, "Elements are taken from the first sequence.")
+	assert.Equal(t, 3, LongestCommonSubsequenceLengthFunc(strings.Fields("a x c D"), strings.Fields("A b C d e"), strings.EqualFold))
+}
+
+func Test_JaroSimilarity(t *testing.T) {
+	assert.Equal(t, 0.0, JaroSimilarity[int64](nil, nil))
+	assert.Equal(t, 1.0, JaroSimilarity([]int64{1, 2, 3}, []int64{1, 2, 3}))
+	EqualWithin(t, 0.9444444, JaroSimilarity([]byte("MARTHA"), []byte("MARHTA")), 0.0001)
+	EqualWithin(t, 0.8222222, JaroSimilarity([]byte("DWAYNE"), []byte("DUANE")), 0.0001)
+	EqualWithin(t, 0.7666666, JaroSimilarity([]byte("DIXON"), []byte("DICKSONX")), 0.0001)
+	EqualWithin(t, 0.9444444, JaroSimilarityFunc([]rune("martha"), []rune("MARHTA"), func(x, y rune) bool {
+		return x == y || x-'a'+'A' == y
+	}), 0.0001)
+}
+
+func Test_JaroWinklerSimilarity(t *testing.T) {
+	assert.Equal(t, 0.0, JaroWinklerSimilarity[int64](nil, []int64{1}))
+	EqualWithin(t, 0.9611111, JaroWinklerSimilarity([]byte("MARTHA"), []byte("MARHTA")), 0.0001)
+	EqualWithin(t, 0.8400000, JaroWinklerSimilarity([]byte("DWAYNE"), []byte("DUANE")), 0.0001)
+	EqualWithin(t, 0.8133333, JaroWinklerSimilarity([]byte("DIXON"), []byte("DICKSONX")), 0.0001)
+	EqualWithin(t, 0.9611111, JaroWinklerSimilarityFunc(strings.Split("m a r t h a", " "), strings.Split("M A R H T A", " "), strings.EqualFold), 0.0001)
+	EqualWithin(t, 0.9444444, JaroWinklerSimilarityParametric([]byte("MARTHA"), []byte("MARHTA"), 0.1, 4, 0.95), 0.0001, "Below the boost threshold, no prefix bonus is given.")
+}
+
+func Benchmark_LevenshteinDistance(b *testing.B) {
+	x, y := []rune("kitten"), []rune("sitting")
+	for i := 0; i < b.N; i++ {
+		LevenshteinDistance(x, y)
+	}
+}
+
+func Benchmark_LevenshteinDistanceFunc(b *testing.B) {
+	x, y := []rune("kitten"), []rune("sitting")
+	equal := func(p, q rune) bool {
+		return p == q
+	}
+	for i := 0; i < b.N; i++ {
+		LevenshteinDistanceFunc(x, y, equal)
+	}
+}
+
+func Benchmark_LevenshteinDistanceWithin(b *testing.B) {
+	var w Workspace[rune]
+	x, y := []rune("The quick brown fox jumps over the lazy dog"), []rune("The quick brown dog jumps over the lazy fox")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.LevenshteinDistanceWithin(x, y, 3)
+	}
+}
+
+func EqualWithin(t *testing.T, a, b, delta float64, msgAndArgs ...interface{}) bool {
+	if math.Abs(a-b) > delta {
+		return assert.Fail(t, "Values not within delta", msgAndArgs...)
+	}
+	return true
+}
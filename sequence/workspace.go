@@ -0,0 +1,330 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package sequence
+
+// Workspace holds the buffers used by the metrics of this
+// package, so that they may be reused from one comparison to
+// the next. Once its buffers have grown to fit the longest
+// sequences compared, its methods do not allocate.
+//
+// The zero value is an empty Workspace ready for use. A
+// Workspace must not be used by more than one goroutine at a
+// time; keep one per goroutine instead.
+//
+// The package-level functions each use a new Workspace, and so
+// allocate their buffers on every call.
+type Workspace[T comparable] struct {
+	ints  []int
+	flags []bool
+}
+
+// intBuffer returns a slice of n ints whose contents are
+// undefined.
+func (w *Workspace[T]) intBuffer(n int) []int {
+	if cap(w.ints) < n {
+		w.ints = make([]int, n, n)
+	}
+	return w.ints[:n]
+}
+
+// flagBuffer returns a slice of n flags which are all false.
+func (w *Workspace[T]) flagBuffer(n int) []bool {
+	if cap(w.flags) < n {
+		w.flags = make([]bool, n, n)
+		return w.flags
+	}
+	flags := w.flags[:n]
+	for i := range flags {
+		flags[i] = false
+	}
+	return flags
+}
+
+// LevenshteinDistance calculates the Levenshtein distance
+// between two sequences. See LevenshteinDistance.
+func (w *Workspace[T]) LevenshteinDistance(a, b []T) (int, error) {
+	aLen := len(a)
+	bLen := len(b)
+	if aLen == 0 {
+		return bLen, nil
+	}
+	if bLen == 0 {
+		return aLen, nil
+	}
+
+	rowLen := bLen + 1
+	rows := w.intBuffer(2 * rowLen)
+	prevRow := rows[:rowLen]
+	currRow := rows[rowLen:]
+	for h := 0; h < rowLen; h++ {
+		prevRow[h] = h
+	}
+	cost := 0
+	for i := 0; i < aLen; i++ {
+		currRow[0] = i + 1
+		for j := 0; j < bLen; j++ {
+			if a[i] == b[j] {
+				cost = 0
+			} else {
+				cost = 1
+			}
+			currRow[j+1] = min(
+				currRow[j]+1,
+				prevRow[j+1]+1,
+				prevRow[j]+cost)
+		}
+		prevRow, currRow = currRow, prevRow
+	}
+	return prevRow[bLen], nil
+}
+
+// DamerauLevenshteinDistance calculates the Damerau-Levenshtein
+// distance between two sequences. See DamerauLevenshteinDistance.
+func (w *Workspace[T]) DamerauLevenshteinDistance(a, b []T) (int, error) {
+	aLen := len(a)
+	bLen := len(b)
+	if aLen == 0 {
+		return bLen, nil
+	} else if bLen == 0 {
+		return aLen, nil
+	}
+
+	// Swap to ensure a contains the shorter slice
+	if aLen > bLen {
+		a, aLen, b, bLen = b, bLen, a, aLen
+	}
+	rowLen := aLen + 1
+	rows := w.intBuffer(3 * rowLen)
+	tranRow := rows[:rowLen]
+	prevRow := rows[rowLen : 2*rowLen]
+	currRow := rows[2*rowLen:]
+	for h := 0; h < rowLen; h++ {
+		prevRow[h] = h
+	}
+	var cost int
+	for i := 1; i <= bLen; i++ {
+		currB := b[i-1]
+		currRow[0] = i
+		for j := 1; j <= aLen; j++ {
+			currA := a[j-1]
+			if currA == currB {
+				cost = 0
+			} else {
+				cost = 1
+			}
+			entry := min(
+				currRow[j-1]+1,
+				prevRow[j]+1,
+				prevRow[j-1]+cost)
+			if cost == 1 && i > 1 && j > 1 && currA == b[i-2] && a[j-2] == currB {
+				trans := tranRow[j-2] + 1
+				if trans < entry {
+					entry = trans
+				}
+			}
+			currRow[j] = entry
+		}
+		tranRow, prevRow, currRow = prevRow, currRow, tranRow
+	}
+	return prevRow[aLen], nil
+}
+
+// LevenshteinDistanceWithin calculates the Levenshtein distance
+// between two sequences, if it is no greater than maxDistance.
+// See LevenshteinDistanceWithin.
+func (w *Workspace[T]) LevenshteinDistanceWithin(a, b []T, maxDistance int) (int, bool) {
+	return w.editDistanceWithin(a, b, maxDistance, false)
+}
+
+// DamerauLevenshteinDistanceWithin calculates the
+// Damerau-Levenshtein distance between two sequences, if it is
+// no greater than maxDistance. See
+// DamerauLevenshteinDistanceWithin.
+func (w *Workspace[T]) DamerauLevenshteinDistanceWithin(a, b []T, maxDistance int) (int, bool) {
+	return w.editDistanceWithin(a, b, maxDistance, true)
+}
+
+// editDistanceWithin calculates the Levenshtein distance, or with
+// transpositions the Damerau-Levenshtein distance, between a and
+// b if it is no greater than maxDistance, and otherwise returns
+// maxDistance+1.
+//
+// Elements common to the start or end of both sequences are
+// skipped first, as they do not change the distance. Then only
+// the cells of the diagonal band of width 2*maxDistance+1 are
+// calculated, as an alignment which strays further from the
+// diagonal costs more than maxDistance. Cells beside the band
+// are set to maxDistance+1, so that they stand in for all larger
+// values, and the calculation stops as soon as every cell of a
+// row exceeds maxDistance, as no later row can then return
+// within it.
+func (w *Workspace[T]) editDistanceWithin(a, b []T, maxDistance int, transpositions bool) (int, bool) {
+	if maxDistance < 0 {
+		return 0, false
+	}
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+	aLen := len(a)
+	bLen := len(b)
+	if aLen-bLen > maxDistance || bLen-aLen > maxDistance {
+		return maxDistance + 1, false
+	}
+	if aLen == 0 || bLen == 0 {
+		return aLen + bLen, true
+	}
+
+	over := maxDistance + 1
+	rowLen := bLen + 1
+	rows := w.intBuffer(3 * rowLen)
+	tranRow := rows[:rowLen]
+	prevRow := rows[rowLen : 2*rowLen]
+	currRow := rows[2*rowLen:]
+	for j := range prevRow {
+		prevRow[j] = min(j, over)
+	}
+	for i := 1; i <= aLen; i++ {
+		from := max(1, i-maxDistance)
+		to := min(bLen, i+maxDistance)
+		currRow[from-1] = over
+		if from == 1 {
+			currRow[0] = min(i, over)
+		}
+		if to < bLen {
+			currRow[to+1] = over
+		}
+		rowMin := currRow[from-1]
+		for j := from; j <= to; j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			entry := min(
+				currRow[j-1]+1,
+				prevRow[j]+1,
+				prevRow[j-1]+cost,
+				over)
+			if transpositions && cost == 1 && i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				entry = min(entry, tranRow[j-2]+1)
+			}
+			currRow[j] = entry
+			rowMin = min(rowMin, entry)
+		}
+		if rowMin > maxDistance {
+			return over, false
+		}
+		tranRow, prevRow, currRow = prevRow, currRow, tranRow
+	}
+	if d := prevRow[bLen]; d <= maxDistance {
+		return d, true
+	}
+	return over, false
+}
+
+// LongestCommonSubsequenceLength returns the length of the
+// longest common subsequence of two sequences. See
+// LongestCommonSubsequenceLength.
+func (w *Workspace[T]) LongestCommonSubsequenceLength(a, b []T) int {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	rowLen := len(b) + 1
+	rows := w.intBuffer(2 * rowLen)
+	prevRow := rows[:rowLen]
+	currRow := rows[rowLen:]
+	for j := range prevRow {
+		prevRow[j] = 0
+	}
+	currRow[0] = 0
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				currRow[j+1] = prevRow[j] + 1
+			} else if prevRow[j+1] >= currRow[j] {
+				currRow[j+1] = prevRow[j+1]
+			} else {
+				currRow[j+1] = currRow[j]
+			}
+		}
+		prevRow, currRow = currRow, prevRow
+	}
+	return prevRow[len(b)]
+}
+
+// JaroSimilarity calculates the Jaro similarity of two
+// sequences. See JaroSimilarity.
+func (w *Workspace[T]) JaroSimilarity(a, b []T) float64 {
+	aLen := len(a)
+	bLen := len(b)
+	if aLen == 0 || bLen == 0 {
+		return 0.0
+	}
+	matchMax := (max(aLen, bLen) / 2) - 1
+	if matchMax < 0 {
+		matchMax = 0
+	}
+	flags := w.flagBuffer(aLen + bLen)
+	aMatched := flags[:aLen]
+	bMatched := flags[aLen:]
+	matches := 0
+	for i := range a {
+		from := max(i-matchMax, 0)
+		to := min(i+matchMax, bLen-1)
+		for j := from; j <= to; j++ {
+			if !bMatched[j] && a[i] == b[j] {
+				aMatched[i] = true
+				bMatched[j] = true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0.0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range a {
+		if !aMatched[i] {
+			continue
+		}
+		for !bMatched[j] {
+			j++
+		}
+		if a[i] != b[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	matchFloat := float64(matches)
+	return (1.0 / 3.0) * (matchFloat/float64(aLen) + matchFloat/float64(bLen) + (matchFloat-float64(transpositions/2))/matchFloat)
+}
+
+// JaroWinklerSimilarity calculates the Jaro-Winkler similarity
+// of two sequences. See JaroWinklerSimilarity.
+func (w *Workspace[T]) JaroWinklerSimilarity(a, b []T) float64 {
+	return w.JaroWinklerSimilarityParametric(a, b, WinklerPrefixScale, WinklerMaxPrefixLength, WinklerBoostThreshold)
+}
+
+// JaroWinklerSimilarityParametric calculates the Jaro-Winkler
+// similarity of two sequences with the given parameters. See
+// JaroWinklerSimilarityParametric.
+func (w *Workspace[T]) JaroWinklerSimilarityParametric(a, b []T, prefixScale float64, maxPrefixLength int, boostThreshold float64) float64 {
+	j := w.JaroSimilarity(a, b)
+	if j < boostThreshold {
+		return j
+	}
+	prefix := 0
+	for minLen := min(len(a), len(b), maxPrefixLength); prefix < minLen && a[prefix] == b[prefix]; prefix++ {
+	}
+	return j + float64(prefix)*prefixScale*(1.0-j)
+}
@@ -0,0 +1,191 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package simhash
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Match is a fingerprint found by a query of an Index, along with
+// its distance from the query.
+type Match struct {
+	ID       int // The id of the fingerprint, as returned by Add.
+	Distance int // The Hamming distance of the fingerprint from the query.
+}
+
+// Index finds the fingerprints within a Hamming distance of a
+// query, with the permuted tables of Manku, Jain and Das Sarma.
+//
+// The 64 bits of a fingerprint are divided into blocks. Two
+// fingerprints within distance k of each other differ in at most k
+// blocks, and so agree exactly in the remaining blocks of at least
+// one set of blocks-k. The index holds a table for each set of
+// blocks-k blocks, keyed by those blocks; a query looks up its own
+// key in each table, and checks the distance of only the
+// fingerprints it finds.
+//
+// More blocks give smaller buckets, and so faster queries, at the
+// cost of more tables: there are blocks!/(k!(blocks-k)!) tables,
+// such as 4 for 3 bits in 4 blocks, or 10 for 3 bits in 5 blocks.
+//
+// An Index is not safe for concurrent use while fingerprints are
+// being added.
+type Index struct {
+	maxDistance  int
+	fingerprints []Fingerprint
+	// masks holds the bits of the key of each table.
+	masks []Fingerprint
+	// tables maps the key of each fingerprint to its ids, for
+	// each table.
+	tables []map[Fingerprint][]int
+}
+
+// maxTables bounds the number of tables of an Index. Each
+// fingerprint added is stored in every table, so an Index with
+// more would be too slow and too large to be of use.
+const maxTables = 1024
+
+// NewIndex returns an empty Index for finding fingerprints within
+// maxDistance bits of each other, dividing them into the given
+// number of blocks.
+//
+// NewIndex panics unless 0 <= maxDistance < blocks <= 64, and
+// unless the Index needs at most 1024 tables, as for 3 bits in up
+// to 19 blocks, or 5 bits in up to 12.
+func NewIndex(maxDistance, blocks int) *Index {
+	if maxDistance < 0 || blocks <= maxDistance || blocks > 64 {
+		panic(fmt.Sprintf("simhash: an Index cannot find fingerprints within %d bits with %d blocks", maxDistance, blocks))
+	}
+	if tableCount(maxDistance, blocks) > maxTables {
+		panic(fmt.Sprintf("simhash: an Index within %d bits with %d blocks needs more than %d tables", maxDistance, blocks, maxTables))
+	}
+	blockMasks := make([]Fingerprint, blocks, blocks)
+	for b := range blockMasks {
+		for bit := b * 64 / blocks; bit < (b+1)*64/blocks; bit++ {
+			blockMasks[b] |= 1 << uint(bit)
+		}
+	}
+
+	x := &Index{maxDistance: maxDistance}
+	// Each table is keyed by a combination of blocks-maxDistance
+	// blocks, enumerated in lexicographic order.
+	var combine func(first int, mask Fingerprint, remaining int)
+	combine = func(first int, mask Fingerprint, remaining int) {
+		if remaining == 0 {
+			x.masks = append(x.masks, mask)
+			return
+		}
+		for b := first; b <= blocks-remaining; b++ {
+			combine(b+1, mask|blockMasks[b], remaining-1)
+		}
+	}
+	combine(0, 0, blocks-maxDistance)
+
+	x.tables = make([]map[Fingerprint][]int, len(x.masks), len(x.masks))
+	for t := range x.tables {
+		x.tables[t] = make(map[Fingerprint][]int)
+	}
+	return x
+}
+
+// tableCount returns the number of tables of an Index,
+// blocks!/(k!(blocks-k)!), or maxTables+1 if it is greater than
+// maxTables.
+func tableCount(k, blocks int) int {
+	if k > blocks-k {
+		k = blocks - k
+	}
+	n := 1
+	for i := 1; i <= k; i++ {
+		// n is C(blocks-k+i-1, i-1), so this is exact.
+		n = n * (blocks - k + i) / i
+		if n > maxTables {
+			return maxTables + 1
+		}
+	}
+	return n
+}
+
+// Len returns the number of fingerprints in the index.
+func (x *Index) Len() int {
+	return len(x.fingerprints)
+}
+
+// Add adds a fingerprint to the index, and returns its id: the
+// number of fingerprints added before it.
+func (x *Index) Add(fp Fingerprint) int {
+	id := len(x.fingerprints)
+	x.fingerprints = append(x.fingerprints, fp)
+	for t, mask := range x.masks {
+		x.tables[t][fp&mask] = append(x.tables[t][fp&mask], id)
+	}
+	return id
+}
+
+// Fingerprint returns the fingerprint with the given id.
+func (x *Index) Fingerprint(id int) Fingerprint {
+	return x.fingerprints[id]
+}
+
+// Query returns the fingerprints in the index within the index's
+// maximum distance of fp, nearest first, and by id among those at
+// the same distance.
+func (x *Index) Query(fp Fingerprint) []Match {
+	seen := make(map[int]struct{})
+	var matches []Match
+	for t, mask := range x.masks {
+		for _, id := range x.tables[t][fp&mask] {
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			if d := HammingDistance(fp, x.fingerprints[id]); d <= x.maxDistance {
+				matches = append(matches, Match{ID: id, Distance: d})
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Distance != matches[j].Distance {
+			return matches[i].Distance < matches[j].Distance
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches
+}
+
+// Pairs returns each pair of ids of fingerprints in the index
+// within the index's maximum distance of each other, once, with
+// the lesser id first, ordered by the first id and then the
+// second.
+func (x *Index) Pairs() [][2]int {
+	seen := make(map[[2]int]struct{})
+	var pairs [][2]int
+	for _, table := range x.tables {
+		for _, ids := range table {
+			for i, a := range ids {
+				for _, b := range ids[i+1:] {
+					pair := [2]int{a, b}
+					if _, ok := seen[pair]; ok {
+						continue
+					}
+					seen[pair] = struct{}{}
+					if HammingDistance(x.fingerprints[a], x.fingerprints[b]) <= x.maxDistance {
+						pairs = append(pairs, pair)
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	return pairs
+}
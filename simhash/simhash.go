@@ -0,0 +1,95 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+/*
+Package stralgo/simhash implements SimHash fingerprints, 64-bit
+hashes of texts which differ in few bits between similar texts,
+and an index which finds the fingerprints within a Hamming
+distance of a query without comparing it with every one.
+
+Each token of a text, such as a word or character shingle, votes
+on each bit of the fingerprint with its weight, according to the
+corresponding bit of its own hash. Texts which share most of
+their weight in tokens thus have fingerprints which agree in most
+bits:
+
+	h := simhash.New(tokenize.Chain(tokenize.Words, tokenize.NGrams(3)), nil)
+	index := simhash.NewIndex(3, 4)
+	for _, text := range texts {
+		index.Add(h.Fingerprint(text))
+	}
+	matches := index.Query(h.Fingerprint(query))
+
+See: http://en.wikipedia.org/wiki/SimHash
+
+See: http://www.wwwconference.org/www2007/papers/paper215.pdf
+*/
+package simhash
+
+import (
+	"github.com/ZackPierce/stralgo/internal/hashing"
+	"github.com/ZackPierce/stralgo/tokenize"
+	"math/bits"
+)
+
+// Fingerprint is the SimHash fingerprint of a text.
+type Fingerprint uint64
+
+// HammingDistance returns the number of bits in which two
+// fingerprints differ. Unlike bytewise.HammingDistance, which
+// compares strings byte by byte, it compares bit by bit, and is
+// defined for every pair of fingerprints.
+//
+// See: http://en.wikipedia.org/wiki/Hamming_distance
+func HammingDistance(a, b Fingerprint) int {
+	return bits.OnesCount64(uint64(a ^ b))
+}
+
+// Hasher computes the fingerprints of texts. It is safe for
+// concurrent use, provided its tokenizer and weight function are.
+type Hasher struct {
+	tokenizer tokenize.Tokenizer
+	weight    func(token string) float64
+}
+
+// New returns a Hasher which splits texts into tokens with the
+// given tokenizer, and weights each occurrence of a token by
+// weight, such as its inverse document frequency, so that common
+// tokens count for less. A nil weight weights each occurrence 1.
+func New(tokenizer tokenize.Tokenizer, weight func(token string) float64) *Hasher {
+	return &Hasher{tokenizer: tokenizer, weight: weight}
+}
+
+// Fingerprint returns the fingerprint of the tokens of s.
+func (h *Hasher) Fingerprint(s string) Fingerprint {
+	return h.TokenFingerprint(h.tokenizer.Tokenize(s))
+}
+
+// TokenFingerprint returns the fingerprint of a list of tokens,
+// in which each occurrence of a token counts.
+func (h *Hasher) TokenFingerprint(tokens []string) Fingerprint {
+	var votes [64]float64
+	for _, token := range tokens {
+		w := 1.0
+		if h.weight != nil {
+			w = h.weight(token)
+		}
+		x := hashing.Mix(hashing.FNV1a(token))
+		for bit := range votes {
+			if x&(1<<uint(bit)) != 0 {
+				votes[bit] += w
+			} else {
+				votes[bit] -= w
+			}
+		}
+	}
+	var fp Fingerprint
+	for bit, v := range votes {
+		if v > 0 {
+			fp |= 1 << uint(bit)
+		}
+	}
+	return fp
+}
@@ -0,0 +1,110 @@
+﻿package simhash
+
+import (
+	"github.com/ZackPierce/stralgo/tokenize"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+const article = `The quick brown fox jumps over the lazy dog, and the dog,
+being lazy, does nothing about it. Later that day the fox returns
+to the same field, where the dog is still asleep in the sun.`
+
+func Test_HammingDistance(t *testing.T) {
+	assert.Equal(t, 0, HammingDistance(0, 0))
+	assert.Equal(t, 64, HammingDistance(0, ^Fingerprint(0)))
+	assert.Equal(t, 2, HammingDistance(0x5, 0x6))
+}
+
+func Test_Fingerprint(t *testing.T) {
+	h := New(tokenize.Chain(tokenize.Words, tokenize.NGrams(3)), nil)
+	fp := h.Fingerprint(article)
+	assert.Equal(t, fp, New(tokenize.Chain(tokenize.Words, tokenize.NGrams(3)), nil).Fingerprint(article))
+
+	edited := h.Fingerprint(article[:len(article)-4] + "shade.")
+	assert.True(t, HammingDistance(fp, edited) <= 6, "%d", HammingDistance(fp, edited))
+
+	other := h.Fingerprint("Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore.")
+	assert.True(t, HammingDistance(fp, other) > 16, "%d", HammingDistance(fp, other))
+
+	assert.Equal(t, Fingerprint(0), h.Fingerprint(""))
+	assert.Equal(t, h.TokenFingerprint([]string{"abc"}), h.TokenFingerprint([]string{"abc", "abc"}))
+
+	// A token with all the weight decides every bit.
+	heavy := New(tokenize.Words, func(token string) float64 {
+		if token == "fox" {
+			return 1000
+		}
+		return 1
+	})
+	assert.Equal(t, heavy.Fingerprint("fox"), heavy.Fingerprint(article))
+}
+
+func Test_Index(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var fps []Fingerprint
+	for i := 0; i < 500; i++ {
+		fp := Fingerprint(r.Uint64())
+		fps = append(fps, fp)
+		// A neighbor of up to 4 bits away.
+		for flips := r.Intn(5); flips > 0; flips-- {
+			fp ^= 1 << uint(r.Intn(64))
+		}
+		fps = append(fps, fp)
+	}
+
+	for _, config := range [][2]int{{0, 1}, {3, 4}, {3, 5}, {2, 8}} {
+		maxDistance, blocks := config[0], config[1]
+		index := NewIndex(maxDistance, blocks)
+		for i, fp := range fps {
+			assert.Equal(t, i, index.Add(fp))
+		}
+		assert.Equal(t, len(fps), index.Len())
+		assert.Equal(t, fps[7], index.Fingerprint(7))
+
+		var expectedPairs [][2]int
+		for i, a := range fps {
+			var expected []Match
+			for j, b := range fps {
+				if d := HammingDistance(a, b); d <= maxDistance {
+					expected = append(expected, Match{ID: j, Distance: d})
+					if i < j {
+						expectedPairs = append(expectedPairs, [2]int{i, j})
+					}
+				}
+			}
+			matches := index.Query(a)
+			assert.ElementsMatch(t, expected, matches, "%d bits in %d blocks", maxDistance, blocks)
+		}
+		assert.Equal(t, expectedPairs, index.Pairs(), "%d bits in %d blocks", maxDistance, blocks)
+	}
+
+	index := NewIndex(2, 3)
+	index.Add(0x0)
+	index.Add(0x3)
+	index.Add(0x1)
+	index.Add(0x7)
+	assert.Equal(t, []Match{{0, 0}, {2, 1}, {1, 2}}, index.Query(0x0))
+
+	assert.Panics(t, func() { NewIndex(3, 3) })
+	assert.Panics(t, func() { NewIndex(-1, 3) })
+	assert.Panics(t, func() { NewIndex(3, 65) })
+	assert.PanicsWithValue(t, "simhash: an Index within 20 bits with 40 blocks needs more than 1024 tables", func() { NewIndex(20, 40) })
+	assert.Panics(t, func() { NewIndex(3, 20) })
+	assert.NotPanics(t, func() { NewIndex(3, 19) })
+	assert.NotPanics(t, func() { NewIndex(5, 12) })
+	assert.NotPanics(t, func() { NewIndex(63, 64) })
+}
+
+func Benchmark_Index_Query(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	index := NewIndex(3, 6)
+	for i := 0; i < 100000; i++ {
+		index.Add(Fingerprint(r.Uint64()))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		index.Query(Fingerprint(r.Uint64()))
+	}
+}
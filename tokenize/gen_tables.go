@@ -0,0 +1,151 @@
+﻿//go:build ignore
+// +build ignore
+
+/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+// This program generates tables.go, the word break property
+// and Extended_Pictographic tables, from the Unicode Character
+// Database.
+//
+// Usage:
+//
+//	go run gen_tables.go [-ucd https://www.unicode.org/Public/15.0.0/ucd]
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+var ucd = flag.String("ucd", "https://www.unicode.org/Public/15.0.0/ucd", "base URL of the Unicode Character Database")
+
+var linePattern = regexp.MustCompile(`^([0-9A-F]{4,6})(?:\.\.([0-9A-F]{4,6}))?\s*;\s*([A-Za-z_]+)\s*#`)
+
+var propertyNames = map[string]string{
+	"CR":                 "wbCR",
+	"LF":                 "wbLF",
+	"Newline":            "wbNewline",
+	"Extend":             "wbExtend",
+	"ZWJ":                "wbZWJ",
+	"Regional_Indicator": "wbRegionalIndicator",
+	"Format":             "wbFormat",
+	"Katakana":           "wbKatakana",
+	"Hebrew_Letter":      "wbHebrewLetter",
+	"ALetter":            "wbALetter",
+	"Single_Quote":       "wbSingleQuote",
+	"Double_Quote":       "wbDoubleQuote",
+	"MidNumLet":          "wbMidNumLet",
+	"MidLetter":          "wbMidLetter",
+	"MidNum":             "wbMidNum",
+	"Numeric":            "wbNumeric",
+	"ExtendNumLet":       "wbExtendNumLet",
+	"WSegSpace":          "wbWSegSpace",
+}
+
+type propertyRange struct {
+	lo, hi   uint64
+	property string
+}
+
+func main() {
+	flag.Parse()
+	properties := merge(fetch(*ucd+"/auxiliary/WordBreakProperty.txt", ""))
+	pictographic := merge(fetch(*ucd+"/emoji/emoji-data.txt", "Extended_Pictographic"))
+
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "// Code generated by gen_tables.go. DO NOT EDIT.")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "package tokenize")
+	fmt.Fprintln(&buf)
+	fmt.Fprintf(&buf, "// wordBreakProperties is derived from %s/auxiliary/WordBreakProperty.txt.\n", *ucd)
+	fmt.Fprintln(&buf, "// See http://www.unicode.org/copyright.html for the Unicode license agreement.")
+	fmt.Fprintln(&buf, "var wordBreakProperties = []propertyRange{")
+	for _, r := range properties {
+		fmt.Fprintf(&buf, "\t{0x%04X, 0x%04X, %s},\n", r.lo, r.hi, propertyNames[r.property])
+	}
+	fmt.Fprintln(&buf, "}")
+	fmt.Fprintln(&buf)
+	fmt.Fprintf(&buf, "// extendedPictographic is derived from the Extended_Pictographic entries of\n")
+	fmt.Fprintf(&buf, "// %s/emoji/emoji-data.txt.\n", *ucd)
+	fmt.Fprintln(&buf, "var extendedPictographic = []propertyRange{")
+	for _, r := range pictographic {
+		fmt.Fprintf(&buf, "\t{0x%04X, 0x%04X, wbOther},\n", r.lo, r.hi)
+	}
+	fmt.Fprintln(&buf, "}")
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile("tables.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// merge sorts ranges and combines the adjacent ranges of equal
+// property.
+func merge(ranges []propertyRange) []propertyRange {
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].lo < ranges[j].lo
+	})
+	merged := make([]propertyRange, 0, len(ranges))
+	for _, r := range ranges {
+		last := len(merged) - 1
+		if last >= 0 && merged[last].property == r.property && merged[last].hi+1 == r.lo {
+			merged[last].hi = r.hi
+			continue
+		}
+		if last >= 0 && merged[last].hi >= r.lo {
+			log.Fatalf("overlapping ranges %X..%X and %X..%X", merged[last].lo, merged[last].hi, r.lo, r.hi)
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// fetch parses the code point ranges of a UCD property file.
+// If only is not empty, properties other than only are skipped.
+func fetch(url, only string) []propertyRange {
+	log.Printf("Fetching %s", url)
+	res, err := http.Get(url)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		log.Fatalf("%s: %s", url, res.Status)
+	}
+	ranges := make([]propertyRange, 0)
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		m := linePattern.FindStringSubmatch(scanner.Text())
+		if m == nil || (only != "" && m[3] != only) {
+			continue
+		}
+		if _, ok := propertyNames[m[3]]; !ok && only == "" {
+			log.Fatalf("unknown property %q", m[3])
+		}
+		lo, _ := strconv.ParseUint(m[1], 16, 32)
+		hi := lo
+		if m[2] != "" {
+			hi, _ = strconv.ParseUint(m[2], 16, 32)
+		}
+		ranges = append(ranges, propertyRange{lo: lo, hi: hi, property: m[3]})
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
+	return ranges
+}
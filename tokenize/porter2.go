@@ -0,0 +1,329 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package tokenize
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// EnglishStemmer is a Tokenizer stage which replaces its input
+// with its English stem, as found by EnglishStem. It is meant to
+// follow a word tokenizer in a Chain, and returns one token for
+// each token it is given:
+//
+//	stems := tokenize.Chain(tokenize.Words, tokenize.EnglishStemmer)
+//	stems.Tokenize("Running shoes") // "run", "shoe"
+var EnglishStemmer Tokenizer = TokenizerFunc(func(s string) []string {
+	return []string{EnglishStem(s)}
+})
+
+// EnglishStem returns the stem of an English word per the Porter2
+// (Snowball English) stemming algorithm, so that "running", "runs"
+// and "run" all become "run". The word is lower-cased first, and
+// the stem is always lower-case.
+//
+// Stems are not necessarily words themselves; "generously" and
+// "generous" both become "generous", but "happiness" and "happy"
+// become "happi".
+//
+// See: http://snowballstem.org/algorithms/english/stemmer.html
+func EnglishStem(word string) string {
+	word = strings.ToLower(word)
+	if stem, ok := porter2Exceptions[word]; ok {
+		return stem
+	}
+	if utf8.RuneCountInString(word) < 3 {
+		return word
+	}
+	w := &porter2Word{b: []byte(strings.TrimPrefix(word, "'"))}
+	w.prelude()
+	w.markRegions()
+	w.step0()
+	w.step1a()
+	if !porter2Invariants[string(w.b)] {
+		w.step1b()
+		w.step1c()
+		w.step2()
+		w.step3()
+		w.step4()
+		w.step5()
+	}
+	w.postlude()
+	return string(w.b)
+}
+
+// porter2Exceptions holds the words which are stemmed irregularly,
+// or left unchanged, before the algorithm proper is applied.
+var porter2Exceptions = map[string]string{
+	"skis": "ski", "skies": "sky", "dying": "die", "lying": "lie",
+	"tying": "tie", "idly": "idl", "gently": "gentl", "ugly": "ugli",
+	"early": "earli", "only": "onli", "singly": "singl",
+	"sky": "sky", "news": "news", "howe": "howe", "atlas": "atlas",
+	"cosmos": "cosmos", "bias": "bias", "andes": "andes",
+}
+
+// porter2Invariants holds the words which are left unchanged
+// once step 1a has been applied.
+var porter2Invariants = map[string]bool{
+	"inning": true, "outing": true, "canning": true, "herring": true,
+	"earring": true, "proceed": true, "exceed": true, "succeed": true,
+}
+
+// porter2Word is a word being stemmed, with its regions R1 and
+// R2 given by their starting offsets.
+type porter2Word struct {
+	b      []byte
+	r1, r2 int
+}
+
+func isPorter2Vowel(c byte) bool {
+	switch c {
+	case 'a', 'e', 'i', 'o', 'u', 'y':
+		return true
+	}
+	return false
+}
+
+func (w *porter2Word) prelude() {
+	for i, c := range w.b {
+		if c == 'y' && (i == 0 || isPorter2Vowel(w.b[i-1])) {
+			w.b[i] = 'Y'
+		}
+	}
+}
+
+func (w *porter2Word) postlude() {
+	for i, c := range w.b {
+		if c == 'Y' {
+			w.b[i] = 'y'
+		}
+	}
+}
+
+// markRegions finds R1, the region after the first non-vowel
+// following a vowel, and R2, the region after the first non-vowel
+// following a vowel in R1.
+func (w *porter2Word) markRegions() {
+	w.r1 = len(w.b)
+	for _, prefix := range []string{"gener", "commun", "arsen"} {
+		if strings.HasPrefix(string(w.b), prefix) {
+			w.r1 = len(prefix)
+		}
+	}
+	if w.r1 == len(w.b) {
+		w.r1 = w.regionAfter(0)
+	}
+	w.r2 = w.regionAfter(w.r1)
+}
+
+func (w *porter2Word) regionAfter(start int) int {
+	for i := start + 1; i < len(w.b); i++ {
+		if !isPorter2Vowel(w.b[i]) && isPorter2Vowel(w.b[i-1]) {
+			return i + 1
+		}
+	}
+	return len(w.b)
+}
+
+func (w *porter2Word) hasSuffix(suffix string) bool {
+	return strings.HasSuffix(string(w.b), suffix)
+}
+
+// longestSuffix returns the longest of suffixes which ends the word.
+func (w *porter2Word) longestSuffix(suffixes ...string) string {
+	longest := ""
+	for _, s := range suffixes {
+		if len(s) > len(longest) && w.hasSuffix(s) {
+			longest = s
+		}
+	}
+	return longest
+}
+
+func (w *porter2Word) inR1(suffix string) bool {
+	return len(w.b)-len(suffix) >= w.r1
+}
+
+func (w *porter2Word) inR2(suffix string) bool {
+	return len(w.b)-len(suffix) >= w.r2
+}
+
+func (w *porter2Word) replace(suffix, replacement string) {
+	w.b = append(w.b[:len(w.b)-len(suffix)], replacement...)
+}
+
+func (w *porter2Word) containsVowel(end int) bool {
+	if end <= 0 {
+		return false
+	}
+	for _, c := range w.b[:end] {
+		if isPorter2Vowel(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// endsShortSyllable reports whether the first end bytes of the word
+// end with a short syllable: a non-vowel, then a vowel, then a
+// non-vowel other than w, x or Y, or else a vowel at the start of
+// the word followed by a non-vowel.
+func (w *porter2Word) endsShortSyllable(end int) bool {
+	b := w.b[:end]
+	n := len(b)
+	if n == 2 {
+		return isPorter2Vowel(b[0]) && !isPorter2Vowel(b[1])
+	}
+	if n >= 3 {
+		last := b[n-1]
+		return !isPorter2Vowel(b[n-3]) && isPorter2Vowel(b[n-2]) && !isPorter2Vowel(last) &&
+			last != 'w' && last != 'x' && last != 'Y'
+	}
+	return false
+}
+
+func (w *porter2Word) isShort() bool {
+	return w.r1 >= len(w.b) && w.endsShortSyllable(len(w.b))
+}
+
+func (w *porter2Word) step0() {
+	if s := w.longestSuffix("'", "'s", "'s'"); s != "" {
+		w.replace(s, "")
+	}
+}
+
+func (w *porter2Word) step1a() {
+	switch s := w.longestSuffix("sses", "ied", "ies", "s", "us", "ss"); s {
+	case "sses":
+		w.replace(s, "ss")
+	case "ied", "ies":
+		if len(w.b) > 4 {
+			w.replace(s, "i")
+		} else {
+			w.replace(s, "ie")
+		}
+	case "s":
+		if w.containsVowel(len(w.b) - 2) {
+			w.replace(s, "")
+		}
+	}
+}
+
+func (w *porter2Word) step1b() {
+	switch s := w.longestSuffix("eed", "eedly", "ed", "edly", "ing", "ingly"); s {
+	case "eed", "eedly":
+		if w.inR1(s) {
+			w.replace(s, "ee")
+		}
+	case "ed", "edly", "ing", "ingly":
+		if !w.containsVowel(len(w.b) - len(s)) {
+			return
+		}
+		w.replace(s, "")
+		switch {
+		case w.hasSuffix("at"), w.hasSuffix("bl"), w.hasSuffix("iz"):
+			w.b = append(w.b, 'e')
+		case w.longestSuffix("bb", "dd", "ff", "gg", "mm", "nn", "pp", "rr", "tt") != "":
+			w.b = w.b[:len(w.b)-1]
+		case w.isShort():
+			w.b = append(w.b, 'e')
+		}
+	}
+}
+
+func (w *porter2Word) step1c() {
+	n := len(w.b)
+	if n > 2 && (w.b[n-1] == 'y' || w.b[n-1] == 'Y') && !isPorter2Vowel(w.b[n-2]) {
+		w.b[n-1] = 'i'
+	}
+}
+
+var step2Replacements = map[string]string{
+	"tional": "tion", "enci": "ence", "anci": "ance", "abli": "able",
+	"entli": "ent", "izer": "ize", "ization": "ize", "ational": "ate",
+	"ation": "ate", "ator": "ate", "alism": "al", "aliti": "al",
+	"alli": "al", "fulness": "ful", "ousli": "ous", "ousness": "ous",
+	"iveness": "ive", "iviti": "ive", "biliti": "ble", "bli": "ble",
+	"ogi": "og", "fulli": "ful", "lessli": "less", "li": "",
+}
+
+var step2Suffixes = keys(step2Replacements)
+
+func (w *porter2Word) step2() {
+	s := w.longestSuffix(step2Suffixes...)
+	if s == "" || !w.inR1(s) {
+		return
+	}
+	before := len(w.b) - len(s) - 1
+	switch s {
+	case "ogi":
+		if before < 0 || w.b[before] != 'l' {
+			return
+		}
+	case "li":
+		if before < 0 || !strings.ContainsRune("cdeghkmnrt", rune(w.b[before])) {
+			return
+		}
+	}
+	w.replace(s, step2Replacements[s])
+}
+
+var step3Replacements = map[string]string{
+	"tional": "tion", "ational": "ate", "alize": "al", "icate": "ic",
+	"iciti": "ic", "ical": "ic", "ful": "", "ness": "", "ative": "",
+}
+
+var step3Suffixes = keys(step3Replacements)
+
+func (w *porter2Word) step3() {
+	s := w.longestSuffix(step3Suffixes...)
+	if s == "" || !w.inR1(s) || (s == "ative" && !w.inR2(s)) {
+		return
+	}
+	w.replace(s, step3Replacements[s])
+}
+
+var step4Suffixes = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement",
+	"ment", "ent", "ism", "ate", "iti", "ous", "ive", "ize", "ion",
+}
+
+func (w *porter2Word) step4() {
+	s := w.longestSuffix(step4Suffixes...)
+	if s == "" || !w.inR2(s) {
+		return
+	}
+	if s == "ion" {
+		before := len(w.b) - len(s) - 1
+		if before < 0 || (w.b[before] != 's' && w.b[before] != 't') {
+			return
+		}
+	}
+	w.replace(s, "")
+}
+
+func (w *porter2Word) step5() {
+	switch {
+	case w.hasSuffix("e"):
+		if w.inR2("e") || (w.inR1("e") && !w.endsShortSyllable(len(w.b)-1)) {
+			w.replace("e", "")
+		}
+	case w.hasSuffix("l"):
+		if w.inR2("l") && w.hasSuffix("ll") {
+			w.replace("l", "")
+		}
+	}
+}
+
+func keys(m map[string]string) []string {
+	k := make([]string, 0, len(m))
+	for s := range m {
+		k = append(k, s)
+	}
+	return k
+}
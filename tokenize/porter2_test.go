@@ -0,0 +1,63 @@
+﻿package tokenize
+
+import (
+	"bufio"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
+	"testing"
+)
+
+func Test_EnglishStem_SnowballVocabulary(t *testing.T) {
+	f, err := os.Open("testdata/porter2.txt")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	cases, failures := 0, 0
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if !assert.Len(t, fields, 2, line) {
+			continue
+		}
+		if stem := EnglishStem(fields[0]); stem != fields[1] {
+			failures++
+			if failures <= 20 {
+				t.Errorf("EnglishStem(%q) = %q, expected %q", fields[0], stem, fields[1])
+			}
+		}
+		cases++
+	}
+	assert.Nil(t, scanner.Err())
+	assert.Equal(t, 0, failures)
+	assert.True(t, cases > 29000)
+}
+
+func Test_EnglishStem(t *testing.T) {
+	assert.Equal(t, "run", EnglishStem("Running"))
+	assert.Equal(t, "run", EnglishStem("runs"))
+	assert.Equal(t, "generous", EnglishStem("generously"))
+	assert.Equal(t, "happi", EnglishStem("happiness"))
+	assert.Equal(t, "happi", EnglishStem("HAPPY"))
+	assert.Equal(t, "sky", EnglishStem("skies"))
+	assert.Equal(t, "by", EnglishStem("by"))
+	assert.Equal(t, "", EnglishStem(""))
+}
+
+func Test_EnglishStemmer(t *testing.T) {
+	stems := Chain(Words, EnglishStemmer)
+	assert.Equal(t, []string{"run", "shoe"}, stems.Tokenize("Running shoes!"))
+	assert.Equal(t, []string{"run", "shoe"}, stems.Tokenize("run shoe"))
+}
+
+func Benchmark_EnglishStem(b *testing.B) {
+	words := strings.Fields("generalizations conditionally running shoes happiness the agreed")
+	for i := 0; i < b.N; i++ {
+		EnglishStem(words[i%len(words)])
+	}
+}
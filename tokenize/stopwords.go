@@ -0,0 +1,65 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package tokenize
+
+import (
+	"strings"
+)
+
+// EnglishStopWords is the Snowball English stop word list, the
+// common function words which carry little meaning of their own.
+//
+// See: http://snowballstem.org/algorithms/english/stop.txt
+var EnglishStopWords = []string{
+	"i", "me", "my", "myself", "we", "our", "ours", "ourselves",
+	"you", "your", "yours", "yourself", "yourselves", "he", "him",
+	"his", "himself", "she", "her", "hers", "herself", "it", "its",
+	"itself", "they", "them", "their", "theirs", "themselves",
+	"what", "which", "who", "whom", "this", "that", "these", "those",
+	"am", "is", "are", "was", "were", "be", "been", "being", "have",
+	"has", "had", "having", "do", "does", "did", "doing", "would",
+	"should", "could", "ought", "i'm", "you're", "he's", "she's",
+	"it's", "we're", "they're", "i've", "you've", "we've", "they've",
+	"i'd", "you'd", "he'd", "she'd", "we'd", "they'd", "i'll",
+	"you'll", "he'll", "she'll", "we'll", "they'll", "isn't",
+	"aren't", "wasn't", "weren't", "hasn't", "haven't", "hadn't",
+	"doesn't", "don't", "didn't", "won't", "wouldn't", "shan't",
+	"shouldn't", "can't", "cannot", "couldn't", "mustn't", "let's",
+	"that's", "who's", "what's", "here's", "there's", "when's",
+	"where's", "why's", "how's", "a", "an", "the", "and", "but",
+	"if", "or", "because", "as", "until", "while", "of", "at", "by",
+	"for", "with", "about", "against", "between", "into", "through",
+	"during", "before", "after", "above", "below", "to", "from",
+	"up", "down", "in", "out", "on", "off", "over", "under", "again",
+	"further", "then", "once", "here", "there", "when", "where",
+	"why", "how", "all", "any", "both", "each", "few", "more",
+	"most", "other", "some", "such", "no", "nor", "not", "only",
+	"own", "same", "so", "than", "too", "very",
+}
+
+// StopWords returns a Tokenizer stage which discards each token
+// it is given that is one of words, ignoring case, and passes any
+// other token through unchanged. It is meant to follow a word
+// tokenizer in a Chain:
+//
+//	content := tokenize.Chain(tokenize.Words, tokenize.StopWords(tokenize.EnglishStopWords))
+//	content.Tokenize("The Republic of France") // "Republic", "France"
+//
+// The list of words is copied, so it may be changed afterwards
+// without affecting the Tokenizer.
+func StopWords(words []string) Tokenizer {
+	stop := make(map[string]bool, len(words))
+	for _, w := range words {
+		stop[strings.ToLower(w)] = true
+	}
+	return TokenizerFunc(func(s string) []string {
+		if stop[strings.ToLower(s)] {
+			return make([]string, 0)
+		}
+		return []string{s}
+	})
+}
@@ -0,0 +1,23 @@
+﻿package tokenize
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_StopWords(t *testing.T) {
+	content := Chain(Words, StopWords(EnglishStopWords))
+	assert.Equal(t, []string{"Republic", "France"}, content.Tokenize("The Republic of France"))
+	assert.Equal(t, []string{}, content.Tokenize("Can't you? THEY DID."))
+
+	words := []string{"inc", "ltd"}
+	custom := Chain(Punctuation, StopWords(words))
+	words[0] = "acme"
+	assert.Equal(t, []string{"Acme", "Widgets"}, custom.Tokenize("Acme Widgets, Inc."))
+}
+
+func Test_StopWords_Stemming(t *testing.T) {
+	terms := Chain(Words, StopWords(EnglishStopWords), EnglishStemmer)
+	assert.Equal(t, terms.Tokenize("The running shoes"), terms.Tokenize("run shoe"))
+	assert.Equal(t, []string{"connect", "network"}, terms.Tokenize("connections to the networks"))
+}
@@ -0,0 +1,215 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+/*
+Package stralgo/tokenize implements the tokenizers used by the
+token-based similarity metrics, such as DiceCoefficientTokenized
+and WhiteSimilarityTokenized in bytewise, runewise and
+graphemewise.
+
+Tokenizers split a text into whitespace-separated fields, Unicode
+words, punctuation-separated words, or character n-grams, and may
+be chained so that, for example, the n-grams are taken within each
+word rather than across the whole text:
+
+	letterPairs := tokenize.Chain(tokenize.Whitespace, tokenize.NGrams(2))
+	similarity, err := runewise.WhiteSimilarityTokenized(a, b, letterPairs)
+
+Stages which rewrite or discard individual tokens, such as
+EnglishStemmer and StopWords, may also be chained after a word
+tokenizer, so that "running shoes" and "run shoe" produce the
+same tokens:
+
+	terms := tokenize.Chain(tokenize.Words, tokenize.StopWords(tokenize.EnglishStopWords), tokenize.EnglishStemmer)
+*/
+package tokenize
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokenizer splits a text into tokens.
+//
+// Tokenizers must be safe for concurrent use.
+type Tokenizer interface {
+	// Tokenize returns the tokens of s in the order they occur.
+	Tokenize(s string) []string
+}
+
+// TokenizerFunc adapts an ordinary function to the Tokenizer
+// interface.
+type TokenizerFunc func(s string) []string
+
+// Tokenize calls f(s).
+func (f TokenizerFunc) Tokenize(s string) []string {
+	return f(s)
+}
+
+// Whitespace splits a text into the fields separated by runs of
+// Unicode whitespace, as reported by unicode.IsSpace.
+var Whitespace Tokenizer = TokenizerFunc(whitespace)
+
+// ASCIIWhitespace splits a text into the fields separated by runs
+// of the single-byte ASCII whitespace characters, '\t', '\n', '\v',
+// '\f', '\r' and ' '. It examines bytes rather than runes, and is
+// the whitespace tokenizer used by default in bytewise.
+var ASCIIWhitespace Tokenizer = TokenizerFunc(asciiWhitespace)
+
+// Words splits a text into its words, as delimited by the word
+// boundaries of Unicode Standard Annex #29, discarding the
+// segments between words which contain no letters or numbers.
+//
+// Unlike Whitespace, Words separates words from the punctuation
+// around them, while keeping contractions such as "can't" and
+// numbers such as "3.14" whole. See SplitWords.
+var Words Tokenizer = TokenizerFunc(words)
+
+// Punctuation splits a text into the fields separated by whitespace,
+// punctuation and symbols, except that an apostrophe or hyphen
+// between two letters or digits is kept within its field, so that
+// "O'Brien-Smith, J." yields "O'Brien-Smith" and "J".
+var Punctuation Tokenizer = TokenizerFunc(punctuation)
+
+func whitespace(s string) []string {
+	return fields(s, func(s string, i int, r rune) bool {
+		return unicode.IsSpace(r)
+	})
+}
+
+func asciiWhitespace(s string) []string {
+	tokens := make([]string, 0)
+	start := -1
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\t', '\n', '\v', '\f', '\r', ' ':
+			if start >= 0 {
+				tokens = append(tokens, s[start:i])
+				start = -1
+			}
+		default:
+			if start < 0 {
+				start = i
+			}
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
+
+func punctuation(s string) []string {
+	return fields(s, func(s string, i int, r rune) bool {
+		if unicode.IsSpace(r) {
+			return true
+		}
+		if !unicode.IsPunct(r) && !unicode.IsSymbol(r) {
+			return false
+		}
+		switch r {
+		case '\'', '’', '-', '‐', '‑':
+			before, _ := utf8.DecodeLastRuneInString(s[:i])
+			after, _ := utf8.DecodeRuneInString(s[i+utf8.RuneLen(r):])
+			return !isAlphanumeric(before) || !isAlphanumeric(after)
+		}
+		return true
+	})
+}
+
+func isAlphanumeric(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsNumber(r))
+}
+
+// fields splits s around the runs of runes for which separator
+// returns true. separator is given s and the index of each rune.
+func fields(s string, separator func(s string, i int, r rune) bool) []string {
+	tokens := make([]string, 0)
+	start := -1
+	for i, r := range s {
+		if separator(s, i, r) {
+			if start >= 0 {
+				tokens = append(tokens, s[start:i])
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
+
+// NGrams returns a Tokenizer which splits a text into its
+// overlapping n-rune substrings, so that the 2-grams of "night"
+// are "ni", "ig", "gh" and "ht". A text of fewer than n runes has
+// no n-grams.
+//
+// NGrams panics if n is less than 1.
+//
+// See: http://en.wikipedia.org/wiki/N-gram
+func NGrams(n int) Tokenizer {
+	if n < 1 {
+		panic("The n-gram length must be at least 1.")
+	}
+	return TokenizerFunc(func(s string) []string {
+		offsets := make([]int, 0, len(s)+1)
+		for i := range s {
+			offsets = append(offsets, i)
+		}
+		offsets = append(offsets, len(s))
+		count := len(offsets) - n
+		if count < 1 {
+			return make([]string, 0)
+		}
+		grams := make([]string, count, count)
+		for i := range grams {
+			grams[i] = s[offsets[i]:offsets[i+n]]
+		}
+		return grams
+	})
+}
+
+// ByteNGrams returns a Tokenizer which splits a text into its
+// overlapping n-byte substrings, without regard for multi-byte
+// runes. It is the n-gram tokenizer used by default in bytewise.
+//
+// ByteNGrams panics if n is less than 1.
+func ByteNGrams(n int) Tokenizer {
+	if n < 1 {
+		panic("The n-gram length must be at least 1.")
+	}
+	return TokenizerFunc(func(s string) []string {
+		count := len(s) - n + 1
+		if count < 1 {
+			return make([]string, 0)
+		}
+		grams := make([]string, count, count)
+		for i := range grams {
+			grams[i] = s[i : i+n]
+		}
+		return grams
+	})
+}
+
+// Chain returns a Tokenizer which splits a text with first, and
+// then splits each of the resulting tokens with each of then in
+// turn. For example, chaining Whitespace and NGrams(2) yields the
+// letter pairs within each word of a text.
+func Chain(first Tokenizer, then ...Tokenizer) Tokenizer {
+	return TokenizerFunc(func(s string) []string {
+		tokens := first.Tokenize(s)
+		for _, t := range then {
+			split := make([]string, 0, len(tokens))
+			for _, token := range tokens {
+				split = append(split, t.Tokenize(token)...)
+			}
+			tokens = split
+		}
+		return tokens
+	})
+}
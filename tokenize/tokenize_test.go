@@ -0,0 +1,47 @@
+﻿package tokenize
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_Whitespace(t *testing.T) {
+	assert.Equal(t, []string{"REPUBLIC", "OF", "FRANCE"}, Whitespace.Tokenize("  REPUBLIC\tOF　FRANCE\n"))
+	assert.Equal(t, []string{}, Whitespace.Tokenize(" \t "))
+	assert.Equal(t, []string{}, Whitespace.Tokenize(""))
+}
+
+func Test_ASCIIWhitespace(t *testing.T) {
+	assert.Equal(t, []string{"a", "b", "c"}, ASCIIWhitespace.Tokenize("a b\t\n\v\f\rc "))
+	assert.Equal(t, []string{"a　b"}, ASCIIWhitespace.Tokenize("a　b"), "Multi-byte whitespace is not split upon.")
+	assert.Equal(t, []string{"a\x85\xa0b"}, ASCIIWhitespace.Tokenize("a\x85\xa0b"), "Bytes above 0x7F are not whitespace.")
+}
+
+func Test_Punctuation(t *testing.T) {
+	assert.Equal(t, []string{"O'Brien-Smith", "J"}, Punctuation.Tokenize("O'Brien-Smith, J."))
+	assert.Equal(t, []string{"rock", "n", "roll"}, Punctuation.Tokenize("rock 'n' roll"))
+	assert.Equal(t, []string{"a", "b", "c"}, Punctuation.Tokenize("a+b=c"), "Symbols separate tokens.")
+	assert.Equal(t, []string{"l’été", "2013"}, Punctuation.Tokenize("«l’été» -2013-"))
+}
+
+func Test_NGrams(t *testing.T) {
+	assert.Equal(t, []string{"ni", "ig", "gh", "ht"}, NGrams(2).Tokenize("night"))
+	assert.Equal(t, []string{"日本語"}, NGrams(3).Tokenize("日本語"))
+	assert.Equal(t, []string{}, NGrams(4).Tokenize("日本語"))
+	assert.Equal(t, []string{"a", " ", "b"}, NGrams(1).Tokenize("a b"))
+	assert.Panics(t, func() { NGrams(0) })
+}
+
+func Test_ByteNGrams(t *testing.T) {
+	assert.Equal(t, []string{"ni", "ig", "gh", "ht"}, ByteNGrams(2).Tokenize("night"))
+	assert.Equal(t, []string{"\xe6\x97", "\x97\xa5"}, ByteNGrams(2).Tokenize("日"))
+	assert.Equal(t, []string{}, ByteNGrams(2).Tokenize("a"))
+	assert.Panics(t, func() { ByteNGrams(-1) })
+}
+
+func Test_Chain(t *testing.T) {
+	pairs := Chain(Whitespace, NGrams(2))
+	assert.Equal(t, []string{"Gr", "rü", "ün", "of", "ff"}, pairs.Tokenize("Grün a off"))
+	assert.Equal(t, []string{"Grün", "off"}, Chain(Whitespace).Tokenize("Grün off"))
+	assert.Equal(t, []string{"ca", "an", "n'", "'t", "st", "to", "op"}, Chain(Words, NGrams(2)).Tokenize("can't stop!"))
+}
@@ -0,0 +1,235 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+
+package tokenize
+
+import (
+	"sort"
+	"unicode"
+)
+
+//go:generate go run gen_tables.go
+
+// wbProperty is a Word_Break property value.
+type wbProperty uint8
+
+const (
+	wbOther wbProperty = iota
+	wbCR
+	wbLF
+	wbNewline
+	wbExtend
+	wbZWJ
+	wbRegionalIndicator
+	wbFormat
+	wbKatakana
+	wbHebrewLetter
+	wbALetter
+	wbSingleQuote
+	wbDoubleQuote
+	wbMidNumLet
+	wbMidLetter
+	wbMidNum
+	wbNumeric
+	wbExtendNumLet
+	wbWSegSpace
+)
+
+type propertyRange struct {
+	lo, hi   rune
+	property wbProperty
+}
+
+func search(ranges []propertyRange, r rune) (wbProperty, bool) {
+	i := sort.Search(len(ranges), func(i int) bool {
+		return ranges[i].hi >= r
+	})
+	if i < len(ranges) && ranges[i].lo <= r {
+		return ranges[i].property, true
+	}
+	return wbOther, false
+}
+
+func property(r rune) wbProperty {
+	p, _ := search(wordBreakProperties, r)
+	return p
+}
+
+func isExtendedPictographic(r rune) bool {
+	_, ok := search(extendedPictographic, r)
+	return ok
+}
+
+// ignorable reports whether rule WB4 attaches p to the
+// character before it.
+func (p wbProperty) ignorable() bool {
+	return p == wbExtend || p == wbFormat || p == wbZWJ
+}
+
+func (p wbProperty) newline() bool {
+	return p == wbCR || p == wbLF || p == wbNewline
+}
+
+func (p wbProperty) ahLetter() bool {
+	return p == wbALetter || p == wbHebrewLetter
+}
+
+func (p wbProperty) midNumLetQ() bool {
+	return p == wbMidNumLet || p == wbSingleQuote
+}
+
+// SplitWords segments s at its word boundaries, following the
+// rules of Unicode Standard Annex #29 (Unicode 15.0).
+//
+// Every rune of s belongs to exactly one segment, so that the
+// segments include the whitespace and punctuation between words,
+// and concatenating them reproduces s exactly. Each invalid UTF-8
+// byte is treated as a U+FFFD rune.
+//
+// See: http://www.unicode.org/reports/tr29/#Word_Boundaries
+func SplitWords(s string) []string {
+	runes := make([]rune, 0, len(s))
+	offsets := make([]int, 0, len(s)+1)
+	for i, r := range s {
+		runes = append(runes, r)
+		offsets = append(offsets, i)
+	}
+	offsets = append(offsets, len(s))
+	n := len(runes)
+	props := make([]wbProperty, n, n)
+	for i, r := range runes {
+		props[i] = property(r)
+	}
+	// base[i] is the index of the character which rune i is
+	// treated as part of under rule WB4, and next[i] is the index
+	// of the first rune after i which is not attached to another
+	// by that rule, or n.
+	base := make([]int, n, n)
+	for i, p := range props {
+		base[i] = i
+		if i > 0 && p.ignorable() && !props[base[i-1]].newline() {
+			base[i] = base[i-1]
+		}
+	}
+	next := make([]int, n, n)
+	following := n
+	for i := n - 1; i >= 0; i-- {
+		next[i] = following
+		if base[i] == i {
+			following = i
+		}
+	}
+	// regional[i] counts the consecutive Regional_Indicators ending
+	// at character i, for rules WB15 and WB16.
+	regional := make([]int, n, n)
+
+	segments := make([]string, 0, n)
+	start := 0
+	for i := 0; i < n; i++ {
+		if base[i] == i && props[i] == wbRegionalIndicator {
+			regional[i] = 1
+			if i > 0 && props[base[i-1]] == wbRegionalIndicator {
+				regional[i] += regional[base[i-1]]
+			}
+		}
+		if i == 0 || !isWordBoundary(runes, props, base, next, regional, i) {
+			continue
+		}
+		segments = append(segments, s[offsets[start]:offsets[i]])
+		start = i
+	}
+	if n > 0 {
+		segments = append(segments, s[offsets[start]:])
+	}
+	return segments
+}
+
+// isWordBoundary reports whether there is a word boundary between
+// runes i-1 and i.
+func isWordBoundary(runes []rune, props []wbProperty, base, next, regional []int, i int) bool {
+	prev, cur := props[i-1], props[i]
+	switch {
+	case prev == wbCR && cur == wbLF: // WB3
+		return false
+	case prev.newline(): // WB3a
+		return true
+	case cur.newline(): // WB3b
+		return true
+	case prev == wbZWJ && isExtendedPictographic(runes[i]): // WB3c
+		return false
+	case prev == wbWSegSpace && cur == wbWSegSpace: // WB3d
+		return false
+	case cur.ignorable(): // WB4
+		return false
+	}
+
+	// The remaining rules skip the characters ignored by WB4.
+	l := base[i-1]
+	left := props[l]
+	before := wbOther
+	if l > 0 {
+		before = props[base[l-1]]
+	}
+	after := wbOther
+	if j := next[i]; j < len(props) {
+		after = props[j]
+	}
+	switch {
+	case left.ahLetter() && cur.ahLetter(): // WB5
+		return false
+	case left.ahLetter() && (cur == wbMidLetter || cur.midNumLetQ()) && after.ahLetter(): // WB6
+		return false
+	case before.ahLetter() && (left == wbMidLetter || left.midNumLetQ()) && cur.ahLetter(): // WB7
+		return false
+	case left == wbHebrewLetter && cur == wbSingleQuote: // WB7a
+		return false
+	case left == wbHebrewLetter && cur == wbDoubleQuote && after == wbHebrewLetter: // WB7b
+		return false
+	case before == wbHebrewLetter && left == wbDoubleQuote && cur == wbHebrewLetter: // WB7c
+		return false
+	case left == wbNumeric && cur == wbNumeric: // WB8
+		return false
+	case left.ahLetter() && cur == wbNumeric: // WB9
+		return false
+	case left == wbNumeric && cur.ahLetter(): // WB10
+		return false
+	case before == wbNumeric && (left == wbMidNum || left.midNumLetQ()) && cur == wbNumeric: // WB11
+		return false
+	case left == wbNumeric && (cur == wbMidNum || cur.midNumLetQ()) && after == wbNumeric: // WB12
+		return false
+	case left == wbKatakana && cur == wbKatakana: // WB13
+		return false
+	case (left.ahLetter() || left == wbNumeric || left == wbKatakana || left == wbExtendNumLet) && cur == wbExtendNumLet: // WB13a
+		return false
+	case left == wbExtendNumLet && (cur.ahLetter() || cur == wbNumeric || cur == wbKatakana): // WB13b
+		return false
+	case left == wbRegionalIndicator && cur == wbRegionalIndicator && regional[l]%2 == 1: // WB15, WB16
+		return false
+	}
+	return true // WB999
+}
+
+// isWordLike reports whether a segment produced by SplitWords
+// is a word, rather than whitespace, punctuation or symbols.
+func isWordLike(segment string) bool {
+	for _, r := range segment {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func words(s string) []string {
+	segments := SplitWords(s)
+	tokens := segments[:0]
+	for _, segment := range segments {
+		if isWordLike(segment) {
+			tokens = append(tokens, segment)
+		}
+	}
+	return tokens
+}
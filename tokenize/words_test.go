@@ -0,0 +1,75 @@
+﻿package tokenize
+
+import (
+	"bufio"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func Test_SplitWords(t *testing.T) {
+	assert.Equal(t, []string{"The", " ", "quick", " ", "(", "\"", "brown", "\"", ")", " ", "fox", " ", "can't", " ", "jump", " ", "32.3", " ", "feet", ",", " ", "right", "?"},
+		SplitWords("The quick (\"brown\") fox can't jump 32.3 feet, right?"))
+	assert.Equal(t, []string{"e.g", ".", "  ", "U.S.A"}, SplitWords("e.g.  U.S.A"))
+	assert.Equal(t, []string{"日", "本", "カタカナ"}, SplitWords("日本カタカナ"))
+	assert.Equal(t, []string{"snake_case", "\r\n", "\U0001F1EF\U0001F1F5", "\U0001F1EB"}, SplitWords("snake_case\r\n\U0001F1EF\U0001F1F5\U0001F1EB"))
+	assert.Equal(t, []string{}, SplitWords(""))
+	assert.Equal(t, []string{"a", "\xff", "b"}, SplitWords("a\xffb"))
+}
+
+func Test_Words(t *testing.T) {
+	assert.Equal(t, []string{"The", "quick", "brown", "fox", "can't", "jump", "32.3", "feet", "right"},
+		Words.Tokenize("The quick (\"brown\") fox can't jump 32.3 feet, right?"))
+	assert.Equal(t, []string{"café", "naïve"}, Words.Tokenize("— café, naïve… ❤"))
+	assert.Equal(t, []string{}, Words.Tokenize(" ... "))
+}
+
+func Test_SplitWords_UnicodeConformance(t *testing.T) {
+	f, err := os.Open("testdata/WordBreakTest.txt")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	cases := 0
+	for scanner.Scan() {
+		line := scanner.Text()
+		if hash := strings.Index(line, "#"); hash >= 0 {
+			line = line[:hash]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		expected := make([]string, 0)
+		var text, segment strings.Builder
+		for _, field := range strings.Fields(line) {
+			switch field {
+			case "÷":
+				if segment.Len() > 0 {
+					expected = append(expected, segment.String())
+					segment.Reset()
+				}
+			case "×":
+			default:
+				cp, err := strconv.ParseUint(field, 16, 32)
+				assert.Nil(t, err)
+				segment.WriteRune(rune(cp))
+				text.WriteRune(rune(cp))
+			}
+		}
+		assert.Equal(t, expected, SplitWords(text.String()), line)
+		cases++
+	}
+	assert.Nil(t, scanner.Err())
+	assert.True(t, cases > 1500)
+}
+
+func Benchmark_SplitWords(b *testing.B) {
+	s := strings.Repeat("The quick (\"brown\") fox can't jump 32.3 feet, right? ", 20)
+	for i := 0; i < b.N; i++ {
+		SplitWords(s)
+	}
+}
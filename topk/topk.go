@@ -0,0 +1,235 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+/*
+Package stralgo/topk implements searches for the k best matches
+for a query among a list of candidate strings, under any of the
+metrics of the metric package:
+
+	d, _ := metric.LookupDistance("levenshtein")
+	matches := topk.Nearest("jonh", names, 5, 2, d, nil)
+	for _, m := range matches {
+		fmt.Println(m.Candidate, m.Score)
+	}
+
+Each search compares the query with every candidate, but does
+less work for those which cannot be among the best: a metric
+which implements metric.BoundedDistance or
+metric.BoundedSimilarity, as the built-in Levenshtein, Damerau-
+Levenshtein, Jaro and Jaro-Winkler metrics do, is asked only
+whether each candidate beats the worst of the best k found so
+far, and the search ends early once k perfect matches are found.
+Large candidate lists are shared among several goroutines.
+
+To search the same candidates repeatedly, consider building an
+index over them with vptree instead.
+*/
+package topk
+
+import (
+	"container/heap"
+	"github.com/ZackPierce/stralgo/metric"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// Match is a candidate found by a search, along with its score.
+type Match struct {
+	Index     int     // The position of the candidate within the candidates searched.
+	Candidate string  // The candidate.
+	Score     float64 // The distance or similarity between the query and the candidate.
+}
+
+// Options configures a search. The zero value, like a nil
+// *Options, selects the defaults.
+type Options struct {
+	// Workers is the largest number of goroutines which share a
+	// search. It defaults to runtime.GOMAXPROCS(0). Fewer are
+	// used for short candidate lists, which are searched by a
+	// single goroutine when shorter than MinCandidatesPerWorker.
+	Workers int
+}
+
+// MinCandidatesPerWorker is the fewest candidates given to each
+// goroutine of a search, below which starting another goroutine
+// costs more than it saves.
+const MinCandidatesPerWorker = 512
+
+// Nearest returns up to k of the candidates whose distance from
+// the query is no greater than maxDistance, nearest first. Of
+// candidates at the same distance, those earlier in candidates
+// come first. Pass math.Inf(1) as maxDistance to accept
+// candidates at any distance.
+//
+// Candidates for which the distance fails, such as those of a
+// different length under a Hamming distance, are skipped.
+func Nearest(query string, candidates []string, k int, maxDistance float64, d metric.Distance, options *Options) []Match {
+	s := &search{
+		query:     query,
+		k:         k,
+		threshold: maxDistance,
+		perfect:   0,
+		better:    func(x, y float64) bool { return x < y },
+	}
+	if bounded, ok := d.(metric.BoundedDistance); ok {
+		s.score = func(a, b string, bound float64) (float64, bool) {
+			dist, ok, err := bounded.DistanceWithin(a, b, bound)
+			return dist, ok && err == nil
+		}
+	} else {
+		s.score = func(a, b string, bound float64) (float64, bool) {
+			dist, err := d.Distance(a, b)
+			return dist, err == nil && dist <= bound
+		}
+	}
+	return s.run(candidates, options)
+}
+
+// MostSimilar returns up to k of the candidates whose similarity
+// to the query is at least minSimilarity, most similar first. Of
+// equally similar candidates, those earlier in candidates come
+// first. Pass 0 as minSimilarity to accept candidates of any
+// similarity.
+//
+// Candidates for which the similarity fails, such as those with
+// no bigrams under a Dice coefficient, are skipped.
+func MostSimilar(query string, candidates []string, k int, minSimilarity float64, s metric.Similarity, options *Options) []Match {
+	srch := &search{
+		query:     query,
+		k:         k,
+		threshold: minSimilarity,
+		perfect:   1.0,
+		better:    func(x, y float64) bool { return x > y },
+	}
+	if bounded, ok := s.(metric.BoundedSimilarity); ok {
+		srch.score = func(a, b string, bound float64) (float64, bool) {
+			sim, ok, err := bounded.SimilarityAtLeast(a, b, bound)
+			return sim, ok && err == nil
+		}
+	} else {
+		srch.score = func(a, b string, bound float64) (float64, bool) {
+			sim, err := s.Similarity(a, b)
+			return sim, err == nil && sim >= bound
+		}
+	}
+	return srch.run(candidates, options)
+}
+
+// search holds the parameters of a search, for either distances
+// or similarities.
+type search struct {
+	query     string
+	k         int
+	threshold float64
+	// perfect is the best score possible, which no other can
+	// beat.
+	perfect float64
+	// better reports whether score x is strictly better than y.
+	better func(x, y float64) bool
+	// score returns the score of a candidate and true, if it is
+	// no worse than bound.
+	score func(a, b string, bound float64) (float64, bool)
+}
+
+// run searches the candidates, sharing them among goroutines in
+// contiguous ranges, and merges the best matches of each.
+func (s *search) run(candidates []string, options *Options) []Match {
+	if s.k <= 0 || len(candidates) == 0 {
+		return nil
+	}
+	workers := runtime.GOMAXPROCS(0)
+	if options != nil && options.Workers > 0 {
+		workers = options.Workers
+	}
+	workers = max(1, min(workers, len(candidates)/MinCandidatesPerWorker))
+	if workers == 1 {
+		return s.sorted(s.searchRange(candidates, 0))
+	}
+
+	results := make([][]Match, workers, workers)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		from := w * len(candidates) / workers
+		to := (w + 1) * len(candidates) / workers
+		go func(w, from, to int) {
+			defer wg.Done()
+			results[w] = s.searchRange(candidates[from:to], from)
+		}(w, from, to)
+	}
+	wg.Wait()
+
+	var all []Match
+	for _, r := range results {
+		all = append(all, r...)
+	}
+	all = s.sorted(all)
+	if len(all) > s.k {
+		all = all[:s.k]
+	}
+	return all
+}
+
+// searchRange returns the best k matches among candidates, whose
+// first element is at position offset of the full list, in no
+// particular order.
+func (s *search) searchRange(candidates []string, offset int) []Match {
+	h := &matchHeap{search: s}
+	for i, c := range candidates {
+		bound := s.threshold
+		if len(h.matches) == s.k {
+			bound = h.matches[0].Score
+			if bound == s.perfect {
+				break
+			}
+		}
+		score, ok := s.score(s.query, c, bound)
+		if !ok {
+			continue
+		}
+		m := Match{Index: offset + i, Candidate: c, Score: score}
+		if len(h.matches) < s.k {
+			heap.Push(h, m)
+		} else if s.better(score, h.matches[0].Score) {
+			h.matches[0] = m
+			heap.Fix(h, 0)
+		}
+	}
+	return h.matches
+}
+
+// sorted sorts matches best first, and by position among equals.
+func (s *search) sorted(matches []Match) []Match {
+	sort.Slice(matches, func(i, j int) bool {
+		return s.worse(matches[j], matches[i])
+	})
+	return matches
+}
+
+// worse reports whether x ranks below y.
+func (s *search) worse(x, y Match) bool {
+	if x.Score != y.Score {
+		return s.better(y.Score, x.Score)
+	}
+	return x.Index > y.Index
+}
+
+// matchHeap is a heap of the best matches found so far, with the
+// worst on top, so that it may be replaced by a better match.
+type matchHeap struct {
+	search  *search
+	matches []Match
+}
+
+func (h *matchHeap) Len() int           { return len(h.matches) }
+func (h *matchHeap) Less(i, j int) bool { return h.search.worse(h.matches[i], h.matches[j]) }
+func (h *matchHeap) Swap(i, j int)      { h.matches[i], h.matches[j] = h.matches[j], h.matches[i] }
+func (h *matchHeap) Push(x interface{}) { h.matches = append(h.matches, x.(Match)) }
+func (h *matchHeap) Pop() interface{} {
+	last := h.matches[len(h.matches)-1]
+	h.matches = h.matches[:len(h.matches)-1]
+	return last
+}
@@ -0,0 +1,127 @@
+﻿package topk
+
+import (
+	"github.com/ZackPierce/stralgo/metric"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func randomNames(count int, seed int64) []string {
+	r := rand.New(rand.NewSource(seed))
+	names := make([]string, count, count)
+	for i := range names {
+		b := make([]byte, 3+r.Intn(6), 8)
+		for j := range b {
+			b[j] = "aeinost"[r.Intn(7)]
+		}
+		names[i] = string(b)
+	}
+	return names
+}
+
+// bruteForce scores every candidate, and sorts the matches.
+func bruteForce(query string, candidates []string, k int, accept func(score float64) bool, score func(a, b string) (float64, error), better func(x, y float64) bool) []Match {
+	var matches []Match
+	for i, c := range candidates {
+		if s, err := score(query, c); err == nil && accept(s) {
+			matches = append(matches, Match{Index: i, Candidate: c, Score: s})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return better(matches[i].Score, matches[j].Score) })
+	if len(matches) > k {
+		matches = matches[:k]
+	}
+	return matches
+}
+
+func Test_Nearest(t *testing.T) {
+	candidates := randomNames(5000, 1)
+	less := func(x, y float64) bool { return x < y }
+	for _, name := range []string{"levenshtein", "damerau-levenshtein", "hamming", "grapheme-levenshtein"} {
+		d, err := metric.LookupDistance(name)
+		assert.Nil(t, err)
+		for _, query := range randomNames(10, 2) {
+			for _, k := range []int{1, 5, 50} {
+				for _, maxDistance := range []float64{0, 2, 3.5, math.Inf(1)} {
+					expected := bruteForce(query, candidates, k, func(s float64) bool { return s <= maxDistance }, d.Distance, less)
+					for _, workers := range []int{1, 7} {
+						actual := Nearest(query, candidates, k, maxDistance, d, &Options{Workers: workers})
+						assert.Equal(t, expected, actual, "%s %q k=%d max=%v workers=%d", name, query, k, maxDistance, workers)
+					}
+				}
+			}
+		}
+	}
+}
+
+func Test_MostSimilar(t *testing.T) {
+	candidates := randomNames(5000, 3)
+	greater := func(x, y float64) bool { return x > y }
+	for _, name := range []string{"jaro", "jaro-winkler", "dice", "white"} {
+		s, err := metric.LookupSimilarity(name)
+		assert.Nil(t, err)
+		for _, query := range randomNames(10, 4) {
+			for _, k := range []int{1, 5, 50} {
+				for _, minSimilarity := range []float64{0, 0.5, 0.9, 1} {
+					expected := bruteForce(query, candidates, k, func(v float64) bool { return v >= minSimilarity }, s.Similarity, greater)
+					for _, workers := range []int{1, 7} {
+						actual := MostSimilar(query, candidates, k, minSimilarity, s, &Options{Workers: workers})
+						assert.Equal(t, expected, actual, "%s %q k=%d min=%v workers=%d", name, query, k, minSimilarity, workers)
+					}
+				}
+			}
+		}
+	}
+}
+
+func Test_Nearest_Examples(t *testing.T) {
+	d, _ := metric.LookupDistance("levenshtein")
+	names := []string{"John", "Joan", "Jon", "Johann", "Jonathan", "John"}
+	assert.Equal(t, []Match{
+		{Index: 0, Candidate: "John", Score: 0},
+		{Index: 5, Candidate: "John", Score: 0},
+		{Index: 1, Candidate: "Joan", Score: 1},
+	}, Nearest("John", names, 3, 1, d, nil))
+	assert.Nil(t, Nearest("John", names, 0, 1, d, nil))
+	assert.Nil(t, Nearest("John", nil, 3, 1, d, nil))
+	assert.Empty(t, Nearest("Zzzzzz", names, 3, 1, d, nil))
+
+	unbounded := metric.DistanceFunc(d.Distance)
+	assert.Equal(t, Nearest("Jonh", names, 4, 3, d, nil), Nearest("Jonh", names, 4, 3, unbounded, nil))
+}
+
+func Benchmark_Nearest_Bounded(b *testing.B) {
+	candidates := randomNames(20000, 5)
+	d, _ := metric.LookupDistance("levenshtein")
+	for i := 0; i < b.N; i++ {
+		Nearest("antonie", candidates, 5, 2, d, &Options{Workers: 1})
+	}
+}
+
+func Benchmark_Nearest_Unbounded(b *testing.B) {
+	candidates := randomNames(20000, 5)
+	d, _ := metric.LookupDistance("levenshtein")
+	unbounded := metric.DistanceFunc(d.Distance)
+	for i := 0; i < b.N; i++ {
+		Nearest("antonie", candidates, 5, 2, unbounded, &Options{Workers: 1})
+	}
+}
+
+func Benchmark_Nearest_Parallel(b *testing.B) {
+	candidates := randomNames(20000, 5)
+	d, _ := metric.LookupDistance("levenshtein")
+	for i := 0; i < b.N; i++ {
+		Nearest("antonie", candidates, 5, 2, d, nil)
+	}
+}
+
+func Benchmark_MostSimilar_Bounded(b *testing.B) {
+	candidates := randomNames(20000, 5)
+	s, _ := metric.LookupSimilarity("jaro-winkler")
+	for i := 0; i < b.N; i++ {
+		MostSimilar("antonie", candidates, 5, 0.9, s, &Options{Workers: 1})
+	}
+}
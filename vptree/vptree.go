@@ -0,0 +1,231 @@
+﻿/*
+Copyright 2013 Zack Pierce.
+Use of this source code is governed by a MIT-style
+license that can be found in the LICENSE file.
+*/
+/*
+Package stralgo/vptree implements a vantage-point tree, a
+metric-space index supporting nearest-neighbor and radius
+queries under any float64-valued distance function.
+
+Unlike a BK-tree, which requires integer distances, a
+vantage-point tree works with continuous scores such as
+1 - runewise.JaroWinklerSimilarity or a length-normalized
+Levenshtein distance. Queries are only guaranteed to be
+exact when the distance function is a true metric, and in
+particular satisfies the triangle inequality; with other
+scores the tree still answers queries, but may miss some
+neighbors.
+
+See: http://en.wikipedia.org/wiki/Vantage-point_tree
+*/
+package vptree
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// DistanceFunc measures the distance between two items.
+// It must be non-negative, return 0 for identical items,
+// be symmetric, and satisfy the triangle inequality.
+type DistanceFunc[T any] func(a, b T) float64
+
+// Neighbor is an indexed item returned by a query, along
+// with its distance from the query item.
+//
+// Index is the position of Item within the slice the
+// tree was built from.
+type Neighbor[T any] struct {
+	Item     T
+	Index    int
+	Distance float64
+}
+
+// Tree is a vantage-point tree built with New.
+//
+// A Tree is immutable once built and may be queried from
+// multiple goroutines, provided the distance function is
+// itself safe for concurrent use.
+type Tree[T any] struct {
+	root     *node[T]
+	distance DistanceFunc[T]
+	size     int
+}
+
+type node[T any] struct {
+	item  T
+	index int
+	// threshold is the median distance from item to the
+	// items beneath it. Items in inside are no further than
+	// threshold, and items in outside are no closer.
+	threshold float64
+	inside    *node[T]
+	outside   *node[T]
+}
+
+type entry struct {
+	index    int
+	distance float64
+}
+
+// New builds a Tree over items using the given distance
+// function.
+//
+// Vantage points are chosen at random from a source seeded
+// with seed, so building from the same items, distance
+// function and seed always produces the same tree.
+func New[T any](items []T, distance DistanceFunc[T], seed int64) *Tree[T] {
+	t := &Tree[T]{distance: distance, size: len(items)}
+	entries := make([]entry, len(items), len(items))
+	for i := range entries {
+		entries[i].index = i
+	}
+	t.root = t.build(items, entries, rand.New(rand.NewSource(seed)))
+	return t
+}
+
+func (t *Tree[T]) build(items []T, entries []entry, rng *rand.Rand) *node[T] {
+	if len(entries) == 0 {
+		return nil
+	}
+	v := rng.Intn(len(entries))
+	entries[0], entries[v] = entries[v], entries[0]
+	n := &node[T]{item: items[entries[0].index], index: entries[0].index}
+	rest := entries[1:]
+	if len(rest) == 0 {
+		return n
+	}
+	for i := range rest {
+		rest[i].distance = t.distance(n.item, items[rest[i].index])
+	}
+	sort.Slice(rest, func(i, j int) bool {
+		if rest[i].distance != rest[j].distance {
+			return rest[i].distance < rest[j].distance
+		}
+		return rest[i].index < rest[j].index
+	})
+	mid := len(rest) / 2
+	n.threshold = rest[mid].distance
+	n.inside = t.build(items, rest[:mid], rng)
+	n.outside = t.build(items, rest[mid:], rng)
+	return n
+}
+
+// Len returns the number of items in the tree.
+func (t *Tree[T]) Len() int {
+	return t.size
+}
+
+// Nearest returns the k items closest to query, ordered by
+// ascending distance. Items at equal distances are ordered
+// by ascending Index.
+//
+// Fewer than k neighbors are returned if the tree holds
+// fewer than k items.
+func (t *Tree[T]) Nearest(query T, k int) []Neighbor[T] {
+	if k <= 0 {
+		return []Neighbor[T]{}
+	}
+	h := make(neighborHeap[T], 0, k)
+	t.nearest(t.root, query, k, &h)
+	result := make([]Neighbor[T], len(h), len(h))
+	for i := len(h) - 1; i >= 0; i-- {
+		result[i] = heap.Pop(&h).(Neighbor[T])
+	}
+	return result
+}
+
+func (t *Tree[T]) nearest(n *node[T], query T, k int, h *neighborHeap[T]) {
+	if n == nil {
+		return
+	}
+	d := t.distance(query, n.item)
+	candidate := Neighbor[T]{Item: n.item, Index: n.index, Distance: d}
+	if len(*h) < k {
+		heap.Push(h, candidate)
+	} else if candidate.closerThan((*h)[0]) {
+		(*h)[0] = candidate
+		heap.Fix(h, 0)
+	}
+	// tau is the distance within which a closer item could
+	// still displace the current k-th nearest neighbor.
+	tau := func() float64 {
+		if len(*h) < k {
+			return math.Inf(1)
+		}
+		return (*h)[0].Distance
+	}
+	if d < n.threshold {
+		if d-n.threshold <= tau() {
+			t.nearest(n.inside, query, k, h)
+		}
+		if n.threshold-d <= tau() {
+			t.nearest(n.outside, query, k, h)
+		}
+	} else {
+		if n.threshold-d <= tau() {
+			t.nearest(n.outside, query, k, h)
+		}
+		if d-n.threshold <= tau() {
+			t.nearest(n.inside, query, k, h)
+		}
+	}
+}
+
+// Within returns every item no further than radius from
+// query, ordered by ascending distance. Items at equal
+// distances are ordered by ascending Index.
+func (t *Tree[T]) Within(query T, radius float64) []Neighbor[T] {
+	result := make([]Neighbor[T], 0)
+	t.within(t.root, query, radius, &result)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].closerThan(result[j])
+	})
+	return result
+}
+
+func (t *Tree[T]) within(n *node[T], query T, radius float64, result *[]Neighbor[T]) {
+	if n == nil {
+		return
+	}
+	d := t.distance(query, n.item)
+	if d <= radius {
+		*result = append(*result, Neighbor[T]{Item: n.item, Index: n.index, Distance: d})
+	}
+	if d-n.threshold <= radius {
+		t.within(n.inside, query, radius, result)
+	}
+	if n.threshold-d <= radius {
+		t.within(n.outside, query, radius, result)
+	}
+}
+
+func (a Neighbor[T]) closerThan(b Neighbor[T]) bool {
+	if a.Distance != b.Distance {
+		return a.Distance < b.Distance
+	}
+	return a.Index < b.Index
+}
+
+// neighborHeap is a max-heap keeping the furthest of the
+// current nearest neighbors at the top.
+type neighborHeap[T any] []Neighbor[T]
+
+func (h neighborHeap[T]) Len() int           { return len(h) }
+func (h neighborHeap[T]) Less(i, j int) bool { return h[j].closerThan(h[i]) }
+func (h neighborHeap[T]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *neighborHeap[T]) Push(x interface{}) {
+	*h = append(*h, x.(Neighbor[T]))
+}
+
+func (h *neighborHeap[T]) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
@@ -0,0 +1,113 @@
+﻿package vptree
+
+import (
+	"github.com/ZackPierce/stralgo/runewise"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func absDistance(a, b float64) float64 {
+	return math.Abs(a - b)
+}
+
+func normalizedLevenshtein(a, b []rune) float64 {
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	if longest == 0 {
+		return 0.0
+	}
+	d, _ := runewise.LevenshteinDistance(a, b)
+	return float64(d) / float64(longest)
+}
+
+func Test_Tree_Empty(t *testing.T) {
+	tree := New([]float64{}, absDistance, 1)
+	assert.Equal(t, 0, tree.Len())
+	assert.Equal(t, 0, len(tree.Nearest(1.0, 3)))
+	assert.Equal(t, 0, len(tree.Within(1.0, 10.0)))
+}
+
+func Test_Tree_Nearest(t *testing.T) {
+	items := []float64{10, 3, 7, 1, 9, 4, 12}
+	tree := New(items, absDistance, 42)
+	assert.Equal(t, 7, tree.Len())
+
+	n := tree.Nearest(8.0, 3)
+	assert.Equal(t, []Neighbor[float64]{
+		{Item: 7, Index: 2, Distance: 1},
+		{Item: 9, Index: 4, Distance: 1},
+		{Item: 10, Index: 0, Distance: 2},
+	}, n, "Ties are broken by ascending index.")
+
+	assert.Equal(t, 7, len(tree.Nearest(0.0, 100)))
+	assert.Equal(t, 0, len(tree.Nearest(0.0, 0)))
+}
+
+func Test_Tree_Within(t *testing.T) {
+	items := []float64{10, 3, 7, 1, 9, 4, 12}
+	tree := New(items, absDistance, 42)
+	assert.Equal(t, []Neighbor[float64]{
+		{Item: 3, Index: 1, Distance: 0},
+		{Item: 4, Index: 5, Distance: 1},
+		{Item: 1, Index: 3, Distance: 2},
+	}, tree.Within(3.0, 2.0))
+	assert.Equal(t, 0, len(tree.Within(100.0, 1.0)))
+}
+
+func Test_Tree_AgreesWithLinearScan(t *testing.T) {
+	r := rand.New(rand.NewSource(30))
+	items := make([]float64, 500)
+	for i := range items {
+		items[i] = float64(r.Intn(1000))
+	}
+	for _, seed := range []int64{1, 2, 3} {
+		tree := New(items, absDistance, seed)
+		for q := 0; q < 50; q++ {
+			query := float64(r.Intn(1100) - 50)
+			expected := make([]Neighbor[float64], len(items))
+			for i, item := range items {
+				expected[i] = Neighbor[float64]{Item: item, Index: i, Distance: absDistance(query, item)}
+			}
+			sort.Slice(expected, func(i, j int) bool {
+				return expected[i].closerThan(expected[j])
+			})
+			assert.Equal(t, expected[:7], tree.Nearest(query, 7))
+
+			within := make([]Neighbor[float64], 0)
+			for _, e := range expected {
+				if e.Distance <= 25.0 {
+					within = append(within, e)
+				}
+			}
+			assert.Equal(t, within, tree.Within(query, 25.0))
+		}
+	}
+}
+
+func Test_Tree_Deterministic(t *testing.T) {
+	items := [][]rune{[]rune("martha"), []rune("marhta"), []rune("dwayne"), []rune("duane"), []rune("dixon"), []rune("dicksonx")}
+	a := New(items, normalizedLevenshtein, 7)
+	b := New(items, normalizedLevenshtein, 7)
+	assert.Equal(t, a.root, b.root)
+}
+
+func Test_Tree_Strings(t *testing.T) {
+	items := [][]rune{[]rune("martha"), []rune("marhta"), []rune("dwayne"), []rune("duane"), []rune("dixon"), []rune("dicksonx")}
+	tree := New(items, normalizedLevenshtein, 7)
+	n := tree.Nearest([]rune("marta"), 2)
+	assert.Equal(t, 2, len(n))
+	assert.Equal(t, 0, n[0].Index)
+	assert.Equal(t, 1, n[1].Index)
+
+	jaroWinkler := func(a, b []rune) float64 {
+		return 1.0 - runewise.JaroWinklerSimilarity(a, b)
+	}
+	tree = New(items, jaroWinkler, 7)
+	n = tree.Nearest([]rune("duwayne"), 1)
+	assert.Equal(t, "dwayne", string(n[0].Item))
+}